@@ -0,0 +1,140 @@
+package confluence
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	transform "github.com/resolute-sh/resolute-transform"
+	"github.com/resolute-sh/resolute/core"
+)
+
+// GetContentViews returns the number of times content was viewed. A non-zero
+// from restricts the count to views on or after that date.
+func (c *Client) GetContentViews(ctx context.Context, contentID string, from time.Time) (int, error) {
+	return c.getAnalyticsCount(ctx, contentID, "views", from)
+}
+
+// GetContentViewers returns the number of distinct users who viewed content.
+// A non-zero from restricts the count to views on or after that date.
+func (c *Client) GetContentViewers(ctx context.Context, contentID string, from time.Time) (int, error) {
+	return c.getAnalyticsCount(ctx, contentID, "viewers", from)
+}
+
+func (c *Client) getAnalyticsCount(ctx context.Context, contentID, metric string, from time.Time) (int, error) {
+	query := url.Values{}
+	if !from.IsZero() {
+		query.Set("fromDate", from.UTC().Format("2006-01-02"))
+	}
+	endpoint := c.apiEndpoint(query, "analytics", "content", contentID, metric)
+
+	var result struct {
+		Count int `json:"count"`
+	}
+	if err := c.getJSON(ctx, endpoint, &result); err != nil {
+		return 0, err
+	}
+
+	return result.Count, nil
+}
+
+// FetchAnalyticsInput is the input for FetchAnalyticsActivity.
+type FetchAnalyticsInput struct {
+	BaseURL  string `validate:"required,url"`
+	Email    string `validate:"required"`
+	APIToken string `validate:"required"`
+	Site     string
+
+	// DocumentsRef references the page Documents to annotate.
+	DocumentsRef core.DataRef `validate:"required"`
+
+	// Since restricts counts to views on or after this date. All-time
+	// counts are used when nil.
+	Since *time.Time
+}
+
+// FetchAnalyticsOutput is the output of FetchAnalyticsActivity.
+type FetchAnalyticsOutput struct {
+	Ref   core.DataRef
+	Count int
+}
+
+// FetchAnalyticsActivity adds page view counts to Documents so retrieval can
+// rank heavily used pages higher. Each page Document gets "view_count" and
+// "unique_viewers" metadata. Pages deleted since they were fetched are left
+// unannotated.
+func FetchAnalyticsActivity(ctx context.Context, input FetchAnalyticsInput) (_ FetchAnalyticsOutput, err error) {
+	defer classifyError(&err)
+
+	if err := resolveCredentials(ctx, input.Site, &input.BaseURL, &input.Email, &input.APIToken); err != nil {
+		return FetchAnalyticsOutput{}, err
+	}
+	if err := validateInput(input); err != nil {
+		return FetchAnalyticsOutput{}, err
+	}
+
+	client := NewClient(ClientConfig{
+		BaseURL:  input.BaseURL,
+		Email:    input.Email,
+		APIToken: input.APIToken,
+	})
+
+	docs, err := transform.LoadDocuments(ctx, input.DocumentsRef)
+	if err != nil {
+		return FetchAnalyticsOutput{}, fmt.Errorf("load documents: %w", err)
+	}
+
+	var from time.Time
+	if input.Since != nil {
+		from = *input.Since
+	}
+
+	for i, doc := range docs {
+		pageID := documentPageID(doc)
+		if pageID == "" {
+			continue
+		}
+
+		views, err := client.GetContentViews(ctx, pageID, from)
+		if hasStatus(err, http.StatusNotFound) {
+			continue
+		}
+		if err != nil {
+			return FetchAnalyticsOutput{}, fmt.Errorf("get views of %s: %w", pageID, err)
+		}
+		viewers, err := client.GetContentViewers(ctx, pageID, from)
+		if hasStatus(err, http.StatusNotFound) {
+			continue
+		}
+		if err != nil {
+			return FetchAnalyticsOutput{}, fmt.Errorf("get viewers of %s: %w", pageID, err)
+		}
+
+		if doc.Metadata == nil {
+			doc.Metadata = make(map[string]string)
+		}
+		doc.Metadata["view_count"] = strconv.Itoa(views)
+		doc.Metadata["unique_viewers"] = strconv.Itoa(viewers)
+		docs[i] = doc
+
+		recordHeartbeat(ctx, i+1)
+	}
+
+	ref, err := transform.StoreDocuments(ctx, docs)
+	if err != nil {
+		return FetchAnalyticsOutput{}, fmt.Errorf("store documents: %w", err)
+	}
+
+	return FetchAnalyticsOutput{
+		Ref:   ref,
+		Count: len(docs),
+	}, nil
+}
+
+// FetchAnalytics creates a node for annotating Documents with view counts.
+func FetchAnalytics(input FetchAnalyticsInput) *core.Node[FetchAnalyticsInput, FetchAnalyticsOutput] {
+	return withPolicy(core.NewNode("confluence.FetchAnalytics", FetchAnalyticsActivity, input))
+}
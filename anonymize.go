@@ -0,0 +1,106 @@
+package confluence
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+)
+
+// Anonymization modes.
+const (
+	// AnonymizeHash replaces account IDs with keyed hashes and user names
+	// with pseudonyms derived from them, so the Documents of one user can
+	// still be grouped.
+	AnonymizeHash = "hash"
+	// AnonymizeDrop leaves account IDs and user names out.
+	AnonymizeDrop = "drop"
+)
+
+// Anonymization configures how the account IDs and user names of Atlassian
+// users appear in the metadata and content of Documents, for corpora that
+// must satisfy data-minimization requirements. Structured outputs, such as
+// stored AuditRecords and FetchContributors results, are not affected.
+type Anonymization struct {
+	// Mode is AnonymizeHash or AnonymizeDrop. Empty keeps users as they are;
+	// any other value drops them.
+	Mode string
+	// Key keys the hashes of AnonymizeHash, so they cannot be reversed by
+	// hashing known account IDs. Keep it secret and stable: changing it
+	// changes every pseudonym.
+	Key string
+}
+
+var (
+	anonymizationMu sync.RWMutex
+	anonymization   Anonymization
+)
+
+// SetAnonymization sets how every activity anonymizes users in the
+// Documents it builds. The zero Anonymization turns anonymization off.
+// Provider's WithAnonymization option is equivalent.
+func SetAnonymization(a Anonymization) {
+	anonymizationMu.Lock()
+	defer anonymizationMu.Unlock()
+	anonymization = a
+}
+
+// currentAnonymization returns the Anonymization set with SetAnonymization.
+func currentAnonymization() Anonymization {
+	anonymizationMu.RLock()
+	defer anonymizationMu.RUnlock()
+	return anonymization
+}
+
+// anonymizeUser returns user as configured with SetAnonymization. Dropped
+// users are returned as the zero User.
+func anonymizeUser(user User) User {
+	a := currentAnonymization()
+	switch a.Mode {
+	case "":
+		return user
+	case AnonymizeHash:
+		if user.AccountID == "" && user.DisplayName == "" {
+			return User{}
+		}
+		token := a.hash("name:" + user.DisplayName)
+		if user.AccountID != "" {
+			token = a.hash("account:" + user.AccountID)
+		}
+		anonymized := User{DisplayName: "User " + token[:8]}
+		if user.AccountID != "" {
+			anonymized.AccountID = token
+		}
+		return anonymized
+	default:
+		return User{}
+	}
+}
+
+// anonymizeAccountID returns an account ID as configured with
+// SetAnonymization, or an empty string when it is dropped.
+func anonymizeAccountID(accountID string) string {
+	if accountID == "" {
+		return ""
+	}
+	return anonymizeUser(User{AccountID: accountID}).AccountID
+}
+
+// anonymizeAccountIDs returns account IDs as configured with
+// SetAnonymization, leaving out the dropped ones.
+func anonymizeAccountIDs(accountIDs []string) []string {
+	anonymized := make([]string, 0, len(accountIDs))
+	for _, accountID := range accountIDs {
+		if id := anonymizeAccountID(accountID); id != "" {
+			anonymized = append(anonymized, id)
+		}
+	}
+	return anonymized
+}
+
+// hash returns the hex-encoded keyed hash of value.
+func (a Anonymization) hash(value string) string {
+	mac := hmac.New(sha256.New, []byte(a.Key))
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
@@ -0,0 +1,65 @@
+package confluence_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/resolute-sh/resolute-confluence"
+	"github.com/resolute-sh/resolute-confluence/confluencetest"
+	transform "github.com/resolute-sh/resolute-transform"
+)
+
+// mentionAccountID is the account ID mentioned by the pages of the
+// anonymization tests.
+const mentionAccountID = "557058:mentioned"
+
+// fetchMentionPage fetches a page mentioning mentionAccountID with
+// anonymization a and returns its Document.
+func fetchMentionPage(t *testing.T, a confluence.Anonymization) transform.Document {
+	t.Helper()
+	srv := newServer(t)
+	srv.AddPage(confluencetest.NewPage("ENG", "Handover",
+		`<p>Ask <ac:link><ri:user ri:account-id="`+mentionAccountID+`" /></ac:link> for access.</p>`))
+
+	confluence.SetAnonymization(a)
+	t.Cleanup(func() { confluence.SetAnonymization(confluence.Anonymization{}) })
+
+	out, err := confluence.FetchPagesActivity(context.Background(), confluence.FetchPagesInput{
+		BaseURL:  srv.URL,
+		Email:    srv.Email,
+		APIToken: srv.APIToken,
+		SpaceKey: "ENG",
+	})
+	if err != nil {
+		t.Fatalf("FetchPagesActivity() error = %v", err)
+	}
+	docs, err := transform.LoadDocuments(context.Background(), out.Ref)
+	if err != nil {
+		t.Fatalf("LoadDocuments() error = %v", err)
+	}
+	if len(docs) != 1 {
+		t.Fatalf("got %d Documents, want 1", len(docs))
+	}
+	return docs[0]
+}
+
+func TestAnonymizationMentions(t *testing.T) {
+	plain := fetchMentionPage(t, confluence.Anonymization{})
+	if !strings.Contains(plain.Content, "@"+mentionAccountID) {
+		t.Errorf("content = %q, want the mention kept", plain.Content)
+	}
+
+	hashed := fetchMentionPage(t, confluence.Anonymization{Mode: confluence.AnonymizeHash, Key: "secret"})
+	if strings.Contains(hashed.Content, mentionAccountID) {
+		t.Errorf("hashed content = %q, want no account ID", hashed.Content)
+	}
+	if !strings.Contains(hashed.Content, "Ask @") {
+		t.Errorf("hashed content = %q, want a hashed mention", hashed.Content)
+	}
+
+	dropped := fetchMentionPage(t, confluence.Anonymization{Mode: confluence.AnonymizeDrop})
+	if want := "Ask for access."; dropped.Content != want {
+		t.Errorf("dropped content = %q, want %q", dropped.Content, want)
+	}
+}
@@ -0,0 +1,164 @@
+package confluence
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/resolute-sh/resolute-confluence/cql"
+	"github.com/resolute-sh/resolute/core"
+	"go.temporal.io/sdk/temporal"
+)
+
+// maxArchiveBatch is the number of pages Confluence archives per request.
+const maxArchiveBatch = 300
+
+// ArchivePages starts archiving pages and returns the ID of the long task
+// tracking the operation.
+func (c *Client) ArchivePages(ctx context.Context, pageIDs []string) (string, error) {
+	body := struct {
+		Pages []ancestorRef `json:"pages"`
+	}{Pages: make([]ancestorRef, 0, len(pageIDs))}
+	for _, id := range pageIDs {
+		body.Pages = append(body.Pages, ancestorRef{ID: id})
+	}
+
+	endpoint := c.apiEndpoint(nil, "content", "archive")
+
+	var task LongTask
+	if err := c.doJSON(ctx, http.MethodPost, endpoint, body, &task); err != nil {
+		return "", err
+	}
+
+	return task.ID, nil
+}
+
+// ErrTypeArchiveFailed is the application error type of archive operations
+// that Confluence reports as unsuccessful.
+const ErrTypeArchiveFailed = "confluence.ArchiveFailed"
+
+// StalePage is a page selected for archiving.
+type StalePage struct {
+	ID           string
+	Title        string
+	URL          string
+	LastModified time.Time
+}
+
+// ArchiveStaleContentInput is the input for ArchiveStaleContentActivity.
+type ArchiveStaleContentInput struct {
+	BaseURL  string `validate:"required,url"`
+	Email    string `validate:"required"`
+	APIToken string `validate:"required"`
+	Site     string
+	SpaceKey string `validate:"required"`
+
+	// MaxAgeDays selects pages not modified in this many days.
+	MaxAgeDays int `validate:"min=1"`
+
+	// DryRun reports the stale pages without archiving them.
+	DryRun bool
+
+	// Limit is the number of pages requested per API call, up to the
+	// API maximum of 250. Defaults to 250.
+	Limit int `validate:"min=0"`
+
+	// PollInterval is the delay between archive progress checks. Defaults
+	// to 2s.
+	PollInterval time.Duration
+}
+
+// ArchiveStaleContentOutput is the output of ArchiveStaleContentActivity.
+type ArchiveStaleContentOutput struct {
+	// Pages lists the stale pages, archived unless DryRun was set.
+	Pages  []StalePage
+	Count  int
+	DryRun bool
+}
+
+// ArchiveStaleContentActivity archives the pages of a space that were not
+// modified in MaxAgeDays days. Archived pages no longer match the stale page
+// query, so a retried attempt only archives what remains.
+func ArchiveStaleContentActivity(ctx context.Context, input ArchiveStaleContentInput) (_ ArchiveStaleContentOutput, err error) {
+	defer classifyError(&err)
+
+	if err := resolveCredentials(ctx, input.Site, &input.BaseURL, &input.Email, &input.APIToken); err != nil {
+		return ArchiveStaleContentOutput{}, err
+	}
+	if err := validateInput(input); err != nil {
+		return ArchiveStaleContentOutput{}, err
+	}
+
+	client := NewClient(ClientConfig{
+		BaseURL:  input.BaseURL,
+		Email:    input.Email,
+		APIToken: input.APIToken,
+	})
+
+	limit := pageSize(ctx, input.Limit)
+	interval := input.PollInterval
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+
+	cutoff := time.Now().UTC().AddDate(0, 0, -input.MaxAgeDays)
+	query := cql.Space(input.SpaceKey).
+		And(cql.Type(cql.TypePage), cql.LastModifiedBefore(cutoff)).
+		OrderBy(cql.FieldLastModified, cql.Ascending).
+		String()
+
+	pages, err := listAllPages(ctx, limit, func(start, limit int) (*PageList, error) {
+		return client.SearchContent(ctx, query, []string{"version"}, start, limit)
+	})
+	if err != nil {
+		return ArchiveStaleContentOutput{}, fmt.Errorf("search stale pages: %w", err)
+	}
+
+	output := ArchiveStaleContentOutput{DryRun: input.DryRun}
+	ids := make([]string, 0, len(pages))
+	for _, page := range pages {
+		modified := page.Version.ModifiedAt()
+		if !modified.IsZero() && !modified.Before(cutoff) {
+			continue
+		}
+		output.Pages = append(output.Pages, StalePage{
+			ID:           page.ID,
+			Title:        page.Title,
+			URL:          webURL(input.BaseURL, page.Links.WebUI),
+			LastModified: modified,
+		})
+		ids = append(ids, page.ID)
+	}
+	output.Count = len(output.Pages)
+
+	if input.DryRun {
+		return output, nil
+	}
+
+	for start := 0; start < len(ids); start += maxArchiveBatch {
+		batch := ids[start:min(start+maxArchiveBatch, len(ids))]
+
+		taskID, err := client.ArchivePages(ctx, batch)
+		if err != nil {
+			return ArchiveStaleContentOutput{}, fmt.Errorf("archive pages: %w", err)
+		}
+
+		task, err := waitLongTask(ctx, client, taskID, interval)
+		if err != nil {
+			return ArchiveStaleContentOutput{}, fmt.Errorf("wait for archive task %s: %w", taskID, err)
+		}
+		if !task.Successful {
+			return ArchiveStaleContentOutput{}, temporal.NewNonRetryableApplicationError(
+				fmt.Sprintf("archive of %d pages in space %s failed: %s", len(batch), input.SpaceKey, task.message()),
+				ErrTypeArchiveFailed, nil)
+		}
+	}
+
+	return output, nil
+}
+
+// ArchiveStaleContent creates a node for archiving stale Confluence pages.
+func ArchiveStaleContent(input ArchiveStaleContentInput) *core.Node[ArchiveStaleContentInput, ArchiveStaleContentOutput] {
+	return withPolicy(core.NewNode("confluence.ArchiveStaleContent", ArchiveStaleContentActivity, input))
+}
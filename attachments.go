@@ -0,0 +1,295 @@
+package confluence
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/resolute-sh/resolute/core"
+)
+
+// SchemaFile is the DataRef schema of stored files.
+const SchemaFile = "confluence.File"
+
+// File is a named file stored for upload as an attachment.
+type File struct {
+	Name      string
+	MediaType string
+	Data      []byte
+}
+
+// Attachment represents a file attached to a page.
+type Attachment struct {
+	ID         string               `json:"id"`
+	Title      string               `json:"title"`
+	Version    Version              `json:"version"`
+	Metadata   AttachmentMetadata   `json:"metadata"`
+	Extensions AttachmentExtensions `json:"extensions"`
+	Links      AttachmentLinks      `json:"_links"`
+}
+
+// AttachmentMetadata contains attachment metadata.
+type AttachmentMetadata struct {
+	MediaType string `json:"mediaType"`
+	Comment   string `json:"comment"`
+}
+
+// AttachmentExtensions contains attachment file details.
+type AttachmentExtensions struct {
+	MediaType string `json:"mediaType"`
+	FileSize  int64  `json:"fileSize"`
+}
+
+// AttachmentLinks contains attachment links.
+type AttachmentLinks struct {
+	WebUI    string `json:"webui"`
+	Download string `json:"download"`
+}
+
+// AttachmentList represents a single page of attachment results.
+type AttachmentList struct {
+	Results []Attachment `json:"results"`
+	Start   int          `json:"start"`
+	Limit   int          `json:"limit"`
+	Size    int          `json:"size"`
+	Links   ListLinks    `json:"_links"`
+}
+
+// HasMore reports whether another page of results is available.
+func (l *AttachmentList) HasMore() bool {
+	return l.Links.Next != ""
+}
+
+// ListAttachments fetches one page of the attachments of a page starting at offset start.
+func (c *Client) ListAttachments(ctx context.Context, pageID string, start, limit int) (*AttachmentList, error) {
+	if limit <= 0 {
+		limit = maxPageSize
+	}
+
+	endpoint := c.apiEndpoint(url.Values{
+		"start":  {strconv.Itoa(start)},
+		"limit":  {strconv.Itoa(limit)},
+		"expand": {"version"},
+	}, "content", pageID, "child", "attachment")
+
+	var list AttachmentList
+	if err := c.getJSON(ctx, endpoint, &list); err != nil {
+		return nil, err
+	}
+
+	return &list, nil
+}
+
+// listAllAttachments collects every attachment of a page.
+func listAllAttachments(ctx context.Context, client *Client, pageID string, limit int) ([]Attachment, error) {
+	var attachments []Attachment
+	start := 0
+
+	for {
+		list, err := client.ListAttachments(ctx, pageID, start, limit)
+		if err != nil {
+			return nil, fmt.Errorf("list attachments at %d: %w", start, err)
+		}
+
+		attachments = append(attachments, list.Results...)
+
+		start += len(list.Results)
+		if !list.HasMore() || len(list.Results) == 0 {
+			break
+		}
+	}
+
+	return attachments, nil
+}
+
+// UploadAttachmentRequest describes an attachment to upload.
+type UploadAttachmentRequest struct {
+	PageID    string
+	FileName  string
+	MediaType string
+	Data      []byte
+	// Comment is stored with the attachment version.
+	Comment string
+	// MinorEdit suppresses watcher notifications for the upload.
+	MinorEdit bool
+}
+
+// UploadAttachment attaches a file to a page. An attachment with the same
+// file name gets a new version instead of a duplicate.
+func (c *Client) UploadAttachment(ctx context.Context, req UploadAttachmentRequest) (*Attachment, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`,
+		strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(req.FileName)))
+	mediaType := req.MediaType
+	if mediaType == "" {
+		mediaType = "application/octet-stream"
+	}
+	header.Set("Content-Type", mediaType)
+
+	part, err := form.CreatePart(header)
+	if err != nil {
+		return nil, fmt.Errorf("create form file: %w", err)
+	}
+	if _, err := part.Write(req.Data); err != nil {
+		return nil, fmt.Errorf("write form file: %w", err)
+	}
+	if req.Comment != "" {
+		if err := form.WriteField("comment", req.Comment); err != nil {
+			return nil, fmt.Errorf("write form comment: %w", err)
+		}
+	}
+	if err := form.WriteField("minorEdit", fmt.Sprintf("%t", req.MinorEdit)); err != nil {
+		return nil, fmt.Errorf("write form minorEdit: %w", err)
+	}
+	if err := form.Close(); err != nil {
+		return nil, fmt.Errorf("close form: %w", err)
+	}
+
+	endpoint := c.apiEndpoint(url.Values{"expand": {"version"}}, "content", req.PageID, "child", "attachment")
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, &body)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	c.setAuth(httpReq)
+	httpReq.Header.Set("Content-Type", form.FormDataContentType())
+	httpReq.Header.Set("X-Atlassian-Token", "no-check")
+
+	var list AttachmentList
+	if err := c.do(httpReq, &list); err != nil {
+		return nil, err
+	}
+	if len(list.Results) == 0 {
+		return nil, errors.New("upload returned no attachment")
+	}
+
+	return &list.Results[0], nil
+}
+
+// StoreFile stores a file for upload and returns a DataRef.
+func StoreFile(ctx context.Context, file File) (core.DataRef, error) {
+	storage, err := core.GetStorage()
+	if err != nil {
+		return core.DataRef{}, fmt.Errorf("get storage: %w", err)
+	}
+
+	ref, err := storage.StoreJSON(ctx, SchemaFile, file)
+	if err != nil {
+		return core.DataRef{}, err
+	}
+
+	ref.Count = 1
+	return ref, nil
+}
+
+// LoadFile loads a File from a DataRef.
+func LoadFile(ctx context.Context, ref core.DataRef) (*File, error) {
+	if ref.Schema != SchemaFile {
+		return nil, fmt.Errorf("schema mismatch: expected %s, got %s", SchemaFile, ref.Schema)
+	}
+
+	storage, err := core.GetStorage()
+	if err != nil {
+		return nil, fmt.Errorf("get storage: %w", err)
+	}
+
+	var file File
+	if err := storage.LoadJSON(ctx, ref, &file); err != nil {
+		return nil, fmt.Errorf("load file: %w", err)
+	}
+
+	return &file, nil
+}
+
+// AttachFileInput is the input for AttachFileActivity.
+type AttachFileInput struct {
+	BaseURL  string `validate:"required,url"`
+	Email    string `validate:"required"`
+	APIToken string `validate:"required"`
+	Site     string
+	PageID   string `validate:"required"`
+
+	// FileRef references a File stored with StoreFile.
+	FileRef core.DataRef `validate:"required"`
+
+	// FileName overrides the name of the stored File.
+	FileName string
+
+	// Comment is stored with the attachment version.
+	Comment string
+
+	// MinorEdit suppresses watcher notifications for the upload.
+	MinorEdit bool
+}
+
+// AttachFileOutput is the output of AttachFileActivity.
+type AttachFileOutput struct {
+	AttachmentID string
+	Version      int
+	DownloadURL  string
+}
+
+// AttachFileActivity uploads a stored file as a page attachment. Uploading a
+// file name already attached to the page adds a new attachment version.
+func AttachFileActivity(ctx context.Context, input AttachFileInput) (_ AttachFileOutput, err error) {
+	defer classifyError(&err)
+
+	if err := resolveCredentials(ctx, input.Site, &input.BaseURL, &input.Email, &input.APIToken); err != nil {
+		return AttachFileOutput{}, err
+	}
+	if err := validateInput(input); err != nil {
+		return AttachFileOutput{}, err
+	}
+
+	file, err := LoadFile(ctx, input.FileRef)
+	if err != nil {
+		return AttachFileOutput{}, err
+	}
+
+	name := input.FileName
+	if name == "" {
+		name = file.Name
+	}
+	if name == "" {
+		return AttachFileOutput{}, invalidInputError(errors.New("file name is required"))
+	}
+
+	client := NewClient(ClientConfig{
+		BaseURL:  input.BaseURL,
+		Email:    input.Email,
+		APIToken: input.APIToken,
+	})
+
+	attachment, err := client.UploadAttachment(ctx, UploadAttachmentRequest{
+		PageID:    input.PageID,
+		FileName:  name,
+		MediaType: file.MediaType,
+		Data:      file.Data,
+		Comment:   input.Comment,
+		MinorEdit: input.MinorEdit,
+	})
+	if err != nil {
+		return AttachFileOutput{}, fmt.Errorf("upload attachment to %s: %w", input.PageID, err)
+	}
+
+	return AttachFileOutput{
+		AttachmentID: attachment.ID,
+		Version:      attachment.Version.Number,
+		DownloadURL:  webURL(input.BaseURL, attachment.Links.Download),
+	}, nil
+}
+
+// AttachFile creates a node for attaching a file to a Confluence page.
+func AttachFile(input AttachFileInput) *core.Node[AttachFileInput, AttachFileOutput] {
+	return withPolicy(core.NewNode("confluence.AttachFile", AttachFileActivity, input))
+}
@@ -0,0 +1,119 @@
+package confluence
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/interceptor"
+)
+
+// Metric names recorded by the audit interceptor. Each is tagged with the
+// activity name.
+const (
+	MetricActivityDuration = "confluence_activity_duration"
+	MetricAPICalls         = "confluence_api_calls"
+	MetricActivityErrors   = "confluence_activity_errors"
+)
+
+// NewAuditInterceptor returns a worker interceptor that logs every
+// Confluence activity execution with its space, result count, duration, and
+// number of Confluence API calls, and records them as metrics. Other
+// activities pass through untouched.
+//
+//	w := worker.New(c, queue, worker.Options{
+//		Interceptors: []interceptor.WorkerInterceptor{confluence.NewAuditInterceptor()},
+//	})
+func NewAuditInterceptor() interceptor.WorkerInterceptor {
+	return &auditInterceptor{}
+}
+
+type auditInterceptor struct {
+	interceptor.WorkerInterceptorBase
+}
+
+func (a *auditInterceptor) InterceptActivity(ctx context.Context, next interceptor.ActivityInboundInterceptor) interceptor.ActivityInboundInterceptor {
+	i := &auditActivityInterceptor{}
+	i.Next = next
+	return i
+}
+
+type auditActivityInterceptor struct {
+	interceptor.ActivityInboundInterceptorBase
+}
+
+func (a *auditActivityInterceptor) ExecuteActivity(ctx context.Context, in *interceptor.ExecuteActivityInput) (any, error) {
+	name := activityName(ctx)
+	if !strings.HasPrefix(name, "confluence.") {
+		return a.Next.ExecuteActivity(ctx, in)
+	}
+
+	calls := new(atomic.Int64)
+	ctx = context.WithValue(ctx, apiCallsKey{}, calls)
+
+	start := time.Now()
+	result, err := a.Next.ExecuteActivity(ctx, in)
+	duration := time.Since(start)
+
+	keyvals := []any{
+		"Duration", duration,
+		"APICalls", calls.Load(),
+	}
+	if len(in.Args) > 0 {
+		if space := auditField(in.Args[0], "SpaceKey"); space != nil {
+			keyvals = append(keyvals, "SpaceKey", space)
+		}
+	}
+	if count := auditField(result, "Count"); count != nil {
+		keyvals = append(keyvals, "Count", count)
+	}
+
+	metrics := activity.GetMetricsHandler(ctx).WithTags(map[string]string{"activity": name})
+	metrics.Timer(MetricActivityDuration).Record(duration)
+	metrics.Counter(MetricAPICalls).Inc(calls.Load())
+
+	logger := activity.GetLogger(ctx)
+	if err != nil {
+		metrics.Counter(MetricActivityErrors).Inc(1)
+		logger.Warn("Confluence activity failed", append(keyvals, "Error", err)...)
+	} else {
+		logger.Info("Confluence activity completed", keyvals...)
+	}
+
+	return result, err
+}
+
+// apiCallsKey is the context key of the API call counter of an audited
+// activity execution.
+type apiCallsKey struct{}
+
+// countAPICall increments the API call counter carried by ctx, if any.
+func countAPICall(ctx context.Context) {
+	if calls, ok := ctx.Value(apiCallsKey{}).(*atomic.Int64); ok {
+		calls.Add(1)
+	}
+}
+
+// auditField returns the value of a named exported field of a struct, or nil
+// when v has no such field.
+func auditField(v any, name string) any {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+
+	field := rv.FieldByName(name)
+	if !field.IsValid() || field.IsZero() {
+		return nil
+	}
+	return field.Interface()
+}
@@ -0,0 +1,288 @@
+package confluence
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/resolute-sh/resolute/core"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+)
+
+// Space export formats of ExportSpaceArchiveInput.Format.
+const (
+	SpaceExportXML  = "xml"
+	SpaceExportHTML = "html"
+)
+
+// ErrTypeSpaceExportFailed is the application error type of space exports
+// that Confluence reports as unsuccessful.
+const ErrTypeSpaceExportFailed = "confluence.SpaceExportFailed"
+
+// SpaceExportRequest describes a space export.
+type SpaceExportRequest struct {
+	SpaceKey string
+	// Format is SpaceExportXML or SpaceExportHTML.
+	Format string
+	// IncludeComments adds page comments to the export.
+	IncludeComments bool
+}
+
+type spaceExportBody struct {
+	ExportType      string `json:"exportType"`
+	ExportScope     string `json:"exportScope"`
+	IncludeComments bool   `json:"includeComments"`
+}
+
+// StartSpaceExport starts exporting a whole space to an archive and returns
+// the ID of the long task tracking the export.
+func (c *Client) StartSpaceExport(ctx context.Context, req SpaceExportRequest) (string, error) {
+	body := spaceExportBody{
+		ExportType:      strings.ToUpper(req.Format),
+		ExportScope:     "all",
+		IncludeComments: req.IncludeComments,
+	}
+
+	endpoint := c.apiEndpoint(nil, "space", req.SpaceKey, "export")
+
+	var task LongTask
+	if err := c.doJSON(ctx, http.MethodPost, endpoint, body, &task); err != nil {
+		return "", err
+	}
+
+	return task.ID, nil
+}
+
+// Download streams the file at a site path, such as the download link of a
+// finished export, into w and returns the number of bytes written. The
+// download is not limited by the request timeout, which large exports
+// outlast, but only by ctx; activities keep it alive with heartbeats as
+// bytes arrive, so a stalled download times out with the heartbeat.
+func (c *Client) Download(ctx context.Context, sitePath string, w io.Writer) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, webURL(c.baseURL, sitePath), nil)
+	if err != nil {
+		return 0, fmt.Errorf("create request: %w", err)
+	}
+	c.setAuth(req)
+	req.Header.Set("Accept", "*/*")
+
+	resp, cancel, err := c.send(req, false)
+	if err != nil {
+		return 0, err
+	}
+	defer cancel()
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(resp.Body)
+		return 0, &APIError{Status: resp.StatusCode, Body: string(body)}
+	}
+
+	n, err := io.Copy(w, resp.Body)
+	if err != nil {
+		return n, fmt.Errorf("read download: %w", err)
+	}
+	return n, nil
+}
+
+// exportLinkPattern matches the download link in the messages of a finished
+// export task.
+var exportLinkPattern = regexp.MustCompile(`(?:/wiki)?/download/temp/[^\s"'<>]+`)
+
+// exportDownloadPath returns the download link of a finished export task.
+func exportDownloadPath(task *LongTask) string {
+	if task.AdditionalDetails.DownloadURL != "" {
+		return task.AdditionalDetails.DownloadURL
+	}
+	for _, m := range task.Messages {
+		if link := exportLinkPattern.FindString(m.Translation); link != "" {
+			return link
+		}
+	}
+	return ""
+}
+
+// ExportSpaceArchiveInput is the input for ExportSpaceArchiveActivity.
+type ExportSpaceArchiveInput struct {
+	BaseURL  string `validate:"required,url"`
+	Email    string `validate:"required"`
+	APIToken string `validate:"required"`
+	Site     string
+	SpaceKey string `validate:"required"`
+
+	// Format is SpaceExportXML or SpaceExportHTML. Defaults to
+	// SpaceExportXML, the format space imports and migrations read.
+	Format string `validate:"oneof=|xml|html"`
+	// IncludeComments adds page comments to the export.
+	IncludeComments bool
+
+	// PollInterval is the delay between progress checks. Defaults to 10s.
+	PollInterval time.Duration
+}
+
+// ExportSpaceArchiveOutput is the output of ExportSpaceArchiveActivity.
+type ExportSpaceArchiveOutput struct {
+	// Ref references the archive, stored as a File.
+	Ref    core.DataRef
+	TaskID string
+	// FileName is the name of the archive.
+	FileName string
+	// Size is the size of the archive in bytes.
+	Size int64
+}
+
+// ExportSpaceArchiveActivity exports a whole space to a zip archive with
+// Confluence's own space export, waits for the export to finish while
+// heartbeating its progress, and stores the archive as a File for backup
+// and migration workflows. The archive is streamed to a temporary file
+// rather than held in memory while it is downloaded. A retried attempt
+// resumes waiting on the export started by the previous attempt.
+func ExportSpaceArchiveActivity(ctx context.Context, input ExportSpaceArchiveInput) (_ ExportSpaceArchiveOutput, err error) {
+	defer classifyError(&err)
+
+	if err := resolveCredentials(ctx, input.Site, &input.BaseURL, &input.Email, &input.APIToken); err != nil {
+		return ExportSpaceArchiveOutput{}, err
+	}
+	if err := validateInput(input); err != nil {
+		return ExportSpaceArchiveOutput{}, err
+	}
+
+	client := NewClient(ClientConfig{
+		BaseURL:  input.BaseURL,
+		Email:    input.Email,
+		APIToken: input.APIToken,
+	})
+
+	format := input.Format
+	if format == "" {
+		format = SpaceExportXML
+	}
+	interval := input.PollInterval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+
+	var taskID string
+	if activity.IsActivity(ctx) && activity.HasHeartbeatDetails(ctx) {
+		if err := activity.GetHeartbeatDetails(ctx, &taskID); err != nil {
+			return ExportSpaceArchiveOutput{}, fmt.Errorf("get heartbeat details: %w", err)
+		}
+	}
+	if taskID == "" {
+		taskID, err = client.StartSpaceExport(ctx, SpaceExportRequest{
+			SpaceKey:        input.SpaceKey,
+			Format:          format,
+			IncludeComments: input.IncludeComments,
+		})
+		if err != nil {
+			return ExportSpaceArchiveOutput{}, fmt.Errorf("start export of space %s: %w", input.SpaceKey, err)
+		}
+		recordHeartbeat(ctx, taskID)
+	}
+
+	task, err := waitLongTask(ctx, client, taskID, interval)
+	if err != nil {
+		return ExportSpaceArchiveOutput{}, fmt.Errorf("wait for export task %s: %w", taskID, err)
+	}
+	link := exportDownloadPath(task)
+	if !task.Successful || link == "" {
+		return ExportSpaceArchiveOutput{}, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("export of space %s failed: %s", input.SpaceKey, task.message()),
+			ErrTypeSpaceExportFailed, nil)
+	}
+
+	name := path.Base(strings.SplitN(link, "?", 2)[0])
+	ref, size, err := storeDownload(ctx, client, link, name, "application/zip", taskID)
+	if err != nil {
+		return ExportSpaceArchiveOutput{}, fmt.Errorf("download export of space %s: %w", input.SpaceKey, err)
+	}
+
+	return ExportSpaceArchiveOutput{
+		Ref:      ref,
+		TaskID:   taskID,
+		FileName: name,
+		Size:     size,
+	}, nil
+}
+
+// storeDownload downloads the file at a site path and stores it as a File,
+// heartbeating taskID and the bytes downloaded so far. The File is encoded
+// to a temporary file as the download streams in, so only its encoded form
+// is read back into memory to be stored.
+func storeDownload(ctx context.Context, client *Client, sitePath, name, mediaType, taskID string) (core.DataRef, int64, error) {
+	file, err := os.CreateTemp("", "confluence-download-*.json")
+	if err != nil {
+		return core.DataRef{}, 0, fmt.Errorf("create spool: %w", err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	header, err := json.Marshal(File{Name: name, MediaType: mediaType})
+	if err != nil {
+		return core.DataRef{}, 0, fmt.Errorf("marshal file: %w", err)
+	}
+
+	// The header ends in "Data":null}; the data is spliced in its place.
+	w := bufio.NewWriter(file)
+	w.Write(header[:len(header)-len("null}")])
+	w.WriteByte('"')
+	enc := base64.NewEncoder(base64.StdEncoding, w)
+	size, err := client.Download(ctx, sitePath, &progressWriter{ctx: ctx, w: enc, taskID: taskID})
+	if err != nil {
+		return core.DataRef{}, 0, err
+	}
+	enc.Close()
+	w.WriteString(`"}`)
+	if err := w.Flush(); err != nil {
+		return core.DataRef{}, 0, fmt.Errorf("write spool: %w", err)
+	}
+
+	data, err := os.ReadFile(file.Name())
+	if err != nil {
+		return core.DataRef{}, 0, fmt.Errorf("read spool: %w", err)
+	}
+
+	storage, err := core.GetStorage()
+	if err != nil {
+		return core.DataRef{}, 0, fmt.Errorf("get storage: %w", err)
+	}
+	ref, err := storage.StoreJSON(ctx, SchemaFile, json.RawMessage(data))
+	if err != nil {
+		return core.DataRef{}, 0, err
+	}
+
+	ref.Count = 1
+	return ref, size, nil
+}
+
+// progressWriter heartbeats the number of bytes written through it, so long
+// downloads do not miss their heartbeat deadline.
+type progressWriter struct {
+	ctx    context.Context
+	w      io.Writer
+	taskID string
+	n      int64
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.n += int64(n)
+	recordHeartbeat(p.ctx, p.taskID, 100, p.n)
+	return n, err
+}
+
+// ExportSpaceArchive creates a node for exporting a Confluence space to an
+// archive.
+func ExportSpaceArchive(input ExportSpaceArchiveInput) *core.Node[ExportSpaceArchiveInput, ExportSpaceArchiveOutput] {
+	return withPolicy(core.NewNode("confluence.ExportSpaceArchive", ExportSpaceArchiveActivity, input))
+}
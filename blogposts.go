@@ -0,0 +1,90 @@
+package confluence
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	transform "github.com/resolute-sh/resolute-transform"
+	"github.com/resolute-sh/resolute/core"
+)
+
+// FetchBlogPostsInput is the input for FetchBlogPostsActivity.
+type FetchBlogPostsInput struct {
+	BaseURL  string `validate:"required,url"`
+	Email    string `validate:"required"`
+	APIToken string `validate:"required"`
+	Site     string
+	SpaceKey string `validate:"required"`
+
+	// Limit is the number of posts requested per API call, up to the
+	// API maximum of 250. Defaults to 250.
+	Limit int `validate:"min=0"`
+
+	// Source is the Source of the stored Documents, which tells apart the
+	// Documents of several sites. Defaults to the hostname of BaseURL.
+	Source string
+}
+
+// FetchBlogPostsOutput is the output of FetchBlogPostsActivity.
+type FetchBlogPostsOutput struct {
+	Ref   core.DataRef
+	Count int
+}
+
+// FetchBlogPostsActivity fetches the blog posts of a space and stores them.
+// Documents carry "author", "author_account_id", and "published_at" metadata.
+func FetchBlogPostsActivity(ctx context.Context, input FetchBlogPostsInput) (_ FetchBlogPostsOutput, err error) {
+	defer classifyError(&err)
+
+	if err := resolveCredentials(ctx, input.Site, &input.BaseURL, &input.Email, &input.APIToken); err != nil {
+		return FetchBlogPostsOutput{}, err
+	}
+	if err := validateInput(input); err != nil {
+		return FetchBlogPostsOutput{}, err
+	}
+
+	client := NewClient(ClientConfig{
+		BaseURL:  input.BaseURL,
+		Email:    input.Email,
+		APIToken: input.APIToken,
+	})
+
+	source := documentSource(input.BaseURL, input.Source)
+
+	limit := pageSize(ctx, input.Limit)
+
+	posts, err := listAllPages(ctx, limit, func(start, limit int) (*PageList, error) {
+		return client.ListSpaceBlogPosts(ctx, input.SpaceKey, start, limit)
+	})
+	if err != nil {
+		return FetchBlogPostsOutput{}, fmt.Errorf("list blog posts: %w", err)
+	}
+
+	docs := make([]transform.Document, 0, len(posts))
+	for _, post := range posts {
+		doc := pageToDocument(post, input.BaseURL, source, ConvertOptions{})
+		if post.History != nil {
+			setUser(doc.Metadata, "author", post.History.CreatedBy)
+			if published := post.History.CreatedAt(); !published.IsZero() {
+				doc.Metadata["published_at"] = published.Format(time.RFC3339)
+			}
+		}
+		docs = append(docs, doc)
+	}
+
+	ref, err := transform.StoreDocuments(ctx, docs)
+	if err != nil {
+		return FetchBlogPostsOutput{}, fmt.Errorf("store documents: %w", err)
+	}
+
+	return FetchBlogPostsOutput{
+		Ref:   ref,
+		Count: len(docs),
+	}, nil
+}
+
+// FetchBlogPosts creates a node for fetching Confluence blog posts.
+func FetchBlogPosts(input FetchBlogPostsInput) *core.Node[FetchBlogPostsInput, FetchBlogPostsOutput] {
+	return withPolicy(core.NewNode("confluence.FetchBlogPosts", FetchBlogPostsActivity, input))
+}
@@ -0,0 +1,128 @@
+package confluence
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Authentication modes reported in Capabilities.
+const (
+	// AuthModeAPIToken authenticates with an Atlassian account email and API
+	// token over HTTP basic auth.
+	AuthModeAPIToken = "api_token"
+)
+
+// Operation access levels reported in Capabilities.
+const (
+	AccessRead  = "read"
+	AccessWrite = "write"
+)
+
+// Capabilities describes what the provider supports, in a form tools can use
+// to render configuration forms and validate workflow graphs.
+type Capabilities struct {
+	Provider    string      `json:"provider"`
+	Version     string      `json:"version"`
+	AuthModes   []string    `json:"authModes"`
+	APIVersions []string    `json:"apiVersions"`
+	Operations  []Operation `json:"operations"`
+	Workflows   []string    `json:"workflows"`
+}
+
+// Operation describes a registered activity.
+type Operation struct {
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases,omitempty"`
+	Access  string   `json:"access"`
+	Input   []Field  `json:"input"`
+}
+
+// Field describes an activity input field.
+type Field struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Required bool   `json:"required,omitempty"`
+	// Validate holds the validation rules of the field, such as "min=0".
+	Validate string `json:"validate,omitempty"`
+	// Secret marks credentials that forms should mask.
+	Secret bool `json:"secret,omitempty"`
+}
+
+// ProviderCapabilities returns the capabilities of the provider built with
+// the same options, listing only the activities Provider would register.
+func ProviderCapabilities(opts ...ProviderOption) Capabilities {
+	var cfg providerConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	caps := Capabilities{
+		Provider:    ProviderName,
+		Version:     ProviderVersion,
+		AuthModes:   []string{AuthModeAPIToken},
+		APIVersions: []string{"/wiki/rest/api"},
+		Workflows:   []string{SyncSpaceWorkflowName, WatchSpaceWorkflowName},
+	}
+	for _, r := range registrations {
+		if !cfg.includes(r) {
+			continue
+		}
+
+		op := Operation{
+			Name:   r.name,
+			Access: AccessRead,
+			Input:  inputFields(r.fn, cfg.resolver != nil || len(cfg.sites) > 0),
+		}
+		if !cfg.noAliases {
+			op.Aliases = r.formerNames()
+		}
+		if r.writes {
+			op.Access = AccessWrite
+		}
+		caps.Operations = append(caps.Operations, op)
+	}
+	return caps
+}
+
+// inputFields describes the fields of an activity's input struct. With
+// default credentials or registered sites the connection fields are
+// optional.
+func inputFields(fn any, defaultCredentials bool) []Field {
+	typ := reflect.TypeOf(fn)
+	if typ.Kind() != reflect.Func || typ.NumIn() < 2 || typ.In(1).Kind() != reflect.Struct {
+		return nil
+	}
+	input := typ.In(1)
+
+	fields := make([]Field, 0, input.NumField())
+	for i := 0; i < input.NumField(); i++ {
+		f := input.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		rules := f.Tag.Get("validate")
+		required := hasRule(rules, "required") || hasRule(rules, "minlen=1")
+		switch f.Name {
+		case "BaseURL", "Email", "APIToken":
+			required = required && !defaultCredentials
+		}
+		fields = append(fields, Field{
+			Name:     f.Name,
+			Type:     f.Type.String(),
+			Required: required,
+			Validate: rules,
+			Secret:   f.Name == "APIToken",
+		})
+	}
+	return fields
+}
+
+func hasRule(rules, rule string) bool {
+	for _, r := range strings.Split(rules, ",") {
+		if strings.TrimSpace(r) == rule {
+			return true
+		}
+	}
+	return false
+}
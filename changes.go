@@ -0,0 +1,146 @@
+package confluence
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	transform "github.com/resolute-sh/resolute-transform"
+	"github.com/resolute-sh/resolute/core"
+)
+
+// ChangesSinceInput is the input for ChangesSinceActivity.
+type ChangesSinceInput struct {
+	BaseURL  string `validate:"required,url"`
+	Email    string `validate:"required"`
+	APIToken string `validate:"required"`
+	Site     string
+	SpaceKey string    `validate:"required"`
+	Since    time.Time `validate:"required"`
+
+	// PreviousRef optionally references the Documents of a prior sync.
+	// Pages present in the snapshot but no longer listed in the space are
+	// reported as deleted, which catches pages purged from the trash.
+	PreviousRef core.DataRef
+
+	// Limit is the number of pages requested per API call, up to the
+	// API maximum of 250. Defaults to 250.
+	Limit int `validate:"min=0"`
+
+	// IncludeArchivedSpaces reports the changes of the space even when it
+	// is archived, which CQL searches leave out by default.
+	IncludeArchivedSpaces bool
+}
+
+// ChangesSinceOutput is the output of ChangesSinceActivity.
+type ChangesSinceOutput struct {
+	// Created lists the pages created at or after Since.
+	Created []string
+	// Updated lists the older pages modified at or after Since.
+	Updated []string
+	// Deleted lists the trashed pages, and with PreviousRef the pages that
+	// disappeared since the previous sync.
+	Deleted []string
+
+	// Watermark is the latest modification time among the changed pages, or
+	// Since if nothing changed. Pass it as Since to the next call.
+	Watermark time.Time
+}
+
+// ChangesSinceActivity reports which pages of a space were created, updated,
+// or deleted since a point in time, without downloading page bodies.
+// Confluence does not record when content was trashed, so every page in the
+// trash is reported as deleted on each call; consumers should treat
+// deletions as idempotent evictions.
+func ChangesSinceActivity(ctx context.Context, input ChangesSinceInput) (_ ChangesSinceOutput, err error) {
+	defer classifyError(&err)
+
+	if err := resolveCredentials(ctx, input.Site, &input.BaseURL, &input.Email, &input.APIToken); err != nil {
+		return ChangesSinceOutput{}, err
+	}
+	if err := validateInput(input); err != nil {
+		return ChangesSinceOutput{}, err
+	}
+
+	client := NewClient(ClientConfig{
+		BaseURL:  input.BaseURL,
+		Email:    input.Email,
+		APIToken: input.APIToken,
+	})
+
+	limit := pageSize(ctx, input.Limit)
+
+	output := ChangesSinceOutput{Watermark: input.Since}
+
+	changed, err := listAllPages(ctx, limit, func(start, limit int) (*PageList, error) {
+		return client.SearchContentWithOptions(ctx, sinceCQL(input.SpaceKey, input.Since), start, limit, SearchOptions{
+			Expand:                []string{"version", "history"},
+			IncludeArchivedSpaces: input.IncludeArchivedSpaces,
+		})
+	})
+	if err != nil {
+		return ChangesSinceOutput{}, fmt.Errorf("search changed pages: %w", err)
+	}
+	for _, page := range changed {
+		modified := page.Version.ModifiedAt()
+		if modified.Before(input.Since) {
+			continue
+		}
+		if modified.After(output.Watermark) {
+			output.Watermark = modified
+		}
+
+		if page.History != nil && !page.History.CreatedAt().Before(input.Since) {
+			output.Created = append(output.Created, page.ID)
+		} else {
+			output.Updated = append(output.Updated, page.ID)
+		}
+	}
+
+	trashed, err := listAllPages(ctx, limit, func(start, limit int) (*PageList, error) {
+		return client.ListTrashedPages(ctx, input.SpaceKey, start, limit)
+	})
+	if err != nil {
+		return ChangesSinceOutput{}, fmt.Errorf("list trashed pages: %w", err)
+	}
+	deleted := make(map[string]bool, len(trashed))
+	for _, page := range trashed {
+		deleted[page.ID] = true
+		output.Deleted = append(output.Deleted, page.ID)
+	}
+
+	if !input.PreviousRef.IsEmpty() {
+		previous, err := transform.LoadDocuments(ctx, input.PreviousRef)
+		if err != nil {
+			return ChangesSinceOutput{}, fmt.Errorf("load previous documents: %w", err)
+		}
+
+		current, err := listAllPages(ctx, limit, func(start, limit int) (*PageList, error) {
+			return client.ListSpacePageSummaries(ctx, input.SpaceKey, start, limit)
+		})
+		if err != nil {
+			return ChangesSinceOutput{}, fmt.Errorf("list space pages: %w", err)
+		}
+
+		live := make(map[string]bool, len(current))
+		for _, page := range current {
+			live[page.ID] = true
+		}
+
+		for _, doc := range previous {
+			id := documentPageID(doc)
+			if id == "" || live[id] || deleted[id] || doc.Metadata["deleted"] == "true" {
+				continue
+			}
+			deleted[id] = true
+			output.Deleted = append(output.Deleted, id)
+		}
+	}
+
+	return output, nil
+}
+
+// ChangesSince creates a node for listing the pages changed since a time.
+func ChangesSince(input ChangesSinceInput) *core.Node[ChangesSinceInput, ChangesSinceOutput] {
+	return withPolicy(core.NewNode("confluence.ChangesSince", ChangesSinceActivity, input))
+}
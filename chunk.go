@@ -0,0 +1,60 @@
+package confluence
+
+import (
+	"context"
+	"fmt"
+
+	transform "github.com/resolute-sh/resolute-transform"
+	"github.com/resolute-sh/resolute/core"
+)
+
+// ChunkDocumentsInput is the input for ChunkDocumentsActivity.
+type ChunkDocumentsInput struct {
+	Ref     core.DataRef
+	Options transform.ChunkOptions
+}
+
+// ChunkDocumentsOutput is the output of ChunkDocumentsActivity.
+type ChunkDocumentsOutput struct {
+	Ref   core.DataRef
+	Count int
+}
+
+// ChunkDocumentsActivity splits stored Documents into chunks and stores the
+// result. Unlike transform.ChunkActivity it works on refs, so documents
+// never pass through workflow history.
+func ChunkDocumentsActivity(ctx context.Context, input ChunkDocumentsInput) (_ ChunkDocumentsOutput, err error) {
+	defer classifyError(&err)
+
+	if err := validateInput(input); err != nil {
+		return ChunkDocumentsOutput{}, err
+	}
+
+	docs, err := transform.LoadDocuments(ctx, input.Ref)
+	if err != nil {
+		return ChunkDocumentsOutput{}, fmt.Errorf("load documents: %w", err)
+	}
+
+	chunked, err := transform.ChunkActivity(ctx, transform.ChunkInput{
+		Documents: docs,
+		Options:   input.Options,
+	})
+	if err != nil {
+		return ChunkDocumentsOutput{}, fmt.Errorf("chunk documents: %w", err)
+	}
+
+	ref, err := transform.StoreDocuments(ctx, chunked.Documents)
+	if err != nil {
+		return ChunkDocumentsOutput{}, fmt.Errorf("store documents: %w", err)
+	}
+
+	return ChunkDocumentsOutput{
+		Ref:   ref,
+		Count: chunked.Count,
+	}, nil
+}
+
+// ChunkDocuments creates a node for chunking stored Documents.
+func ChunkDocuments(input ChunkDocumentsInput) *core.Node[ChunkDocumentsInput, ChunkDocumentsOutput] {
+	return withPolicy(core.NewNode("confluence.ChunkDocuments", ChunkDocumentsActivity, input))
+}
@@ -1,13 +1,21 @@
 package confluence
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
+
+	"github.com/resolute-sh/resolute-confluence/cql"
 )
 
 // Client is a Confluence REST API client.
@@ -16,6 +24,13 @@ type Client struct {
 	email      string
 	apiToken   string
 	httpClient *http.Client
+	retry      RequestRetryPolicy
+	// throttle paces requests to the rate limit of the site, unless
+	// disabled.
+	throttle *rateLimiter
+
+	// compress gzips large request bodies until the server rejects them.
+	compress atomic.Bool
 }
 
 // ClientConfig contains configuration for creating a Confluence client.
@@ -23,26 +38,112 @@ type ClientConfig struct {
 	BaseURL  string
 	Email    string
 	APIToken string
-	Timeout  time.Duration
+	// Timeout bounds each request. Defaults to the Timeout of the
+	// activity defaults, or 30 seconds.
+	Timeout time.Duration
+	// Retry configures how failed requests are retried, each attempt
+	// bounded by Timeout. Defaults to the Retry of the activity defaults,
+	// which does not retry unless set.
+	Retry RequestRetryPolicy
+
+	// ProxyURL routes requests through an HTTP proxy. Without it, the
+	// HTTP_PROXY, HTTPS_PROXY, and NO_PROXY variables apply.
+	ProxyURL *url.URL
+
+	// DebugWriter receives a dump of every request and response: method,
+	// URL, status, and bodies truncated to 4 KiB. Headers are left out, so
+	// credentials are never written. Pass log.Writer() to dump to the
+	// standard logger. Defaults to the writer set with SetDebugWriter.
+	DebugWriter io.Writer
+
+	// DisableCompression sends request bodies uncompressed. By default,
+	// JSON bodies of at least 64 KiB are gzipped, falling back to plain
+	// bodies if the server answers 415 Unsupported Media Type. Responses are
+	// always requested and decoded as gzip by the HTTP transport.
+	DisableCompression bool
+
+	// DisableThrottling turns off adaptive throttling. By default, the
+	// requests of every client of a site are paced from the X-RateLimit
+	// headers of its responses once less than a fifth of the quota
+	// remains, and paused for the Retry-After of throttled responses,
+	// rather than running into 429s on a shared quota.
+	DisableThrottling bool
 }
 
-// NewClient creates a new Confluence client.
+// NewClient creates a new Confluence client. The base URL is normalized with
+// NormalizeBaseURL; use ClientConfig.Validate to reject unusable configs
+// before creating a client.
 func NewClient(cfg ClientConfig) *Client {
-	timeout := cfg.Timeout
-	if timeout == 0 {
-		timeout = 30 * time.Second
+	baseURL := cfg.BaseURL
+	if normalized, err := NormalizeBaseURL(baseURL); err == nil {
+		baseURL = normalized
 	}
 
-	return &Client{
-		baseURL:  cfg.BaseURL,
+	c := &Client{
+		baseURL:  baseURL,
 		email:    cfg.Email,
 		apiToken: cfg.APIToken,
 		httpClient: &http.Client{
-			Timeout: timeout,
+			Timeout: cfg.Timeout,
 		},
+		retry: cfg.Retry,
+	}
+	if !cfg.DisableThrottling {
+		c.throttle = siteRateLimiter(baseURL)
+	}
+	if cfg.ProxyURL != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = http.ProxyURL(cfg.ProxyURL)
+		c.httpClient.Transport = transport
+	}
+	debug := cfg.DebugWriter
+	if debug == nil {
+		debug = defaultDebugWriter()
+	}
+	if debug != nil {
+		c.httpClient.Transport = newDebugTransport(c.httpClient.Transport, debug)
 	}
+	c.compress.Store(!cfg.DisableCompression)
+	return c
+}
+
+// compressThreshold is the size from which JSON request bodies are gzipped.
+const compressThreshold = 64 << 10
+
+// maxCQLLength is the longest CQL query SearchContentByIDs sends. Escaped
+// into a URL it stays well below the 8 KiB request line limit of the API.
+const maxCQLLength = 4000
+
+// maxPageSize is the largest number of results the Confluence Cloud API
+// returns per request.
+const maxPageSize = 250
+
+// pageSize returns the number of results requested per API call for the
+// limit of an activity input: the API maximum unless a smaller limit is
+// given, by the input or the activity defaults. Larger limits are split into
+// requests of the maximum.
+func pageSize(ctx context.Context, limit int) int {
+	if limit <= 0 {
+		limit = activityDefaults(ctx).Limit
+	}
+	if limit <= 0 || limit > maxPageSize {
+		return maxPageSize
+	}
+	return limit
+}
+
+// documentExpand lists the properties expanded on content that is converted
+// to Documents.
+var documentExpand = []string{
+	"body.storage", "space", "version", "version.by", "history", "ancestors", "metadata.labels",
+	"restrictions.read.restrictions.user", "restrictions.read.restrictions.group",
+	"children.attachment", "metadata.properties." + scheduledPublishProperty,
 }
 
+// scheduledPublishProperty is the content property in which Confluence
+// records the scheduled publish date of a draft.
+const scheduledPublishProperty = "scheduled-publish"
+
 // Page represents a Confluence page.
 type Page struct {
 	ID      string    `json:"id"`
@@ -53,13 +154,162 @@ type Page struct {
 	Body    Body      `json:"body"`
 	Version Version   `json:"version"`
 	Links   PageLinks `json:"_links"`
+
+	// Extensions contains the sibling position of pages.
+	Extensions PageExtensions `json:"extensions"`
+
+	// Ancestors lists the page's ancestors from the space root down, when expanded.
+	Ancestors []Page `json:"ancestors,omitempty"`
+	// History contains creation details, when expanded.
+	History *History `json:"history,omitempty"`
+	// Metadata contains the labels of the content, when expanded.
+	Metadata *ContentMetadata `json:"metadata,omitempty"`
+	// Restrictions contains the restrictions set directly on the content,
+	// when expanded.
+	Restrictions *ContentRestrictions `json:"restrictions,omitempty"`
+	// Children contains the first page of attachments, when expanded.
+	Children *ContentChildren `json:"children,omitempty"`
+}
+
+// ContentChildren lists the expanded children of content.
+type ContentChildren struct {
+	Attachment *AttachmentList `json:"attachment,omitempty"`
+}
+
+// ContentRestrictions lists the restrictions of content by operation.
+type ContentRestrictions struct {
+	Read ContentRestriction `json:"read"`
+}
+
+// PageExtensions contains page ordering details.
+type PageExtensions struct {
+	// Position is the position of the page among its siblings, or "none"
+	// for pages that were never reordered.
+	Position json.RawMessage `json:"position,omitempty"`
+}
+
+// Position returns the position of the page among its siblings, and false
+// when Confluence does not report one.
+func (p Page) Position() (int, bool) {
+	n, err := strconv.Atoi(string(p.Extensions.Position))
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// ContentMetadata represents the expandable metadata of content.
+type ContentMetadata struct {
+	Labels LabelList `json:"labels"`
+	// Properties holds the expanded content properties by key.
+	Properties map[string]ContentProperty `json:"properties,omitempty"`
+}
+
+// ScheduledPublishAt returns the time a draft is scheduled to be published,
+// and false when it is not scheduled or the schedule is not expanded.
+func (p Page) ScheduledPublishAt() (time.Time, bool) {
+	if p.Metadata == nil {
+		return time.Time{}, false
+	}
+	property, ok := p.Metadata.Properties[scheduledPublishProperty]
+	if !ok {
+		return time.Time{}, false
+	}
+
+	var date string
+	switch value := property.Value.(type) {
+	case string:
+		date = value
+	case map[string]any:
+		date, _ = value["date"].(string)
+	}
+	t, err := time.Parse(time.RFC3339, date)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// LabelList is a page of content labels.
+type LabelList struct {
+	Results []Label `json:"results"`
+}
+
+// LabelNames returns the names of the page's labels, when expanded.
+func (p Page) LabelNames() []string {
+	if p.Metadata == nil {
+		return nil
+	}
+	names := make([]string, 0, len(p.Metadata.Labels.Results))
+	for _, label := range p.Metadata.Labels.Results {
+		names = append(names, label.Name)
+	}
+	return names
+}
+
+// History represents the creation history of content.
+type History struct {
+	CreatedBy   User   `json:"createdBy"`
+	CreatedDate string `json:"createdDate"`
+}
+
+// CreatedAt parses the creation date of the content.
+func (h History) CreatedAt() time.Time {
+	t, err := time.Parse(time.RFC3339, h.CreatedDate)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// User represents a Confluence user.
+type User struct {
+	AccountID   string `json:"accountId"`
+	DisplayName string `json:"displayName"`
+	Email       string `json:"email"`
 }
 
 // Space represents a Confluence space.
 type Space struct {
-	ID   int    `json:"id"`
-	Key  string `json:"key"`
-	Name string `json:"name"`
+	ID       int            `json:"id"`
+	Key      string         `json:"key"`
+	Name     string         `json:"name"`
+	Type     string         `json:"type,omitempty"`
+	Status   string         `json:"status,omitempty"`
+	Metadata *SpaceMetadata `json:"metadata,omitempty"`
+}
+
+// SpaceMetadata holds the expanded metadata of a space.
+type SpaceMetadata struct {
+	Labels LabelList `json:"labels"`
+}
+
+// spaceCategoryPrefix is the label prefix of space categories, which
+// Confluence stores as labels alongside the global ones.
+const spaceCategoryPrefix = "team"
+
+// LabelNames returns the names of the space's global labels, when expanded.
+func (s Space) LabelNames() []string {
+	return s.labelNames(func(prefix string) bool { return prefix != spaceCategoryPrefix })
+}
+
+// Categories returns the names of the space's categories, when its labels
+// are expanded.
+func (s Space) Categories() []string {
+	return s.labelNames(func(prefix string) bool { return prefix == spaceCategoryPrefix })
+}
+
+func (s Space) labelNames(match func(prefix string) bool) []string {
+	if s.Metadata == nil {
+		return nil
+	}
+	var names []string
+	for _, label := range s.Metadata.Labels.Results {
+		if match(label.Prefix) {
+			names = append(names, label.Name)
+		}
+	}
+	return names
 }
 
 // Body represents page content.
@@ -70,7 +320,8 @@ type Body struct {
 
 // StorageBody is the storage format content.
 type StorageBody struct {
-	Value string `json:"value"`
+	Value          string `json:"value"`
+	Representation string `json:"representation,omitempty"`
 }
 
 // ViewBody is the view format content.
@@ -83,6 +334,22 @@ type Version struct {
 	Number    int       `json:"number"`
 	When      string    `json:"when"`
 	CreatedAt time.Time `json:"createdAt"`
+	By        User      `json:"by"`
+	// Message is the change message the author left, if any.
+	Message string `json:"message,omitempty"`
+}
+
+// ModifiedAt returns the time the version was created, falling back to the
+// v1 "when" timestamp when createdAt is not populated.
+func (v Version) ModifiedAt() time.Time {
+	if !v.CreatedAt.IsZero() {
+		return v.CreatedAt
+	}
+	t, err := time.Parse(time.RFC3339, v.When)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
 }
 
 // PageLinks contains page links.
@@ -93,122 +360,872 @@ type PageLinks struct {
 
 // SearchResult represents a CQL search result.
 type SearchResult struct {
-	Results []SearchResultItem `json:"results"`
-	Start   int                `json:"start"`
-	Limit   int                `json:"limit"`
-	Size    int                `json:"size"`
+	Results   []SearchResultItem `json:"results"`
+	Start     int                `json:"start"`
+	Limit     int                `json:"limit"`
+	Size      int                `json:"size"`
+	TotalSize int                `json:"totalSize"`
+	Links     ListLinks          `json:"_links"`
+}
+
+// HasMore reports whether another page of results is available.
+func (r *SearchResult) HasMore() bool {
+	return r.Links.Next != ""
+}
+
+// NextCursor returns the cursor of the next page of results, or an empty
+// string when there is none.
+func (r *SearchResult) NextCursor() string {
+	if r.Links.Next == "" {
+		return ""
+	}
+	next, err := url.Parse(r.Links.Next)
+	if err != nil {
+		return ""
+	}
+	return next.Query().Get("cursor")
 }
 
 // SearchResultItem represents a single search result.
 type SearchResultItem struct {
-	Content   Page   `json:"content"`
-	Title     string `json:"title"`
-	Excerpt   string `json:"excerpt"`
-	URL       string `json:"url"`
+	Content    Page   `json:"content"`
+	Title      string `json:"title"`
+	Excerpt    string `json:"excerpt"`
+	URL        string `json:"url"`
 	ResultType string `json:"resultGlobalContainer"`
 }
 
 // SearchCQL searches for content using CQL.
 func (c *Client) SearchCQL(ctx context.Context, cql string, limit int) (*SearchResult, error) {
-	if limit <= 0 {
-		limit = 25
-	}
+	return c.SearchCQLPage(ctx, cql, "", limit)
+}
 
-	endpoint := fmt.Sprintf("%s/wiki/rest/api/search?cql=%s&limit=%d&expand=content.body.storage,content.space,content.version",
-		c.baseURL, url.QueryEscape(cql), limit)
+// Excerpt strategies of SearchOptions.
+const (
+	ExcerptHighlight = "highlight"
+	ExcerptIndexed   = "indexed"
+	ExcerptNone      = "none"
+)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
-	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
-	}
+// SearchOptions tunes the results of a CQL search.
+type SearchOptions struct {
+	// Excerpt is the excerpt strategy: ExcerptHighlight, ExcerptIndexed, or
+	// ExcerptNone. Empty uses the API default, highlighted excerpts.
+	Excerpt string
+	// IncludeArchivedSpaces includes the content of archived spaces, which
+	// searches leave out by default.
+	IncludeArchivedSpaces bool
+	// Expand lists the content properties to expand. Defaults to the
+	// properties Documents are built from.
+	Expand []string
+}
 
-	c.setAuth(req)
+// SearchCQLPage fetches one page of CQL search results. An empty cursor
+// starts from the first result; pass NextCursor of the previous page to
+// continue.
+func (c *Client) SearchCQLPage(ctx context.Context, cql, cursor string, limit int) (*SearchResult, error) {
+	return c.SearchCQLPageWithOptions(ctx, cql, cursor, limit, SearchOptions{})
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("execute request: %w", err)
+// SearchCQLPageWithOptions fetches one page of CQL search results like
+// SearchCQLPage, tuned by opts.
+func (c *Client) SearchCQLPageWithOptions(ctx context.Context, cql, cursor string, limit int, opts SearchOptions) (*SearchResult, error) {
+	if limit <= 0 {
+		limit = maxPageSize
+	}
+	properties := opts.Expand
+	if len(properties) == 0 {
+		properties = documentExpand
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("confluence API error: status=%d body=%s", resp.StatusCode, string(body))
+	query := url.Values{}
+	query.Set("cql", cql)
+	query.Set("limit", strconv.Itoa(limit))
+	expand := make([]string, 0, len(properties))
+	for _, e := range properties {
+		expand = append(expand, "content."+e)
+	}
+	query.Set("expand", strings.Join(expand, ","))
+	if opts.Excerpt != "" {
+		query.Set("excerpt", opts.Excerpt)
+	}
+	if opts.IncludeArchivedSpaces {
+		query.Set("includeArchivedSpaces", "true")
 	}
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+
+	endpoint := c.apiEndpoint(query, "search")
 
 	var result SearchResult
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("decode response: %w", err)
+	if err := c.getJSON(ctx, endpoint, &result); err != nil {
+		return nil, err
 	}
 
 	return &result, nil
 }
 
+// CountCQL returns the number of results matching a CQL query.
+func (c *Client) CountCQL(ctx context.Context, cql string) (int, error) {
+	query := url.Values{}
+	query.Set("cql", cql)
+	query.Set("limit", "1")
+
+	endpoint := c.apiEndpoint(query, "search")
+
+	var result SearchResult
+	if err := c.getJSON(ctx, endpoint, &result); err != nil {
+		return 0, err
+	}
+
+	return result.TotalSize, nil
+}
+
+// ValidateCQL checks a CQL query with a search that requests no results. A
+// query the API rejects as malformed is reported as an *InvalidCQLError;
+// other failures are returned as is.
+func (c *Client) ValidateCQL(ctx context.Context, cql string) error {
+	query := url.Values{}
+	query.Set("cql", cql)
+	query.Set("limit", "0")
+
+	endpoint := c.apiEndpoint(query, "search")
+
+	var result SearchResult
+	err := c.getJSON(ctx, endpoint, &result)
+	if hasStatus(err, http.StatusBadRequest) {
+		var apiErr *APIError
+		errors.As(err, &apiErr)
+		return &InvalidCQLError{CQL: cql, Message: apiErrorMessage(apiErr.Body)}
+	}
+	return err
+}
+
 // GetPage fetches a single page by ID.
 func (c *Client) GetPage(ctx context.Context, pageID string) (*Page, error) {
-	endpoint := fmt.Sprintf("%s/wiki/rest/api/content/%s?expand=body.storage,space,version",
-		c.baseURL, pageID)
+	return c.GetContent(ctx, pageID, documentExpand)
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
-	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
+// GetContent fetches a single piece of content by ID, expanding the given
+// properties.
+func (c *Client) GetContent(ctx context.Context, id string, expand []string) (*Page, error) {
+	endpoint := c.apiEndpoint(url.Values{"expand": {strings.Join(expand, ",")}}, "content", id)
+
+	var page Page
+	if err := c.getJSON(ctx, endpoint, &page); err != nil {
+		return nil, err
 	}
 
-	c.setAuth(req)
+	return &page, nil
+}
+
+// PageList represents a single page of content listing results.
+type PageList struct {
+	Results []Page    `json:"results"`
+	Start   int       `json:"start"`
+	Limit   int       `json:"limit"`
+	Size    int       `json:"size"`
+	Links   ListLinks `json:"_links"`
+
+	// TotalSize is the number of results of a search, when reported.
+	TotalSize int `json:"totalSize,omitempty"`
+
+	// Malformed lists the results, by ID, that a streaming listing could
+	// not decode. They are not passed on, and the listing goes on.
+	Malformed []ItemError `json:"-"`
+}
+
+// ListLinks contains pagination links for list responses.
+type ListLinks struct {
+	Next string `json:"next"`
+}
+
+// HasMore reports whether another page of results is available.
+func (l *PageList) HasMore() bool {
+	return l.Links.Next != ""
+}
+
+// ContentQuery selects content for ListContent.
+type ContentQuery struct {
+	SpaceKey string
+	// Type is "page" (default) or "blogpost".
+	Type string
+	// Status filters by content status, such as "current" or "archived".
+	Status string
+	// Statuses filters by several content statuses at once, in addition
+	// to Status.
+	Statuses []string
+	// Expand lists the properties to expand. Defaults to the properties
+	// converted to Document fields and metadata.
+	Expand []string
+}
+
+// ListContent fetches one page of content matching query starting at offset start.
+func (c *Client) ListContent(ctx context.Context, query ContentQuery, start, limit int) (*PageList, error) {
+	var list PageList
+	if err := c.getJSON(ctx, c.contentEndpoint(query, start, limit), &list); err != nil {
+		return nil, err
+	}
+
+	return &list, nil
+}
 
-	resp, err := c.httpClient.Do(req)
+// StreamContent fetches one page of content matching query like
+// ListContent, but calls each for every result as it is decoded instead of
+// collecting them. The returned PageList has no Results.
+func (c *Client) StreamContent(ctx context.Context, query ContentQuery, start, limit int, each func(Page) error) (*PageList, error) {
+	stream := &pageStream{each: each}
+	if err := c.getJSON(ctx, c.contentEndpoint(query, start, limit), stream); err != nil {
+		return nil, err
+	}
+
+	return &stream.list, nil
+}
+
+func (c *Client) contentEndpoint(query ContentQuery, start, limit int) string {
+	if limit <= 0 {
+		limit = maxPageSize
+	}
+
+	params := url.Values{}
+	params.Set("spaceKey", query.SpaceKey)
+	params.Set("type", "page")
+	if query.Type != "" {
+		params.Set("type", query.Type)
+	}
+	if query.Status != "" {
+		params.Add("status", query.Status)
+	}
+	for _, status := range query.Statuses {
+		params.Add("status", status)
+	}
+	expand := query.Expand
+	if len(expand) == 0 {
+		expand = documentExpand
+	}
+	params.Set("expand", strings.Join(expand, ","))
+	params.Set("start", strconv.Itoa(start))
+	params.Set("limit", strconv.Itoa(limit))
+
+	return c.apiEndpoint(params, "content")
+}
+
+// GetSpacePages fetches the first page of pages in a space.
+func (c *Client) GetSpacePages(ctx context.Context, spaceKey string, limit int) ([]Page, error) {
+	list, err := c.ListSpacePages(ctx, spaceKey, 0, limit)
 	if err != nil {
-		return nil, fmt.Errorf("execute request: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
+	return list.Results, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("confluence API error: status=%d body=%s", resp.StatusCode, string(body))
+// ListSpacePages fetches one page of pages in a space starting at offset start.
+func (c *Client) ListSpacePages(ctx context.Context, spaceKey string, start, limit int) (*PageList, error) {
+	if limit <= 0 {
+		limit = maxPageSize
+	}
+
+	endpoint := c.apiEndpoint(url.Values{
+		"spaceKey": {spaceKey},
+		"type":     {"page"},
+		"start":    {strconv.Itoa(start)},
+		"limit":    {strconv.Itoa(limit)},
+		"expand":   {strings.Join(documentExpand, ",")},
+	}, "content")
+
+	var list PageList
+	if err := c.getJSON(ctx, endpoint, &list); err != nil {
+		return nil, err
+	}
+
+	return &list, nil
+}
+
+// ListSpaceBlogPosts fetches one page of blog posts in a space starting at offset start.
+func (c *Client) ListSpaceBlogPosts(ctx context.Context, spaceKey string, start, limit int) (*PageList, error) {
+	if limit <= 0 {
+		limit = maxPageSize
+	}
+
+	endpoint := c.apiEndpoint(url.Values{
+		"spaceKey": {spaceKey},
+		"type":     {"blogpost"},
+		"start":    {strconv.Itoa(start)},
+		"limit":    {strconv.Itoa(limit)},
+		"expand":   {strings.Join(documentExpand, ",")},
+	}, "content")
+
+	var list PageList
+	if err := c.getJSON(ctx, endpoint, &list); err != nil {
+		return nil, err
+	}
+
+	return &list, nil
+}
+
+// FindPageByTitle looks up a page by its exact title within a space. It
+// returns nil without an error when no page has that title.
+func (c *Client) FindPageByTitle(ctx context.Context, spaceKey, title string) (*Page, error) {
+	endpoint := c.apiEndpoint(url.Values{
+		"spaceKey": {spaceKey},
+		"title":    {title},
+		"type":     {"page"},
+		"expand":   {"body.storage,space,version,ancestors"},
+	}, "content")
+
+	var list PageList
+	if err := c.getJSON(ctx, endpoint, &list); err != nil {
+		return nil, err
+	}
+
+	if len(list.Results) == 0 {
+		return nil, nil
 	}
+	return &list.Results[0], nil
+}
+
+// GetPageDraft fetches the latest draft of a page: its unpublished edits,
+// or the page itself while it was never published. A page without a draft
+// is reported as a 404 APIError.
+func (c *Client) GetPageDraft(ctx context.Context, pageID string) (*Page, error) {
+	endpoint := c.apiEndpoint(url.Values{
+		"status": {"draft"},
+		"expand": {strings.Join(documentExpand, ",")},
+	}, "content", pageID)
 
 	var page Page
-	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
-		return nil, fmt.Errorf("decode response: %w", err)
+	if err := c.getJSON(ctx, endpoint, &page); err != nil {
+		return nil, err
 	}
 
 	return &page, nil
 }
 
-// GetSpacePages fetches all pages in a space.
-func (c *Client) GetSpacePages(ctx context.Context, spaceKey string, limit int) ([]Page, error) {
+// ListChildPages fetches one page of the direct children of a page starting at offset start.
+func (c *Client) ListChildPages(ctx context.Context, pageID string, start, limit int) (*PageList, error) {
+	if limit <= 0 {
+		limit = maxPageSize
+	}
+
+	endpoint := c.apiEndpoint(url.Values{
+		"start":  {strconv.Itoa(start)},
+		"limit":  {strconv.Itoa(limit)},
+		"expand": {strings.Join(documentExpand, ",")},
+	}, "content", pageID, "child", "page")
+
+	var list PageList
+	if err := c.getJSON(ctx, endpoint, &list); err != nil {
+		return nil, err
+	}
+
+	return &list, nil
+}
+
+// ListTrashedPages fetches one page of trashed pages in a space starting at offset start.
+func (c *Client) ListTrashedPages(ctx context.Context, spaceKey string, start, limit int) (*PageList, error) {
+	if limit <= 0 {
+		limit = maxPageSize
+	}
+
+	endpoint := c.apiEndpoint(url.Values{
+		"spaceKey": {spaceKey},
+		"type":     {"page"},
+		"status":   {"trashed"},
+		"start":    {strconv.Itoa(start)},
+		"limit":    {strconv.Itoa(limit)},
+		"expand":   {"space,version"},
+	}, "content")
+
+	var list PageList
+	if err := c.getJSON(ctx, endpoint, &list); err != nil {
+		return nil, err
+	}
+
+	return &list, nil
+}
+
+// ListSpacePageSummaries fetches one page of pages in a space without bodies.
+func (c *Client) ListSpacePageSummaries(ctx context.Context, spaceKey string, start, limit int) (*PageList, error) {
 	if limit <= 0 {
-		limit = 25
+		limit = maxPageSize
+	}
+
+	endpoint := c.apiEndpoint(url.Values{
+		"spaceKey": {spaceKey},
+		"type":     {"page"},
+		"start":    {strconv.Itoa(start)},
+		"limit":    {strconv.Itoa(limit)},
+		"expand":   {"space,version"},
+	}, "content")
+
+	var list PageList
+	if err := c.getJSON(ctx, endpoint, &list); err != nil {
+		return nil, err
 	}
 
-	endpoint := fmt.Sprintf("%s/wiki/rest/api/content?spaceKey=%s&limit=%d&expand=body.storage,space,version",
-		c.baseURL, spaceKey, limit)
+	return &list, nil
+}
+
+// SearchPages fetches one page of pages matching a CQL query starting at offset start.
+func (c *Client) SearchPages(ctx context.Context, cql string, start, limit int) (*PageList, error) {
+	return c.SearchContent(ctx, cql, documentExpand, start, limit)
+}
+
+// SearchContent fetches one page of content matching a CQL query starting at
+// offset start, expanding the given properties.
+func (c *Client) SearchContent(ctx context.Context, cql string, expand []string, start, limit int) (*PageList, error) {
+	return c.SearchContentWithOptions(ctx, cql, start, limit, SearchOptions{Expand: expand})
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+// SearchContentWithOptions fetches one page of content matching a CQL query
+// like SearchContent, tuned by opts. Excerpts do not apply to content
+// searches, and only the properties listed in opts.Expand are expanded.
+func (c *Client) SearchContentWithOptions(ctx context.Context, cql string, start, limit int, opts SearchOptions) (*PageList, error) {
+	var list PageList
+	if err := c.getJSON(ctx, c.searchContentEndpoint(cql, start, limit, opts), &list); err != nil {
+		return nil, err
+	}
+
+	return &list, nil
+}
+
+// StreamSearchContent fetches one page of content matching a CQL query like
+// SearchContent, but calls each for every result as it is decoded instead
+// of collecting them. The returned PageList has no Results.
+func (c *Client) StreamSearchContent(ctx context.Context, cql string, expand []string, start, limit int, each func(Page) error) (*PageList, error) {
+	return c.StreamSearchContentWithOptions(ctx, cql, start, limit, SearchOptions{Expand: expand}, each)
+}
+
+// StreamSearchContentWithOptions streams one page of content matching a CQL
+// query like StreamSearchContent, tuned by opts as in
+// SearchContentWithOptions.
+func (c *Client) StreamSearchContentWithOptions(ctx context.Context, cql string, start, limit int, opts SearchOptions, each func(Page) error) (*PageList, error) {
+	stream := &pageStream{each: each}
+	if err := c.getJSON(ctx, c.searchContentEndpoint(cql, start, limit, opts), stream); err != nil {
+		return nil, err
+	}
+
+	return &stream.list, nil
+}
+
+// SearchContentByIDs fetches the content with the given IDs, expanding the
+// given properties. The IDs are split across several searches so that no
+// query exceeds maxCQLLength, which keeps bulk lookups under the URL length
+// limits of the API. IDs that do not exist or are not visible are omitted.
+func (c *Client) SearchContentByIDs(ctx context.Context, ids []string, expand []string) ([]Page, error) {
+	var pages []Page
+	for _, query := range cql.FieldInChunks(cql.FieldID, maxCQLLength, ids...) {
+		start := 0
+		for {
+			list, err := c.SearchContent(ctx, query.String(), expand, start, maxPageSize)
+			if err != nil {
+				return nil, fmt.Errorf("search ids at %d: %w", start, err)
+			}
+			pages = append(pages, list.Results...)
+
+			start += len(list.Results)
+			if !list.HasMore() || len(list.Results) == 0 {
+				break
+			}
+		}
+	}
+	return pages, nil
+}
+
+func (c *Client) searchContentEndpoint(cql string, start, limit int, opts SearchOptions) string {
+	if limit <= 0 {
+		limit = maxPageSize
+	}
+
+	query := url.Values{
+		"cql":    {cql},
+		"start":  {strconv.Itoa(start)},
+		"limit":  {strconv.Itoa(limit)},
+		"expand": {strings.Join(opts.Expand, ",")},
+	}
+	if opts.IncludeArchivedSpaces {
+		query.Set("includeArchivedSpaces", "true")
+	}
+	return c.apiEndpoint(query, "content", "search")
+}
+
+// apiEndpoint returns the URL of a REST API resource: the path segments
+// under /wiki/rest/api, each escaped, followed by the encoded query if any.
+// Every endpoint is built with it so that IDs, space keys, and titles with
+// special characters can neither break the URL nor inject parameters.
+func (c *Client) apiEndpoint(query url.Values, segments ...string) string {
+	var b strings.Builder
+	b.WriteString(c.baseURL)
+	b.WriteString("/wiki/rest/api")
+	for _, segment := range segments {
+		b.WriteByte('/')
+		b.WriteString(url.PathEscape(segment))
+	}
+	if len(query) > 0 {
+		b.WriteByte('?')
+		b.WriteString(query.Encode())
+	}
+	return b.String()
+}
+
+// webURL returns the absolute URL of a link of the API, such as a webui or
+// download link, which Confluence Cloud gives relative to the /wiki context
+// path that NormalizeBaseURL strips from baseURL. Absolute links are
+// returned as they are, and an empty link as an empty URL.
+func webURL(baseURL, link string) string {
+	switch {
+	case link == "":
+		return ""
+	case strings.HasPrefix(link, "http://"), strings.HasPrefix(link, "https://"):
+		return link
+	case strings.HasPrefix(link, "/wiki/"):
+		return baseURL + link
+	default:
+		return baseURL + "/wiki" + link
+	}
+}
+
+// getJSON performs an authenticated GET request and decodes the JSON response into v.
+func (c *Client) getJSON(ctx context.Context, endpoint string, v any) error {
+	return c.doJSON(ctx, http.MethodGet, endpoint, nil, v)
+}
+
+// doJSON performs an authenticated request with an optional JSON body and
+// decodes the JSON response into v. A nil v discards the response body.
+func (c *Client) doJSON(ctx context.Context, method, endpoint string, body, v any) error {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("marshal request: %w", err)
+		}
+	}
+
+	if len(data) >= compressThreshold && c.compress.Load() {
+		err := c.doJSONCompressed(ctx, method, endpoint, data, v)
+		if !hasStatus(err, http.StatusUnsupportedMediaType) {
+			return err
+		}
+		c.compress.Store(false)
+	}
+
+	var reqBody io.Reader
+	if data != nil {
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
+		return fmt.Errorf("create request: %w", err)
 	}
 
 	c.setAuth(req)
 
-	resp, err := c.httpClient.Do(req)
+	return c.do(req, v)
+}
+
+// doJSONCompressed performs an authenticated request with a gzipped JSON
+// body.
+func (c *Client) doJSONCompressed(ctx context.Context, method, endpoint string, data []byte, v any) error {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return fmt.Errorf("compress request: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("compress request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, &buf)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	c.setAuth(req)
+	req.Header.Set("Content-Encoding", "gzip")
+
+	return c.do(req, v)
+}
+
+// requestContext bounds a request by the default timeout of the running
+// activity when the client config sets no timeout.
+func (c *Client) requestContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.httpClient.Timeout > 0 {
+		return ctx, func() {}
+	}
+	timeout := activityDefaults(ctx).Timeout
+	if timeout <= 0 {
+		timeout = defaultRequestTimeout
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// do executes an authenticated request and decodes the JSON response into
+// v, retrying as configured by the retry policy of the client. A nil v
+// discards the response body.
+func (c *Client) do(req *http.Request, v any) error {
+	first := true
+	return c.withRetry(req.Context(), req.Method, func() error {
+		if !first && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return fmt.Errorf("rewind request: %w", err)
+			}
+			req.Body = body
+		}
+		first = false
+		return c.attempt(req, v)
+	})
+}
+
+// attempt executes a request once and decodes the JSON response into v.
+func (c *Client) attempt(req *http.Request, v any) error {
+	resp, cancel, err := c.send(req, true)
 	if err != nil {
-		return nil, fmt.Errorf("execute request: %w", err)
+		return err
 	}
+	defer cancel()
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("confluence API error: status=%d body=%s", resp.StatusCode, string(body))
+		return &APIError{
+			Status:     resp.StatusCode,
+			Body:       string(body),
+			retryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+
+	if v == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	if stream, ok := v.(streamDecoder); ok {
+		return stream.decode(json.NewDecoder(resp.Body))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+
+	return nil
+}
+
+// send executes a request once, after waiting for the throttle of the
+// client, and returns the response. A bounded request is limited by the
+// request timeout until cancel is called; an unbounded one, such as a
+// download streamed for longer than any request timeout, only by its
+// context.
+func (c *Client) send(req *http.Request, bounded bool) (_ *http.Response, cancel context.CancelFunc, err error) {
+	if c.throttle != nil {
+		if err := c.throttle.wait(req.Context()); err != nil {
+			return nil, nil, fmt.Errorf("wait for rate limit: %w", err)
+		}
+	}
+
+	httpClient := c.httpClient
+	ctx, cancel := req.Context(), context.CancelFunc(func() {})
+	if bounded {
+		ctx, cancel = c.requestContext(ctx)
+		req = req.WithContext(ctx)
+	} else if httpClient.Timeout > 0 {
+		unbounded := *httpClient
+		unbounded.Timeout = 0
+		httpClient = &unbounded
+	}
+
+	countAPICall(ctx)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		cancel()
+		return nil, nil, fmt.Errorf("execute request: %w", err)
+	}
+	if c.throttle != nil {
+		c.throttle.observe(resp)
+	}
+	return resp, cancel, nil
+}
+
+// streamDecoder is implemented by response values that decode themselves
+// incrementally from the response body.
+type streamDecoder interface {
+	decode(dec *json.Decoder) error
+}
+
+// pageStream decodes a content listing, handing each result to each as soon
+// as it is decoded so that only one result is held in memory at a time. The
+// other fields of the listing are decoded into list.
+type pageStream struct {
+	list PageList
+	each func(Page) error
+}
+
+func (s *pageStream) decode(dec *json.Decoder) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+
+	rest := make(map[string]json.RawMessage)
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("decode response: %w", err)
+		}
+		key, _ := token.(string)
+
+		if key != "results" {
+			var value json.RawMessage
+			if err := dec.Decode(&value); err != nil {
+				return fmt.Errorf("decode response: %w", err)
+			}
+			rest[key] = value
+			continue
+		}
+
+		if err := expectDelim(dec, '['); err != nil {
+			return err
+		}
+		for dec.More() {
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return fmt.Errorf("decode response: %w", err)
+			}
+			var page Page
+			if err := json.Unmarshal(raw, &page); err != nil {
+				s.list.Malformed = append(s.list.Malformed, newItemError(rawID(raw), fmt.Errorf("decode response: %w", err)))
+				continue
+			}
+			if err := s.each(page); err != nil {
+				return err
+			}
+		}
+		if err := expectDelim(dec, ']'); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.Marshal(rest)
+	if err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.list); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+// rawID returns the id field of a JSON object that did not decode, or an
+// empty string.
+func rawID(raw json.RawMessage) string {
+	var item struct {
+		ID json.RawMessage `json:"id"`
+	}
+	if json.Unmarshal(raw, &item) != nil {
+		return ""
 	}
+	var id string
+	if json.Unmarshal(item.ID, &id) == nil {
+		return id
+	}
+	return string(item.ID)
+}
 
-	var result struct {
-		Results []Page `json:"results"`
+// expectDelim reads the next token and checks that it is delim.
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("decode response: %w", err)
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("decode response: %w", err)
+	if token != delim {
+		return fmt.Errorf("decode response: expected %v, got %v", delim, token)
 	}
+	return nil
+}
+
+// APIError is returned when the Confluence API responds with a non-success status.
+type APIError struct {
+	Status int
+	Body   string
 
-	return result.Results, nil
+	// retryAfter is the wait the Retry-After header of the response asked
+	// for, if any.
+	retryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("confluence API error: status=%d body=%s", e.Status, e.Body)
+}
+
+// StatusCode returns the HTTP status code. It implements core.HTTPStatusError.
+func (e *APIError) StatusCode() int {
+	return e.Status
+}
+
+// InvalidCQLError is returned by ValidateCQL for a query the search API
+// rejects as malformed.
+type InvalidCQLError struct {
+	CQL string
+	// Message is the parse error reported by the API.
+	Message string
+}
+
+func (e *InvalidCQLError) Error() string {
+	return fmt.Sprintf("invalid cql %q: %s", e.CQL, e.Message)
+}
+
+// apiErrorMessage extracts the message of a JSON API error body, falling
+// back to the raw body.
+func apiErrorMessage(body string) string {
+	var payload struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal([]byte(body), &payload); err == nil && payload.Message != "" {
+		return payload.Message
+	}
+	return strings.TrimSpace(body)
+}
+
+// ItemError reports the failure of a single item in a batch operation.
+type ItemError struct {
+	ID     string
+	Status int
+	Error  string
+}
+
+// newItemError builds an ItemError, recording the HTTP status of API errors.
+func newItemError(id string, err error) ItemError {
+	item := ItemError{ID: id, Error: err.Error()}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		item.Status = apiErr.Status
+	}
+	return item
+}
+
+// hasStatus reports whether err wraps an APIError with the given status code.
+func hasStatus(err error, status int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Status == status
+}
+
+// isInaccessible reports whether err means the credentials cannot read an
+// item: 403 Forbidden, or 404 Not Found, which Confluence also answers for
+// content restricted from the user.
+func isInaccessible(err error) bool {
+	return hasStatus(err, http.StatusForbidden) || hasStatus(err, http.StatusNotFound)
+}
+
+// isItemFailure reports whether err is confined to one item: a server
+// error, or a response that could not be decoded. Fetches of many items
+// report such errors and go on with the other items.
+func isItemFailure(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Status >= http.StatusInternalServerError
+	}
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	return errors.As(err, &syntaxErr) || errors.As(err, &typeErr)
 }
 
 func (c *Client) setAuth(req *http.Request) {
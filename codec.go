@@ -0,0 +1,127 @@
+package confluence
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	commonpb "go.temporal.io/api/common/v1"
+	"go.temporal.io/sdk/converter"
+)
+
+// redactedFields are the fields RedactionCodec clears by default.
+var redactedFields = []string{"APIToken"}
+
+// RedactionCodec is a Temporal payload codec that clears secret fields of
+// JSON payloads, such as the APIToken of activity inputs, before they are
+// persisted in workflow histories. Clearing is one-way: a redacted input
+// reaches its activity with an empty APIToken, which the activity fills
+// from the credentials registered for its Site (see SetSite) or the default
+// credentials (see SetDefaultCredentials). Workers that use the codec must
+// therefore register credentials for every site their workflows target.
+// Workflows that name a Site and leave the connection fields of inputs empty
+// keep secrets out of histories without a codec.
+//
+// Use it in the data converter of both the Temporal client and the worker:
+//
+//	dc := converter.NewCodecDataConverter(converter.GetDefaultDataConverter(),
+//		confluence.NewRedactionCodec())
+//	c, err := client.Dial(client.Options{DataConverter: dc})
+//
+// Payloads are redacted before any other codec, such as an encryption codec,
+// when the RedactionCodec is listed last in NewCodecDataConverter.
+type RedactionCodec struct {
+	fields map[string]bool
+}
+
+var _ converter.PayloadCodec = (*RedactionCodec)(nil)
+
+// NewRedactionCodec returns a codec clearing the given fields of JSON
+// payloads, at any depth. Without fields it clears APIToken.
+func NewRedactionCodec(fields ...string) *RedactionCodec {
+	if len(fields) == 0 {
+		fields = redactedFields
+	}
+	c := &RedactionCodec{fields: make(map[string]bool, len(fields))}
+	for _, field := range fields {
+		c.fields[field] = true
+	}
+	return c
+}
+
+// NewRedactingDataConverter wraps the default Temporal data converter with
+// a RedactionCodec clearing APIToken fields.
+func NewRedactingDataConverter() converter.DataConverter {
+	return converter.NewCodecDataConverter(converter.GetDefaultDataConverter(), NewRedactionCodec())
+}
+
+// Encode clears the redacted fields of JSON payloads. Other payloads are
+// passed through unchanged.
+func (c *RedactionCodec) Encode(payloads []*commonpb.Payload) ([]*commonpb.Payload, error) {
+	encoded := make([]*commonpb.Payload, len(payloads))
+	for i, p := range payloads {
+		redacted, err := c.redact(p)
+		if err != nil {
+			return nil, err
+		}
+		encoded[i] = redacted
+	}
+	return encoded, nil
+}
+
+// Decode returns payloads unchanged: redacted values cannot be restored.
+func (c *RedactionCodec) Decode(payloads []*commonpb.Payload) ([]*commonpb.Payload, error) {
+	return payloads, nil
+}
+
+// redact returns p with its redacted fields cleared, or p itself when it is
+// not JSON or has nothing to clear.
+func (c *RedactionCodec) redact(p *commonpb.Payload) (*commonpb.Payload, error) {
+	if string(p.GetMetadata()[converter.MetadataEncoding]) != converter.MetadataEncodingJSON {
+		return p, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(p.GetData()))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return p, nil
+	}
+	if !c.clear(value) {
+		return p, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("redact payload: %w", err)
+	}
+	return &commonpb.Payload{Metadata: p.GetMetadata(), Data: data}, nil
+}
+
+// clear empties the redacted fields of value in place and reports whether
+// any was set.
+func (c *RedactionCodec) clear(value any) bool {
+	cleared := false
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if s, ok := field.(string); ok && c.fields[key] {
+				if s != "" {
+					v[key] = ""
+					cleared = true
+				}
+				continue
+			}
+			if c.clear(field) {
+				cleared = true
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if c.clear(item) {
+				cleared = true
+			}
+		}
+	}
+	return cleared
+}
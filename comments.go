@@ -0,0 +1,342 @@
+package confluence
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	transform "github.com/resolute-sh/resolute-transform"
+	"github.com/resolute-sh/resolute/core"
+)
+
+// Comment locations.
+const (
+	CommentLocationFooter = "footer"
+	CommentLocationInline = "inline"
+)
+
+// Comment represents a Confluence page comment.
+type Comment struct {
+	ID         string            `json:"id"`
+	Title      string            `json:"title"`
+	Body       Body              `json:"body"`
+	Version    Version           `json:"version"`
+	History    *History          `json:"history,omitempty"`
+	Extensions CommentExtensions `json:"extensions"`
+	Ancestors  []Comment         `json:"ancestors,omitempty"`
+	Links      PageLinks         `json:"_links"`
+}
+
+// CommentExtensions contains comment location details.
+type CommentExtensions struct {
+	Location         string            `json:"location"`
+	InlineProperties *InlineProperties `json:"inlineProperties,omitempty"`
+}
+
+// InlineProperties describes the text an inline comment is anchored to.
+type InlineProperties struct {
+	OriginalSelection string `json:"originalSelection"`
+	MarkerRef         string `json:"markerRef"`
+}
+
+// CommentList represents a single page of comment results.
+type CommentList struct {
+	Results []Comment `json:"results"`
+	Start   int       `json:"start"`
+	Limit   int       `json:"limit"`
+	Size    int       `json:"size"`
+	Links   ListLinks `json:"_links"`
+}
+
+// HasMore reports whether another page of results is available.
+func (l *CommentList) HasMore() bool {
+	return l.Links.Next != ""
+}
+
+// commentExpand lists the properties expanded for comments.
+const commentExpand = "body.storage,version,history,ancestors,extensions.inlineProperties"
+
+// ListPageComments fetches one page of footer and inline comments on a page,
+// including replies, starting at offset start.
+func (c *Client) ListPageComments(ctx context.Context, pageID string, start, limit int) (*CommentList, error) {
+	if limit <= 0 {
+		limit = maxPageSize
+	}
+
+	endpoint := c.apiEndpoint(url.Values{
+		"depth":    {"all"},
+		"location": {"footer", "inline"},
+		"start":    {strconv.Itoa(start)},
+		"limit":    {strconv.Itoa(limit)},
+		"expand":   {commentExpand},
+	}, "content", pageID, "child", "comment")
+
+	var list CommentList
+	if err := c.getJSON(ctx, endpoint, &list); err != nil {
+		return nil, err
+	}
+
+	return &list, nil
+}
+
+// GetComment fetches a single comment by ID.
+func (c *Client) GetComment(ctx context.Context, commentID string) (*Comment, error) {
+	endpoint := c.apiEndpoint(url.Values{"expand": {commentExpand}}, "content", commentID)
+
+	var comment Comment
+	if err := c.getJSON(ctx, endpoint, &comment); err != nil {
+		return nil, err
+	}
+
+	return &comment, nil
+}
+
+// CreateCommentRequest describes a footer comment to create.
+type CreateCommentRequest struct {
+	PageID string
+	// ParentCommentID makes the comment a reply to another comment.
+	ParentCommentID string
+	// Body is the comment content in storage format.
+	Body string
+}
+
+// CreateComment creates a footer comment on a page and returns it.
+func (c *Client) CreateComment(ctx context.Context, req CreateCommentRequest) (*Comment, error) {
+	body := contentRequest{
+		Type:      "comment",
+		Container: &containerRef{ID: req.PageID, Type: "page"},
+		Body: &contentBody{
+			Storage: StorageBody{Value: req.Body, Representation: "storage"},
+		},
+	}
+	if req.ParentCommentID != "" {
+		body.Ancestors = []ancestorRef{{ID: req.ParentCommentID}}
+	}
+
+	endpoint := c.apiEndpoint(nil, "content")
+
+	var comment Comment
+	if err := c.doJSON(ctx, http.MethodPost, endpoint, body, &comment); err != nil {
+		return nil, err
+	}
+
+	return &comment, nil
+}
+
+// CommentOnPageInput is the input for CommentOnPageActivity.
+type CommentOnPageInput struct {
+	BaseURL  string `validate:"required,url"`
+	Email    string `validate:"required"`
+	APIToken string `validate:"required"`
+	Site     string
+	PageID   string `validate:"required"`
+	Body     string `validate:"required"`
+
+	// Format is the format of Body: "storage" (default) or "markdown".
+	Format string `validate:"oneof=|storage|markdown"`
+
+	// Mentions lists account IDs to mention at the start of the comment.
+	// Mentioned users are notified by Confluence.
+	Mentions []string
+
+	// ParentCommentID posts the comment as a reply.
+	ParentCommentID string
+}
+
+// CommentOnPageOutput is the output of CommentOnPageActivity.
+type CommentOnPageOutput struct {
+	CommentID string
+	URL       string
+}
+
+// CommentOnPageActivity posts a footer comment on a page.
+func CommentOnPageActivity(ctx context.Context, input CommentOnPageInput) (_ CommentOnPageOutput, err error) {
+	defer classifyError(&err)
+
+	if err := resolveCredentials(ctx, input.Site, &input.BaseURL, &input.Email, &input.APIToken); err != nil {
+		return CommentOnPageOutput{}, err
+	}
+	if err := validateInput(input); err != nil {
+		return CommentOnPageOutput{}, err
+	}
+
+	client := NewClient(ClientConfig{
+		BaseURL:  input.BaseURL,
+		Email:    input.Email,
+		APIToken: input.APIToken,
+	})
+
+	comment, err := client.CreateComment(ctx, CreateCommentRequest{
+		PageID:          input.PageID,
+		ParentCommentID: input.ParentCommentID,
+		Body:            mentionParagraph(input.Mentions) + toStorage(input.Body, input.Format),
+	})
+	if err != nil {
+		return CommentOnPageOutput{}, fmt.Errorf("create comment on %s: %w", input.PageID, err)
+	}
+
+	return CommentOnPageOutput{
+		CommentID: comment.ID,
+		URL:       webURL(input.BaseURL, comment.Links.WebUI),
+	}, nil
+}
+
+// mentionParagraph renders a paragraph mentioning each account, or an empty
+// string when there are none.
+func mentionParagraph(accountIDs []string) string {
+	if len(accountIDs) == 0 {
+		return ""
+	}
+
+	mentions := make([]string, 0, len(accountIDs))
+	for _, id := range accountIDs {
+		mentions = append(mentions, `<ac:link><ri:user ri:account-id="`+html.EscapeString(id)+`" /></ac:link>`)
+	}
+	return "<p>" + strings.Join(mentions, " ") + "</p>"
+}
+
+// CommentOnPage creates a node for commenting on a Confluence page.
+func CommentOnPage(input CommentOnPageInput) *core.Node[CommentOnPageInput, CommentOnPageOutput] {
+	return withPolicy(core.NewNode("confluence.CommentOnPage", CommentOnPageActivity, input))
+}
+
+// FetchCommentsInput is the input for FetchCommentsActivity.
+type FetchCommentsInput struct {
+	BaseURL  string `validate:"required,url"`
+	Email    string `validate:"required"`
+	APIToken string `validate:"required"`
+	Site     string
+	PageIDs  []string `validate:"minlen=1"`
+
+	// Limit is the number of comments requested per API call, up to the
+	// API maximum of 250. Defaults to 250.
+	Limit int `validate:"min=0"`
+
+	// Source is the Source of the stored Documents, which tells apart the
+	// Documents of several sites. Defaults to the hostname of BaseURL.
+	Source string
+}
+
+// FetchCommentsOutput is the output of FetchCommentsActivity.
+type FetchCommentsOutput struct {
+	Ref   core.DataRef
+	Count int
+	// Denied lists the pages, by ID, whose comments were left out because
+	// the credentials cannot read them.
+	Denied []ItemError
+	// Errors lists the pages, by ID, whose comments were left out because
+	// listing them failed with a server error or a malformed response.
+	Errors []ItemError
+}
+
+// FetchCommentsActivity fetches footer and inline comments for a set of
+// pages and stores each comment as a Document. Inline comments carry the
+// "inline_marker_ref" that links them to the commented page text. Pages the
+// credentials cannot read are reported in Denied, and pages whose comments
+// fail to list in Errors, without failing the fetch.
+func FetchCommentsActivity(ctx context.Context, input FetchCommentsInput) (_ FetchCommentsOutput, err error) {
+	defer classifyError(&err)
+
+	if err := resolveCredentials(ctx, input.Site, &input.BaseURL, &input.Email, &input.APIToken); err != nil {
+		return FetchCommentsOutput{}, err
+	}
+	if err := validateInput(input); err != nil {
+		return FetchCommentsOutput{}, err
+	}
+
+	client := NewClient(ClientConfig{
+		BaseURL:  input.BaseURL,
+		Email:    input.Email,
+		APIToken: input.APIToken,
+	})
+
+	source := documentSource(input.BaseURL, input.Source)
+
+	limit := pageSize(ctx, input.Limit)
+
+	var docs []transform.Document
+	var denied, failed []ItemError
+	for _, pageID := range input.PageIDs {
+		var pageDocs []transform.Document
+		start := 0
+		for {
+			list, err := client.ListPageComments(ctx, pageID, start, limit)
+			if isInaccessible(err) {
+				denied = append(denied, newItemError(pageID, err))
+				pageDocs = nil
+				break
+			}
+			if isItemFailure(err) {
+				failed = append(failed, newItemError(pageID, err))
+				pageDocs = nil
+				break
+			}
+			if err != nil {
+				return FetchCommentsOutput{}, fmt.Errorf("list comments of %s at %d: %w", pageID, start, err)
+			}
+
+			for _, comment := range list.Results {
+				pageDocs = append(pageDocs, commentToDocument(comment, pageID, input.BaseURL, source))
+			}
+
+			start += len(list.Results)
+			if !list.HasMore() || len(list.Results) == 0 {
+				break
+			}
+		}
+		docs = append(docs, pageDocs...)
+
+		recordHeartbeat(ctx, len(docs))
+	}
+
+	ref, err := transform.StoreDocuments(ctx, docs)
+	if err != nil {
+		return FetchCommentsOutput{}, fmt.Errorf("store documents: %w", err)
+	}
+
+	return FetchCommentsOutput{
+		Ref:    ref,
+		Count:  len(docs),
+		Denied: denied,
+		Errors: failed,
+	}, nil
+}
+
+func commentToDocument(comment Comment, pageID, baseURL, source string) transform.Document {
+	metadata := map[string]string{
+		"comment_id":       comment.ID,
+		"page_id":          pageID,
+		"content_type":     "comment",
+		"comment_location": comment.Extensions.Location,
+		"version":          fmt.Sprintf("%d", comment.Version.Number),
+	}
+	if comment.History != nil {
+		setUser(metadata, "author", comment.History.CreatedBy)
+	}
+	if n := len(comment.Ancestors); n > 0 {
+		metadata["parent_comment_id"] = comment.Ancestors[n-1].ID
+	}
+	if props := comment.Extensions.InlineProperties; props != nil {
+		metadata["inline_marker_ref"] = props.MarkerRef
+		metadata["original_selection"] = props.OriginalSelection
+	}
+
+	return redactDocument(transform.Document{
+		ID:        comment.ID,
+		Content:   ConvertStorage(comment.Body.Storage.Value, ConvertOptions{}).Text,
+		Title:     strings.TrimSpace(comment.Title),
+		Source:    source,
+		URL:       webURL(baseURL, comment.Links.WebUI),
+		Metadata:  metadata,
+		UpdatedAt: comment.Version.ModifiedAt(),
+	})
+}
+
+// FetchComments creates a node for fetching Confluence page comments.
+func FetchComments(input FetchCommentsInput) *core.Node[FetchCommentsInput, FetchCommentsOutput] {
+	return withPolicy(core.NewNode("confluence.FetchComments", FetchCommentsActivity, input))
+}
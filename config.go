@@ -0,0 +1,205 @@
+package confluence
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables read by ConfigFromEnv.
+const (
+	EnvBaseURL            = "CONFLUENCE_BASE_URL"
+	EnvEmail              = "CONFLUENCE_EMAIL"
+	EnvAPIToken           = "CONFLUENCE_API_TOKEN"
+	EnvProxyURL           = "CONFLUENCE_PROXY_URL"
+	EnvTimeout            = "CONFLUENCE_TIMEOUT"
+	EnvDisableCompression = "CONFLUENCE_DISABLE_COMPRESSION"
+	EnvDisableThrottling  = "CONFLUENCE_DISABLE_THROTTLING"
+	EnvDebug              = "CONFLUENCE_DEBUG"
+	EnvRetryMaxAttempts   = "CONFLUENCE_RETRY_MAX_ATTEMPTS"
+	EnvRetryMaxElapsed    = "CONFLUENCE_RETRY_MAX_ELAPSED"
+	EnvRetryJitter        = "CONFLUENCE_RETRY_JITTER"
+	EnvRetryStatuses      = "CONFLUENCE_RETRY_STATUSES"
+)
+
+// ConfigFromEnv reads a ClientConfig from the environment:
+//
+//	CONFLUENCE_BASE_URL              site URL, such as https://example.atlassian.net (required)
+//	CONFLUENCE_EMAIL                 account email (required)
+//	CONFLUENCE_API_TOKEN             API token (required)
+//	CONFLUENCE_PROXY_URL             HTTP proxy URL
+//	CONFLUENCE_TIMEOUT               request timeout, such as 45s
+//	CONFLUENCE_DISABLE_COMPRESSION   true to send request bodies uncompressed
+//	CONFLUENCE_DISABLE_THROTTLING    true to stop pacing requests to the rate limit
+//	CONFLUENCE_DEBUG                 true to dump requests and responses to stderr
+//	CONFLUENCE_RETRY_MAX_ATTEMPTS    attempts per request, the first one included
+//	CONFLUENCE_RETRY_MAX_ELAPSED     time after which requests are no longer retried, such as 2m
+//	CONFLUENCE_RETRY_JITTER          full, equal, or none
+//	CONFLUENCE_RETRY_STATUSES        retried status codes, such as 429,503
+//
+// Every invalid or missing variable is reported in the returned error.
+func ConfigFromEnv() (ClientConfig, error) {
+	cfg := ClientConfig{
+		BaseURL:  os.Getenv(EnvBaseURL),
+		Email:    os.Getenv(EnvEmail),
+		APIToken: os.Getenv(EnvAPIToken),
+	}
+
+	var errs []error
+	for _, v := range []struct{ name, value string }{
+		{EnvBaseURL, cfg.BaseURL},
+		{EnvEmail, cfg.Email},
+		{EnvAPIToken, cfg.APIToken},
+	} {
+		if v.value == "" {
+			errs = append(errs, fmt.Errorf("%s is not set", v.name))
+		}
+	}
+	if cfg.BaseURL != "" {
+		baseURL, err := NormalizeBaseURL(cfg.BaseURL)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", EnvBaseURL, err))
+		}
+		cfg.BaseURL = baseURL
+	}
+
+	if raw := os.Getenv(EnvProxyURL); raw != "" {
+		if u, err := url.Parse(raw); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("%s: %q is not a URL", EnvProxyURL, raw))
+		} else {
+			cfg.ProxyURL = u
+		}
+	}
+	if raw := os.Getenv(EnvTimeout); raw != "" {
+		if d, err := time.ParseDuration(raw); err != nil || d <= 0 {
+			errs = append(errs, fmt.Errorf("%s: %q is not a positive duration", EnvTimeout, raw))
+		} else {
+			cfg.Timeout = d
+		}
+	}
+	if raw := os.Getenv(EnvDisableCompression); raw != "" {
+		if b, err := strconv.ParseBool(raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %q is not a boolean", EnvDisableCompression, raw))
+		} else {
+			cfg.DisableCompression = b
+		}
+	}
+	if raw := os.Getenv(EnvDisableThrottling); raw != "" {
+		if b, err := strconv.ParseBool(raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %q is not a boolean", EnvDisableThrottling, raw))
+		} else {
+			cfg.DisableThrottling = b
+		}
+	}
+
+	if raw := os.Getenv(EnvDebug); raw != "" {
+		if b, err := strconv.ParseBool(raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %q is not a boolean", EnvDebug, raw))
+		} else if b {
+			cfg.DebugWriter = os.Stderr
+		}
+	}
+
+	if raw := os.Getenv(EnvRetryMaxAttempts); raw != "" {
+		if n, err := strconv.Atoi(raw); err != nil || n <= 0 {
+			errs = append(errs, fmt.Errorf("%s: %q is not a positive integer", EnvRetryMaxAttempts, raw))
+		} else {
+			cfg.Retry.MaxAttempts = n
+		}
+	}
+	if raw := os.Getenv(EnvRetryMaxElapsed); raw != "" {
+		if d, err := time.ParseDuration(raw); err != nil || d <= 0 {
+			errs = append(errs, fmt.Errorf("%s: %q is not a positive duration", EnvRetryMaxElapsed, raw))
+		} else {
+			cfg.Retry.MaxElapsed = d
+		}
+	}
+	if raw := os.Getenv(EnvRetryJitter); raw != "" {
+		cfg.Retry.Jitter = raw
+	}
+	if raw := os.Getenv(EnvRetryStatuses); raw != "" {
+		for _, field := range strings.Split(raw, ",") {
+			status, err := strconv.Atoi(strings.TrimSpace(field))
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %q is not a status code", EnvRetryStatuses, field))
+				continue
+			}
+			cfg.Retry.RetryableStatuses = append(cfg.Retry.RetryableStatuses, status)
+		}
+	}
+	if err := cfg.Retry.validate(); err != nil {
+		errs = append(errs, fmt.Errorf("retry: %w", err))
+	}
+
+	if len(errs) > 0 {
+		return ClientConfig{}, fmt.Errorf("confluence config: %w", errors.Join(errs...))
+	}
+	return cfg, nil
+}
+
+// Credentials returns the credentials of the config, for use as the default
+// credentials of activities.
+func (cfg ClientConfig) Credentials() Credentials {
+	return Credentials{
+		BaseURL:  cfg.BaseURL,
+		Email:    cfg.Email,
+		APIToken: cfg.APIToken,
+	}
+}
+
+// Validate reports whether the config can be used to reach a site: the base
+// URL must be usable and the credentials set.
+func (cfg ClientConfig) Validate() error {
+	var errs []error
+	if _, err := NormalizeBaseURL(cfg.BaseURL); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.Email == "" {
+		errs = append(errs, errors.New("email is required"))
+	}
+	if cfg.APIToken == "" {
+		errs = append(errs, errors.New("API token is required"))
+	}
+	if err := cfg.Retry.validate(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// NormalizeBaseURL returns the canonical form of a site URL: with a scheme,
+// defaulting to https, a lowercase host, and without trailing slashes or a
+// trailing /wiki, which the client adds to every endpoint. URLs without a
+// host, with a scheme other than http or https, or with a query, fragment,
+// or user info are rejected.
+func NormalizeBaseURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", errors.New("base URL is empty")
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("base URL %q: %w", raw, err)
+	}
+	switch {
+	case u.Scheme != "http" && u.Scheme != "https":
+		return "", fmt.Errorf("base URL %q: scheme must be http or https", raw)
+	case u.Host == "":
+		return "", fmt.Errorf("base URL %q: host is missing", raw)
+	case u.RawQuery != "" || u.Fragment != "":
+		return "", fmt.Errorf("base URL %q: query and fragment are not allowed", raw)
+	case u.User != nil:
+		return "", fmt.Errorf("base URL %q: credentials belong in Email and APIToken", u.Redacted())
+	}
+
+	path := strings.TrimRight(u.Path, "/")
+	path = strings.TrimSuffix(path, "/wiki")
+	return u.Scheme + "://" + strings.ToLower(u.Host) + strings.TrimRight(path, "/"), nil
+}
@@ -0,0 +1,457 @@
+package confluencetest
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	confluence "github.com/resolute-sh/resolute-confluence"
+	"github.com/resolute-sh/resolute-confluence/cql"
+)
+
+// query is a parsed CQL query.
+type query struct {
+	match func(*content) bool
+	order []orderField
+}
+
+// orderField is a sort field of the "order by" clause of a query.
+type orderField struct {
+	field string
+	desc  bool
+}
+
+// parseCQL parses the subset of CQL the fake server evaluates: comparisons
+// of the fields below joined with and, or, and not, and an "order by"
+// clause. Other fields are rejected, as the real API rejects unknown ones.
+func parseCQL(s string) (*query, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &cqlParser{tokens: tokens}
+
+	q := &query{match: func(*content) bool { return true }}
+	if !p.keyword("order") {
+		if q.match, err = p.or(); err != nil {
+			return nil, err
+		}
+	}
+	if p.keyword("order") {
+		p.next()
+		if !p.keyword("by") {
+			return nil, fmt.Errorf("expected by after order")
+		}
+		p.next()
+		for {
+			field := p.next()
+			if field.quoted || field.text == "" {
+				return nil, fmt.Errorf("expected a sort field")
+			}
+			order := orderField{field: strings.ToLower(field.text)}
+			if p.keyword(cql.Ascending) || p.keyword(cql.Descending) {
+				order.desc = strings.EqualFold(p.next().text, cql.Descending)
+			}
+			q.order = append(q.order, order)
+			if !p.symbol(",") {
+				break
+			}
+			p.next()
+		}
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("unexpected %q", p.peek().text)
+	}
+	return q, nil
+}
+
+// token is a lexical token of a CQL query.
+type token struct {
+	text   string
+	quoted bool
+	symbol bool
+}
+
+// tokenize splits a CQL query into tokens.
+func tokenize(s string) ([]token, error) {
+	var tokens []token
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"' || r == '\'':
+			var b strings.Builder
+			i++
+			for ; i < len(runes) && runes[i] != r; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				b.WriteRune(runes[i])
+			}
+			if i == len(runes) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			i++
+			tokens = append(tokens, token{text: b.String(), quoted: true})
+		case strings.ContainsRune("(),", r):
+			tokens = append(tokens, token{text: string(r), symbol: true})
+			i++
+		case strings.ContainsRune("=!~<>", r):
+			op := string(r)
+			if i+1 < len(runes) && strings.ContainsRune("=~", runes[i+1]) && r != '=' && r != '~' {
+				op += string(runes[i+1])
+			}
+			tokens = append(tokens, token{text: op, symbol: true})
+			i += len(op)
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune("(),=!~<>\"'", runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{text: string(runes[start:i])})
+		}
+	}
+	return tokens, nil
+}
+
+// cqlParser is a recursive descent parser over CQL tokens.
+type cqlParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *cqlParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *cqlParser) peek() token {
+	if p.done() {
+		return token{}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *cqlParser) next() token {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+// keyword reports whether the next token is the unquoted word w.
+func (p *cqlParser) keyword(w string) bool {
+	t := p.peek()
+	return !t.quoted && !t.symbol && strings.EqualFold(t.text, w)
+}
+
+// symbol reports whether the next token is the symbol s.
+func (p *cqlParser) symbol(s string) bool {
+	t := p.peek()
+	return t.symbol && t.text == s
+}
+
+func (p *cqlParser) or() (func(*content) bool, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		p.next()
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(c *content) bool { return l(c) || right(c) }
+	}
+	return left, nil
+}
+
+func (p *cqlParser) and() (func(*content) bool, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		p.next()
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(c *content) bool { return l(c) && right(c) }
+	}
+	return left, nil
+}
+
+func (p *cqlParser) unary() (func(*content) bool, error) {
+	switch {
+	case p.keyword("not"):
+		p.next()
+		inner, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return func(c *content) bool { return !inner(c) }, nil
+	case p.symbol("("):
+		p.next()
+		inner, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.symbol(")") {
+			return nil, fmt.Errorf("missing )")
+		}
+		p.next()
+		return inner, nil
+	default:
+		return p.comparison()
+	}
+}
+
+func (p *cqlParser) comparison() (func(*content) bool, error) {
+	field := p.next()
+	if field.quoted || field.symbol || field.text == "" {
+		return nil, fmt.Errorf("expected a field, got %q", field.text)
+	}
+	name := strings.ToLower(field.text)
+
+	var op string
+	switch {
+	case p.keyword("in"):
+		p.next()
+		op = "in"
+	case p.keyword("not"):
+		p.next()
+		if !p.keyword("in") {
+			return nil, fmt.Errorf("expected in after not")
+		}
+		p.next()
+		op = "not in"
+	case p.peek().symbol:
+		op = p.next().text
+	default:
+		return nil, fmt.Errorf("expected an operator after %s", field.text)
+	}
+
+	var values []string
+	if op == "in" || op == "not in" {
+		if !p.symbol("(") {
+			return nil, fmt.Errorf("expected ( after %s", op)
+		}
+		p.next()
+		for {
+			v := p.next()
+			if v.symbol {
+				return nil, fmt.Errorf("expected a value, got %q", v.text)
+			}
+			values = append(values, v.text)
+			if p.symbol(")") {
+				p.next()
+				break
+			}
+			if !p.symbol(",") {
+				return nil, fmt.Errorf("expected , or )")
+			}
+			p.next()
+		}
+	} else {
+		v := p.next()
+		if v.symbol || (v.text == "" && !v.quoted) {
+			return nil, fmt.Errorf("expected a value after %s %s", field.text, op)
+		}
+		values = []string{v.text}
+	}
+
+	return fieldMatcher(name, op, values)
+}
+
+// fieldMatcher returns the predicate of a comparison of a field.
+func fieldMatcher(field, op string, values []string) (func(*content) bool, error) {
+	switch field {
+	case cql.FieldLastModified, cql.FieldCreated:
+		if len(values) != 1 {
+			return nil, fmt.Errorf("%s takes a single date", field)
+		}
+		at, err := parseDate(values[0])
+		if err != nil {
+			return nil, err
+		}
+		compare, err := dateComparison(op)
+		if err != nil {
+			return nil, err
+		}
+		return func(c *content) bool {
+			t := c.page.Version.ModifiedAt()
+			if field == cql.FieldCreated {
+				t = createdAt(c.page)
+			}
+			return compare(t.Truncate(time.Minute).Compare(at))
+		}, nil
+	case cql.FieldText, strings.ToLower(cql.FieldSiteSearch):
+		if op != "~" && op != "!~" {
+			return nil, fmt.Errorf("%s only supports ~ and !~", field)
+		}
+		return textMatcher(op, values[0], func(c *content) []string {
+			return []string{c.page.Title, c.text()}
+		}), nil
+	case cql.FieldTitle:
+		if op == "~" || op == "!~" {
+			return textMatcher(op, values[0], func(c *content) []string {
+				return []string{c.page.Title}
+			}), nil
+		}
+	}
+
+	get, ok := stringFields[field]
+	if !ok {
+		return nil, fmt.Errorf("unsupported field %s", field)
+	}
+	matchesAny := func(c *content) bool {
+		for _, have := range get(c) {
+			for _, want := range values {
+				if strings.EqualFold(have, want) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	switch op {
+	case "=", "in":
+		return matchesAny, nil
+	case "!=", "not in":
+		return func(c *content) bool { return !matchesAny(c) }, nil
+	default:
+		return nil, fmt.Errorf("%s does not support %s", field, op)
+	}
+}
+
+// stringFields returns the values of the fields compared with =, !=, in,
+// and not in.
+var stringFields = map[string]func(*content) []string{
+	cql.FieldSpace: func(c *content) []string { return []string{c.page.Space.Key} },
+	cql.FieldType:  func(c *content) []string { return []string{c.page.Type} },
+	cql.FieldLabel: func(c *content) []string { return c.page.LabelNames() },
+	cql.FieldTitle: func(c *content) []string { return []string{c.page.Title} },
+	cql.FieldID:    func(c *content) []string { return []string{c.page.ID} },
+	cql.FieldAncestor: func(c *content) []string {
+		ids := make([]string, 0, len(c.page.Ancestors))
+		for _, ancestor := range c.page.Ancestors {
+			ids = append(ids, ancestor.ID)
+		}
+		return ids
+	},
+	cql.FieldParent: func(c *content) []string {
+		if n := len(c.page.Ancestors); n > 0 {
+			return []string{c.page.Ancestors[n-1].ID}
+		}
+		return nil
+	},
+	cql.FieldCreator: func(c *content) []string {
+		if c.page.History == nil {
+			return nil
+		}
+		return []string{c.page.History.CreatedBy.AccountID}
+	},
+	cql.FieldContributor: func(c *content) []string {
+		ids := []string{c.page.Version.By.AccountID}
+		if c.page.History != nil {
+			ids = append(ids, c.page.History.CreatedBy.AccountID)
+		}
+		return ids
+	},
+}
+
+// textMatcher returns a predicate matching content whose texts contain
+// every word of value, ignoring case.
+func textMatcher(op, value string, texts func(*content) []string) func(*content) bool {
+	words := strings.Fields(strings.ToLower(value))
+	contains := func(c *content) bool {
+		haystack := strings.ToLower(strings.Join(texts(c), "\n"))
+		for _, word := range words {
+			if !strings.Contains(haystack, word) {
+				return false
+			}
+		}
+		return true
+	}
+	if op == "!~" {
+		return func(c *content) bool { return !contains(c) }
+	}
+	return contains
+}
+
+// dateComparison returns the test of a date comparison operator on the
+// result of time.Time.Compare.
+func dateComparison(op string) (func(int) bool, error) {
+	switch op {
+	case "=":
+		return func(n int) bool { return n == 0 }, nil
+	case "!=":
+		return func(n int) bool { return n != 0 }, nil
+	case ">":
+		return func(n int) bool { return n > 0 }, nil
+	case ">=":
+		return func(n int) bool { return n >= 0 }, nil
+	case "<":
+		return func(n int) bool { return n < 0 }, nil
+	case "<=":
+		return func(n int) bool { return n <= 0 }, nil
+	default:
+		return nil, fmt.Errorf("dates do not support %s", op)
+	}
+}
+
+// parseDate parses a CQL date, in UTC.
+func parseDate(s string) (time.Time, error) {
+	for _, layout := range []string{cql.DateFormat, "2006-01-02", "2006/01/02 15:04", "2006/01/02"} {
+		if t, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
+
+// createdAt returns the creation time of a page, or its modification time
+// when its history is unknown.
+func createdAt(page confluence.Page) time.Time {
+	if page.History != nil {
+		if t := page.History.CreatedAt(); !t.IsZero() {
+			return t
+		}
+	}
+	return page.Version.ModifiedAt()
+}
+
+// sortContent sorts content by the order of a query.
+func sortContent(items []*content, order []orderField) {
+	if len(order) == 0 {
+		return
+	}
+	slices.SortStableFunc(items, func(a, b *content) int {
+		for _, o := range order {
+			var n int
+			switch o.field {
+			case cql.FieldLastModified:
+				n = a.page.Version.ModifiedAt().Compare(b.page.Version.ModifiedAt())
+			case cql.FieldCreated:
+				n = createdAt(a.page).Compare(createdAt(b.page))
+			case cql.FieldTitle:
+				n = strings.Compare(strings.ToLower(a.page.Title), strings.ToLower(b.page.Title))
+			default:
+				n = a.seq - b.seq
+			}
+			if o.desc {
+				n = -n
+			}
+			if n != 0 {
+				return n
+			}
+		}
+		return 0
+	})
+}
@@ -0,0 +1,114 @@
+package confluencetest
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	confluence "github.com/resolute-sh/resolute-confluence"
+)
+
+// UpdateGoldenEnv is the environment variable that makes CheckGolden
+// rewrite the golden files with the current output instead of comparing
+// them, after an intended change of the converter:
+//
+//	CONFLUENCE_UPDATE_GOLDEN=1 go test ./...
+const UpdateGoldenEnv = "CONFLUENCE_UPDATE_GOLDEN"
+
+// corpus holds real-world storage-format samples and their golden output:
+// macros, tables, layouts, emoticons, CDATA, links, and inline comments.
+//
+//go:embed testdata/convert
+var corpus embed.FS
+
+// corpusDir is the directory of the samples in corpus.
+const corpusDir = "testdata/convert"
+
+// CheckCorpus runs the storage-format samples bundled with this package
+// through the converter and reports a test error for each whose output
+// differs from its golden file, guarding against extraction regressions.
+func CheckCorpus(t testing.TB) {
+	t.Helper()
+	sub, err := fs.Sub(corpus, corpusDir)
+	if err != nil {
+		t.Fatalf("open corpus: %v", err)
+	}
+	checkGolden(t, sub, nil)
+}
+
+// CheckGolden runs every storage-format sample of dir, the files named
+// *.xml, through the converter and reports a test error for each whose
+// output differs from the golden file of the same name with the .golden
+// extension. Samples are converted with inline comment refs collected,
+// which the output lists after the text. With UpdateGoldenEnv set, golden
+// files are written instead.
+func CheckGolden(t testing.TB, dir string) {
+	t.Helper()
+	var update func(name, output string) error
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		update = func(name, output string) error {
+			return os.WriteFile(filepath.Join(dir, name), []byte(output), 0o644)
+		}
+	}
+	checkGolden(t, os.DirFS(dir), update)
+}
+
+// checkGolden compares the output of the samples of fsys with their golden
+// files, or writes the golden files with update when it is set.
+func checkGolden(t testing.TB, fsys fs.FS, update func(name, output string) error) {
+	t.Helper()
+	samples, err := fs.Glob(fsys, "*.xml")
+	if err != nil {
+		t.Fatalf("list samples: %v", err)
+	}
+	if len(samples) == 0 {
+		t.Fatalf("no *.xml samples found")
+	}
+
+	for _, sample := range samples {
+		storage, err := fs.ReadFile(fsys, sample)
+		if err != nil {
+			t.Errorf("%s: %v", sample, err)
+			continue
+		}
+		got := ConvertGolden(string(storage))
+
+		golden := strings.TrimSuffix(sample, path.Ext(sample)) + ".golden"
+		if update != nil {
+			if err := update(golden, got); err != nil {
+				t.Errorf("%s: %v", golden, err)
+			}
+			continue
+		}
+
+		want, err := fs.ReadFile(fsys, golden)
+		if err != nil {
+			t.Errorf("%s: %v (set %s=1 to create it)", sample, err, UpdateGoldenEnv)
+			continue
+		}
+		if got != string(want) {
+			t.Errorf("%s: output differs from %s\n--- want ---\n%s--- got ---\n%s", sample, golden, want, got)
+		}
+	}
+}
+
+// ConvertGolden converts a storage-format body as CheckGolden does and
+// returns the output compared with golden files: the extracted text, then
+// the inline comment refs if any. Use it to inspect how a custom page is
+// extracted.
+func ConvertGolden(storage string) string {
+	result := confluence.ConvertStorage(storage, confluence.ConvertOptions{CollectCommentRefs: true})
+
+	var b strings.Builder
+	b.WriteString(result.Text)
+	b.WriteString("\n")
+	if len(result.CommentRefs) > 0 {
+		fmt.Fprintf(&b, "\n-- comment refs --\n%s\n", strings.Join(result.CommentRefs, "\n"))
+	}
+	return b.String()
+}
@@ -0,0 +1,1204 @@
+// Package confluencetest provides an in-memory fake of the Confluence Cloud
+// REST API, so workflows built on the confluence package can be integration
+// tested without reaching Atlassian.
+//
+//	srv := confluencetest.NewServer()
+//	defer srv.Close()
+//
+//	srv.AddSpace(confluence.Space{Key: "ENG", Name: "Engineering"})
+//	srv.AddPage(confluencetest.NewPage("ENG", "Runbook", "<p>Restart the service.</p>"))
+//	confluence.SetDefaultCredentials(srv.Credentials())
+//
+// The server serves spaces, pages, blog posts, attachments, the read
+// restrictions set in Page.Restrictions, the space permissions set with
+// SetSpacePermissions, and CQL searches with the
+// pagination of the real API. It ignores expand parameters and returns
+// every property it knows, and evaluates the CQL fields of the cql
+// package: space, type, label, title, text, siteSearch, id, ancestor,
+// parent, creator, contributor, lastmodified, and created.
+//
+// It also serves the writes of the confluence package: creating, updating,
+// trashing, and purging pages, adding and removing labels, and setting
+// content properties. Updates must carry the next version number, as in the
+// API, and EditConcurrently makes them race with another edit. Page returns
+// a page as written, whatever its status.
+//
+// CheckCorpus and CheckGolden guard the extraction of storage-format
+// bodies with golden files.
+package confluencetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	confluence "github.com/resolute-sh/resolute-confluence"
+)
+
+// Credentials the server accepts unless the Server fields are changed.
+const (
+	DefaultEmail    = "test@example.com"
+	DefaultAPIToken = "test-token"
+)
+
+// DefaultMaxPageSize is the largest number of results the server returns
+// per request unless SetMaxPageSize changes it.
+const DefaultMaxPageSize = 250
+
+// excerptLength is the number of characters of search excerpts.
+const excerptLength = 150
+
+// Server is a fake Confluence site served over HTTP.
+type Server struct {
+	// URL is the base URL of the site, for the BaseURL of activity inputs.
+	URL string
+
+	// Email and APIToken are the credentials the server accepts. Requests
+	// with other credentials are answered 401.
+	Email    string
+	APIToken string
+
+	// User is the user the credentials authenticate as. It is the author
+	// of the pages added without one.
+	User confluence.User
+
+	// Now returns the current time, used as the modification time of the
+	// pages added without one. Defaults to time.Now.
+	Now func() time.Time
+
+	srv *httptest.Server
+
+	mu          sync.Mutex
+	spaces      []*confluence.Space
+	permissions map[string][]confluence.SpacePermission
+	contents    []*content
+	byID        map[string]*content
+	attachments map[string][]*attachment
+	seq         int
+	maxPageSize int
+	throttled   int
+	retryAfter  time.Duration
+	requests    int
+	failures    map[string]int
+}
+
+// content is a page or blog post held by the server.
+type content struct {
+	page       confluence.Page
+	seq        int
+	restricted bool
+
+	// races is the number of upcoming updates that race with another edit.
+	races int
+}
+
+// text returns the plain text of the body of the content.
+func (c *content) text() string {
+	return confluence.ConvertStorage(c.page.Body.Storage.Value, confluence.ConvertOptions{}).Text
+}
+
+// attachment is a file attached to a page.
+type attachment struct {
+	meta confluence.Attachment
+	data []byte
+}
+
+// NewServer starts a server holding no content. Close it when done.
+func NewServer() *Server {
+	s := &Server{
+		Email:    DefaultEmail,
+		APIToken: DefaultAPIToken,
+		User: confluence.User{
+			AccountID:   "test-account-id",
+			DisplayName: "Test User",
+			Email:       DefaultEmail,
+		},
+		Now:         time.Now,
+		byID:        make(map[string]*content),
+		attachments: make(map[string][]*attachment),
+		maxPageSize: DefaultMaxPageSize,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /wiki/rest/api/user/current", s.handleCurrentUser)
+	mux.HandleFunc("GET /wiki/rest/api/space", s.handleListSpaces)
+	mux.HandleFunc("GET /wiki/rest/api/space/{key}", s.handleGetSpace)
+	mux.HandleFunc("GET /wiki/rest/api/content", s.handleListContent)
+	mux.HandleFunc("GET /wiki/rest/api/content/search", s.handleSearchContent)
+	mux.HandleFunc("GET /wiki/rest/api/content/{id}", s.handleGetContent)
+	mux.HandleFunc("GET /wiki/rest/api/content/{id}/child/page", s.handleChildPages)
+	mux.HandleFunc("GET /wiki/rest/api/content/{id}/child/attachment", s.handleListAttachments)
+	mux.HandleFunc("GET /wiki/rest/api/content/{id}/restriction/byOperation/read", s.handleReadRestriction)
+	mux.HandleFunc("POST /wiki/rest/api/content", s.handleCreateContent)
+	mux.HandleFunc("PUT /wiki/rest/api/content/{id}", s.handleUpdateContent)
+	mux.HandleFunc("DELETE /wiki/rest/api/content/{id}", s.handleDeleteContent)
+	mux.HandleFunc("POST /wiki/rest/api/content/{id}/label", s.handleAddLabels)
+	mux.HandleFunc("DELETE /wiki/rest/api/content/{id}/label", s.handleRemoveLabel)
+	mux.HandleFunc("GET /wiki/rest/api/content/{id}/property/{key}", s.handleGetProperty)
+	mux.HandleFunc("POST /wiki/rest/api/content/{id}/property", s.handleCreateProperty)
+	mux.HandleFunc("PUT /wiki/rest/api/content/{id}/property/{key}", s.handleUpdateProperty)
+	mux.HandleFunc("GET /wiki/rest/api/search", s.handleSearch)
+	mux.HandleFunc("GET /wiki/download/attachments/{id}/{name}", s.handleDownload)
+	mux.HandleFunc("GET /download/attachments/{id}/{name}", s.handleDownload)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotImplemented, fmt.Sprintf("%s %s is not served by the fake", r.Method, r.URL.Path))
+	})
+
+	s.srv = httptest.NewServer(s.intercept(mux))
+	s.URL = s.srv.URL
+	return s
+}
+
+// Close shuts the server down.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Credentials returns the credentials of the server, for
+// confluence.SetDefaultCredentials or confluence.SetSite.
+func (s *Server) Credentials() confluence.Credentials {
+	return confluence.Credentials{
+		BaseURL:  s.URL,
+		Email:    s.Email,
+		APIToken: s.APIToken,
+	}
+}
+
+// SetMaxPageSize sets the largest number of results the server returns per
+// request, whatever the limit requested, to exercise pagination with a few
+// pages.
+func (s *Server) SetMaxPageSize(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxPageSize = max(n, 1)
+}
+
+// Throttle answers the next n requests with 429 Too Many Requests and a
+// Retry-After header of retryAfter, as the API does when a site exceeds
+// its rate limit.
+func (s *Server) Throttle(n int, retryAfter time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.throttled = n
+	s.retryAfter = retryAfter
+}
+
+// Fail answers every request for the content with the given IDs, including
+// its children, attachments, and restrictions, with status, such as 500
+// Internal Server Error for a page that breaks on the server. A status of
+// zero serves the content again.
+func (s *Server) Fail(status int, ids ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures == nil {
+		s.failures = make(map[string]int)
+	}
+	for _, id := range ids {
+		if status == 0 {
+			delete(s.failures, id)
+		} else {
+			s.failures[id] = status
+		}
+	}
+}
+
+// Requests returns the number of requests the server received, throttled
+// ones included.
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// AddSpace adds a space, or replaces the space with the same key, and
+// returns it with its unset fields filled in: the name defaults to the key,
+// the type to "global", and the status to "current". Space categories are
+// labels with the "team" prefix in Metadata.
+func (s *Server) AddSpace(space confluence.Space) confluence.Space {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return *s.addSpace(space)
+}
+
+func (s *Server) addSpace(space confluence.Space) *confluence.Space {
+	if space.Name == "" {
+		space.Name = space.Key
+	}
+	if space.Type == "" {
+		space.Type = "global"
+	}
+	if space.Status == "" {
+		space.Status = "current"
+	}
+	for i, existing := range s.spaces {
+		if existing.Key == space.Key {
+			space.ID = existing.ID
+			s.spaces[i] = &space
+			return &space
+		}
+	}
+	s.seq++
+	space.ID = s.seq
+	s.spaces = append(s.spaces, &space)
+	return &space
+}
+
+// SetSpacePermissions sets the permissions granted on a space, served when
+// the space is requested. The space is added when missing.
+func (s *Server) SetSpacePermissions(key string, permissions ...confluence.SpacePermission) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.space(key) == nil {
+		s.addSpace(confluence.Space{Key: key})
+	}
+	if s.permissions == nil {
+		s.permissions = make(map[string][]confluence.SpacePermission)
+	}
+	s.permissions[key] = slices.Clone(permissions)
+}
+
+// space returns the space with a key, or nil.
+func (s *Server) space(key string) *confluence.Space {
+	for _, space := range s.spaces {
+		if space.Key == key {
+			return space
+		}
+	}
+	return nil
+}
+
+// NewPage returns a page of a space with a storage-format body, for
+// AddPage. Set its Ancestors to the parent page, such as
+// []confluence.Page{{ID: parent.ID}}, to add it below another page.
+func NewPage(spaceKey, title, storage string) confluence.Page {
+	return confluence.Page{
+		Type:  "page",
+		Title: title,
+		Space: confluence.Space{Key: spaceKey},
+		Body: confluence.Body{
+			Storage: confluence.StorageBody{Value: storage, Representation: "storage"},
+		},
+	}
+}
+
+// AddPage adds a page or blog post and returns it as the server serves it.
+// Unset fields are filled in: an ID, the type "page", the status "current",
+// version 1 by User modified at Now, the creation history, and the web
+// link. The space of the page is added when missing. The last of the
+// Ancestors is the parent page, which must have been added before; the
+// full ancestor chain is derived from it.
+func (s *Server) AddPage(page confluence.Page) confluence.Page {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addPage(page)
+}
+
+func (s *Server) addPage(page confluence.Page) confluence.Page {
+	if page.ID == "" {
+		s.seq++
+		page.ID = strconv.Itoa(s.seq)
+	}
+	if page.Type == "" {
+		page.Type = "page"
+	}
+	if page.Status == "" {
+		page.Status = "current"
+	}
+
+	space := s.space(page.Space.Key)
+	if space == nil {
+		space = s.addSpace(confluence.Space{Key: page.Space.Key})
+	}
+	page.Space = confluence.Space{Key: space.Key, Name: space.Name, Type: space.Type, Status: space.Status}
+
+	if page.Version.Number == 0 {
+		page.Version.Number = 1
+	}
+	if page.Version.ModifiedAt().IsZero() {
+		page.Version.When = s.Now().UTC().Format(time.RFC3339)
+	}
+	if page.Version.By.AccountID == "" {
+		page.Version.By = s.User
+	}
+	if page.History == nil {
+		page.History = &confluence.History{
+			CreatedBy:   page.Version.By,
+			CreatedDate: page.Version.ModifiedAt().Format(time.RFC3339),
+		}
+	}
+
+	if n := len(page.Ancestors); n > 0 {
+		parentID := page.Ancestors[n-1].ID
+		page.Ancestors = nil
+		if parent, ok := s.byID[parentID]; ok {
+			page.Ancestors = append(slices.Clone(parent.page.Ancestors), ancestor(parent.page))
+		}
+	}
+
+	page.Links.WebUI = webUI(page)
+	page.Links.Self = fmt.Sprintf("%s/wiki/rest/api/content/%s", s.URL, page.ID)
+
+	if existing, ok := s.byID[page.ID]; ok {
+		existing.page = page
+		return page
+	}
+	s.seq++
+	c := &content{page: page, seq: s.seq}
+	s.contents = append(s.contents, c)
+	s.byID[page.ID] = c
+	return page
+}
+
+// webUI returns the web link of a page, which holds its title.
+func webUI(page confluence.Page) string {
+	route := "pages"
+	if page.Type == "blogpost" {
+		route = "blog"
+	}
+	return fmt.Sprintf("/spaces/%s/%s/%s/%s", url.PathEscape(page.Space.Key), route, page.ID, url.PathEscape(strings.ReplaceAll(page.Title, " ", "+")))
+}
+
+// ancestor returns the summary of a page listed among the ancestors of its
+// descendants.
+func ancestor(page confluence.Page) confluence.Page {
+	return confluence.Page{ID: page.ID, Type: page.Type, Status: page.Status, Title: page.Title, Links: page.Links}
+}
+
+// UpdatePage replaces the storage-format body of a page, bumping its
+// version and modification time as an edit by User would. It reports
+// false when there is no such page.
+func (s *Server) UpdatePage(id, storage string) (confluence.Page, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.byID[id]
+	if !ok {
+		return confluence.Page{}, false
+	}
+	c.page.Body.Storage.Value = storage
+	c.page.Version = confluence.Version{
+		Number: c.page.Version.Number + 1,
+		When:   s.Now().UTC().Format(time.RFC3339),
+		By:     s.User,
+	}
+	return c.page, true
+}
+
+// Page returns a page or blog post as the server holds it, trashed ones
+// included, to check the writes made to it. It reports false when there is
+// no such page.
+func (s *Server) Page(id string) (confluence.Page, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.byID[id]
+	if !ok {
+		return confluence.Page{}, false
+	}
+	return c.page, true
+}
+
+// EditConcurrently makes the next n updates of a page race with an edit by
+// User that lands first: the page is moved to its next version before each
+// of them is applied, so they are answered 409 Conflict. It reports false
+// when there is no such page.
+func (s *Server) EditConcurrently(id string, n int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.byID[id]
+	if !ok {
+		return false
+	}
+	c.races = n
+	return true
+}
+
+// Restrict makes pages unreadable with the server credentials: they are
+// answered 403 when requested by ID and left out of listings and searches,
+// as restricted pages are for a service account without access.
+func (s *Server) Restrict(ids ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		if c, ok := s.byID[id]; ok {
+			c.restricted = true
+		}
+	}
+}
+
+// AddAttachment attaches a file to a page and returns the attachment as the
+// server lists it. The media type defaults to the one of the file name
+// extension. It reports false when there is no such page.
+func (s *Server) AddAttachment(pageID, name string, data []byte) (confluence.Attachment, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.byID[pageID]; !ok {
+		return confluence.Attachment{}, false
+	}
+
+	mediaType := mime.TypeByExtension(path.Ext(name))
+	if mediaType == "" {
+		mediaType = http.DetectContentType(data)
+	}
+	s.seq++
+	meta := confluence.Attachment{
+		ID:    fmt.Sprintf("att%d", s.seq),
+		Title: name,
+		Version: confluence.Version{
+			Number: 1,
+			When:   s.Now().UTC().Format(time.RFC3339),
+			By:     s.User,
+		},
+		Metadata:   confluence.AttachmentMetadata{MediaType: mediaType},
+		Extensions: confluence.AttachmentExtensions{MediaType: mediaType, FileSize: int64(len(data))},
+		Links: confluence.AttachmentLinks{
+			Download: fmt.Sprintf("/download/attachments/%s/%s?version=1", pageID, url.PathEscape(name)),
+		},
+	}
+	s.attachments[pageID] = append(s.attachments[pageID], &attachment{meta: meta, data: slices.Clone(data)})
+	return meta, true
+}
+
+// intercept counts requests, answers throttled ones, and checks
+// credentials before passing requests to next.
+func (s *Server) intercept(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests++
+		throttled := s.throttled > 0
+		if throttled {
+			s.throttled--
+		}
+		retryAfter := s.retryAfter
+		email, token := s.Email, s.APIToken
+		failure := s.failures[contentID(r.URL.Path)]
+		s.mu.Unlock()
+
+		if throttled {
+			seconds := int((retryAfter + time.Second - 1) / time.Second)
+			w.Header().Set("Retry-After", strconv.Itoa(seconds))
+			writeError(w, http.StatusTooManyRequests, "Rate limit exceeded")
+			return
+		}
+		if user, pass, ok := r.BasicAuth(); !ok || user != email || pass != token {
+			writeError(w, http.StatusUnauthorized, "Client must be authenticated to access this resource.")
+			return
+		}
+		if failure != 0 {
+			writeError(w, failure, http.StatusText(failure))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// contentID returns the ID of the content a request path is about, or an
+// empty string for other paths.
+func contentID(path string) string {
+	rest, ok := strings.CutPrefix(path, "/wiki/rest/api/content/")
+	if !ok {
+		return ""
+	}
+	id, _, _ := strings.Cut(rest, "/")
+	return id
+}
+
+func (s *Server) handleCurrentUser(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, s.User)
+}
+
+func (s *Server) handleListSpaces(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q := r.URL.Query()
+	var matched []confluence.Space
+	for _, space := range s.spaces {
+		if t := q.Get("type"); t != "" && space.Type != t {
+			continue
+		}
+		if statuses := q["status"]; len(statuses) > 0 && !slices.Contains(statuses, space.Status) {
+			continue
+		}
+		if labels := q["label"]; len(labels) > 0 && !hasAny(space.LabelNames(), labels) {
+			continue
+		}
+		matched = append(matched, *space)
+	}
+
+	start, end, limit, err := s.window(r, len(matched))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, confluence.SpaceList{
+		Results: nonNil(matched[start:end]),
+		Start:   start,
+		Limit:   limit,
+		Size:    end - start,
+		Links:   nextLink(r, end, len(matched)),
+	})
+}
+
+func (s *Server) handleGetSpace(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	space := s.space(r.PathValue("key"))
+	if space == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("No space with key : %s", r.PathValue("key")))
+		return
+	}
+	writeJSON(w, struct {
+		*confluence.Space
+		Permissions []confluence.SpacePermission `json:"permissions,omitempty"`
+	}{space, s.permissions[space.Key]})
+}
+
+func (s *Server) handleListContent(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q := r.URL.Query()
+	contentType := q.Get("type")
+	if contentType == "" {
+		contentType = "page"
+	}
+	statuses := q["status"]
+	if len(statuses) == 0 {
+		statuses = []string{"current"}
+	}
+
+	var matched []*content
+	for _, c := range s.contents {
+		switch {
+		case c.restricted:
+		case c.page.Type != contentType:
+		case !slices.Contains(statuses, c.page.Status):
+		case q.Get("spaceKey") != "" && c.page.Space.Key != q.Get("spaceKey"):
+		case q.Get("title") != "" && c.page.Title != q.Get("title"):
+		default:
+			matched = append(matched, c)
+		}
+	}
+	s.writePageList(w, r, matched)
+}
+
+func (s *Server) handleGetContent(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.byID[r.PathValue("id")]
+	statuses := r.URL.Query()["status"]
+	if len(statuses) == 0 {
+		statuses = []string{"current", "archived"}
+	}
+	if !ok || !slices.Contains(statuses, c.page.Status) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("No content found with id: %s", r.PathValue("id")))
+		return
+	}
+	if c.restricted {
+		writeError(w, http.StatusForbidden, "User not permitted to view content")
+		return
+	}
+	writeJSON(w, c.page)
+}
+
+func (s *Server) handleChildPages(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := r.PathValue("id")
+	if _, ok := s.byID[id]; !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("No content found with id: %s", id))
+		return
+	}
+
+	var children []*content
+	for _, c := range s.contents {
+		n := len(c.page.Ancestors)
+		if !c.restricted && c.page.Status == "current" && n > 0 && c.page.Ancestors[n-1].ID == id {
+			children = append(children, c)
+		}
+	}
+	s.writePageList(w, r, children)
+}
+
+func (s *Server) handleReadRestriction(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.byID[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("No content found with id: %s", r.PathValue("id")))
+		return
+	}
+	if c.restricted {
+		writeError(w, http.StatusForbidden, "User not permitted to view content")
+		return
+	}
+	var restriction confluence.ContentRestriction
+	if c.page.Restrictions != nil {
+		restriction = c.page.Restrictions.Read
+	}
+	restriction.Operation = "read"
+	writeJSON(w, restriction)
+}
+
+func (s *Server) handleListAttachments(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := r.PathValue("id")
+	if c, ok := s.byID[id]; !ok || c.restricted {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("No content found with id: %s", id))
+		return
+	}
+
+	attachments := s.attachments[id]
+	start, end, limit, err := s.window(r, len(attachments))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	results := make([]confluence.Attachment, 0, end-start)
+	for _, a := range attachments[start:end] {
+		results = append(results, a.meta)
+	}
+	writeJSON(w, confluence.AttachmentList{
+		Results: results,
+		Start:   start,
+		Limit:   limit,
+		Size:    len(results),
+		Links:   nextLink(r, end, len(attachments)),
+	})
+}
+
+func (s *Server) handleDownload(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, name := r.PathValue("id"), r.PathValue("name")
+	if c, ok := s.byID[id]; ok && !c.restricted {
+		for _, a := range s.attachments[id] {
+			if a.meta.Title == name {
+				w.Header().Set("Content-Type", a.meta.Extensions.MediaType)
+				w.Header().Set("Content-Length", strconv.Itoa(len(a.data)))
+				w.Write(a.data)
+				return
+			}
+		}
+	}
+	writeError(w, http.StatusNotFound, "Attachment not found")
+}
+
+// contentWrite is the request body of page creations and updates.
+type contentWrite struct {
+	Type      string            `json:"type"`
+	Title     string            `json:"title"`
+	Space     confluence.Space  `json:"space"`
+	Ancestors []confluence.Page `json:"ancestors"`
+	Body      confluence.Body   `json:"body"`
+	Version   struct {
+		Number  int    `json:"number"`
+		Message string `json:"message"`
+	} `json:"version"`
+}
+
+func (s *Server) handleCreateContent(w http.ResponseWriter, r *http.Request) {
+	var req contentWrite
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if req.Title == "" {
+		writeError(w, http.StatusBadRequest, "Title is required")
+		return
+	}
+	if s.space(req.Space.Key) == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("No space with key : %s", req.Space.Key))
+		return
+	}
+	if s.titled(req.Space.Key, req.Title) != nil {
+		writeError(w, http.StatusBadRequest, "A page with this title already exists: A page already exists with the same TITLE in this space")
+		return
+	}
+	if n := len(req.Ancestors); n > 0 {
+		if _, ok := s.byID[req.Ancestors[n-1].ID]; !ok {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("No content found with id: %s", req.Ancestors[n-1].ID))
+			return
+		}
+	}
+
+	page := NewPage(req.Space.Key, req.Title, req.Body.Storage.Value)
+	if req.Type != "" {
+		page.Type = req.Type
+	}
+	page.Ancestors = req.Ancestors
+	writeJSON(w, s.addPage(page))
+}
+
+func (s *Server) handleUpdateContent(w http.ResponseWriter, r *http.Request) {
+	var req contentWrite
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.writable(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	if c.races > 0 {
+		c.races--
+		c.page.Version = s.nextVersion(c.page.Version, "")
+	}
+	if req.Version.Number != c.page.Version.Number+1 {
+		writeError(w, http.StatusConflict, fmt.Sprintf("Version must be incremented on update. Current version is: %d", c.page.Version.Number))
+		return
+	}
+	if req.Title == "" {
+		writeError(w, http.StatusBadRequest, "Title is required")
+		return
+	}
+	if other := s.titled(c.page.Space.Key, req.Title); other != nil && other != c {
+		writeError(w, http.StatusBadRequest, "A page with this title already exists: A page already exists with the same TITLE in this space")
+		return
+	}
+
+	c.page.Title = req.Title
+	c.page.Body.Storage = confluence.StorageBody{Value: req.Body.Storage.Value, Representation: "storage"}
+	c.page.Version = s.nextVersion(c.page.Version, req.Version.Message)
+	c.page.Links.WebUI = webUI(c.page)
+	writeJSON(w, c.page)
+}
+
+// nextVersion returns the version following v, as an edit by User at Now
+// with a version comment.
+func (s *Server) nextVersion(v confluence.Version, message string) confluence.Version {
+	return confluence.Version{
+		Number:  v.Number + 1,
+		When:    s.Now().UTC().Format(time.RFC3339),
+		By:      s.User,
+		Message: message,
+	}
+}
+
+// handleDeleteContent moves current content to the trash, or purges
+// trashed content when the status parameter is "trashed", as the API does.
+func (s *Server) handleDeleteContent(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := r.PathValue("id")
+	c, ok := s.byID[id]
+	if r.URL.Query().Get("status") == "trashed" {
+		if !ok || c.page.Status != "trashed" {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("No trashed content found with id: %s", id))
+			return
+		}
+		delete(s.byID, id)
+		s.contents = slices.DeleteFunc(s.contents, func(other *content) bool { return other == c })
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if c, ok = s.writable(w, id); !ok {
+		return
+	}
+	c.page.Status = "trashed"
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleAddLabels(w http.ResponseWriter, r *http.Request) {
+	var labels []confluence.Label
+	if !decodeBody(w, r, &labels) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.writable(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	metadata := c.metadata()
+	for _, label := range labels {
+		if label.Name == "" {
+			writeError(w, http.StatusBadRequest, "Label name cannot be empty")
+			return
+		}
+		if !slices.Contains(c.page.LabelNames(), label.Name) {
+			metadata.Labels.Results = append(metadata.Labels.Results, confluence.Label{Prefix: "global", Name: label.Name})
+		}
+	}
+	writeJSON(w, metadata.Labels)
+}
+
+func (s *Server) handleRemoveLabel(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.writable(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	name := r.URL.Query().Get("name")
+	metadata := c.metadata()
+	n := len(metadata.Labels.Results)
+	metadata.Labels.Results = slices.DeleteFunc(metadata.Labels.Results, func(label confluence.Label) bool {
+		return label.Name == name
+	})
+	if len(metadata.Labels.Results) == n {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Label %s is not on content %s", name, c.page.ID))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleGetProperty(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.writable(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	property, ok := c.metadata().Properties[r.PathValue("key")]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("No property with key : %s", r.PathValue("key")))
+		return
+	}
+	writeJSON(w, property)
+}
+
+func (s *Server) handleCreateProperty(w http.ResponseWriter, r *http.Request) {
+	var property confluence.ContentProperty
+	if !decodeBody(w, r, &property) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.writable(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	metadata := c.metadata()
+	if _, exists := metadata.Properties[property.Key]; exists {
+		writeError(w, http.StatusConflict, fmt.Sprintf("A property with key %s already exists", property.Key))
+		return
+	}
+	property.Version = &confluence.PropertyVersion{Number: 1}
+	metadata.Properties[property.Key] = property
+	writeJSON(w, property)
+}
+
+func (s *Server) handleUpdateProperty(w http.ResponseWriter, r *http.Request) {
+	var property confluence.ContentProperty
+	if !decodeBody(w, r, &property) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.writable(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	metadata := c.metadata()
+	key := r.PathValue("key")
+	existing, ok := metadata.Properties[key]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("No property with key : %s", key))
+		return
+	}
+	version := 1
+	if existing.Version != nil {
+		version = existing.Version.Number
+	}
+	if property.Version == nil || property.Version.Number != version+1 {
+		writeError(w, http.StatusConflict, fmt.Sprintf("Version must be incremented on update. Current version is: %d", version))
+		return
+	}
+	property.Key = key
+	metadata.Properties[key] = property
+	writeJSON(w, property)
+}
+
+// writable returns the current content with an ID, or answers 404 Not
+// Found or 403 Forbidden and reports false.
+func (s *Server) writable(w http.ResponseWriter, id string) (*content, bool) {
+	c, ok := s.byID[id]
+	if !ok || c.page.Status != "current" {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("No content found with id: %s", id))
+		return nil, false
+	}
+	if c.restricted {
+		writeError(w, http.StatusForbidden, "User not permitted to edit content")
+		return nil, false
+	}
+	return c, true
+}
+
+// titled returns the current page of a space with a title, or nil.
+func (s *Server) titled(spaceKey, title string) *content {
+	for _, c := range s.contents {
+		if c.page.Type == "page" && c.page.Status == "current" && c.page.Space.Key == spaceKey && c.page.Title == title {
+			return c
+		}
+	}
+	return nil
+}
+
+// metadata returns the metadata of the content, adding it when missing.
+func (c *content) metadata() *confluence.ContentMetadata {
+	if c.page.Metadata == nil {
+		c.page.Metadata = &confluence.ContentMetadata{}
+	}
+	if c.page.Metadata.Properties == nil {
+		c.page.Metadata.Properties = make(map[string]confluence.ContentProperty)
+	}
+	return c.page.Metadata
+}
+
+// decodeBody decodes the JSON body of a request into v, or answers 400 Bad
+// Request and reports false. Compressed bodies are answered 415
+// Unsupported Media Type, as by sites that do not accept them.
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	if r.Header.Get("Content-Encoding") != "" {
+		writeError(w, http.StatusUnsupportedMediaType, "Unsupported Content-Encoding")
+		return false
+	}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return false
+	}
+	return true
+}
+
+func (s *Server) handleSearchContent(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	matched, err := s.search(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.writePageList(w, r, matched)
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	matched, err := s.search(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	q := r.URL.Query()
+	start := 0
+	if cursor := q.Get("cursor"); cursor != "" {
+		if start, err = strconv.Atoi(cursor); err != nil || start < 0 {
+			writeError(w, http.StatusBadRequest, "invalid cursor")
+			return
+		}
+	}
+	limit, err := s.limit(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	start = min(start, len(matched))
+	end := min(start+limit, len(matched))
+
+	result := confluence.SearchResult{
+		Results:   make([]confluence.SearchResultItem, 0, end-start),
+		Start:     start,
+		Limit:     limit,
+		Size:      end - start,
+		TotalSize: len(matched),
+	}
+	for _, c := range matched[start:end] {
+		item := confluence.SearchResultItem{
+			Content: c.page,
+			Title:   c.page.Title,
+			URL:     c.page.Links.WebUI,
+		}
+		if q.Get("excerpt") != confluence.ExcerptNone {
+			item.Excerpt = excerpt(c.text())
+		}
+		result.Results = append(result.Results, item)
+	}
+	if end < len(matched) {
+		next := url.Values{}
+		for key, values := range q {
+			next[key] = values
+		}
+		next.Set("cursor", strconv.Itoa(end))
+		result.Links.Next = "/rest/api/search?" + next.Encode()
+	}
+	writeJSON(w, result)
+}
+
+// search returns the readable content matching the CQL query of a request,
+// leaving out the content of archived spaces unless the request includes
+// them.
+func (s *Server) search(r *http.Request) ([]*content, error) {
+	q := r.URL.Query()
+	parsed, err := parseCQL(q.Get("cql"))
+	if err != nil {
+		return nil, fmt.Errorf("could not parse cql : %s: %w", q.Get("cql"), err)
+	}
+	includeArchived := q.Get("includeArchivedSpaces") == "true"
+
+	var matched []*content
+	for _, c := range s.contents {
+		if c.restricted || c.page.Status != "current" {
+			continue
+		}
+		if space := s.space(c.page.Space.Key); space != nil && space.Status == "archived" && !includeArchived {
+			continue
+		}
+		if parsed.match(c) {
+			matched = append(matched, c)
+		}
+	}
+	sortContent(matched, parsed.order)
+	return matched, nil
+}
+
+// writePageList writes the window of content selected by the start and
+// limit parameters of a request as a page list.
+func (s *Server) writePageList(w http.ResponseWriter, r *http.Request, matched []*content) {
+	start, end, limit, err := s.window(r, len(matched))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	results := make([]confluence.Page, 0, end-start)
+	for _, c := range matched[start:end] {
+		results = append(results, c.page)
+	}
+	writeJSON(w, confluence.PageList{
+		Results:   results,
+		Start:     start,
+		Limit:     limit,
+		Size:      len(results),
+		Links:     nextLink(r, end, len(matched)),
+		TotalSize: len(matched),
+	})
+}
+
+// window returns the bounds of the results selected by the start and limit
+// parameters of a request among total results.
+func (s *Server) window(r *http.Request, total int) (start, end, limit int, err error) {
+	if v := r.URL.Query().Get("start"); v != "" {
+		if start, err = strconv.Atoi(v); err != nil || start < 0 {
+			return 0, 0, 0, fmt.Errorf("invalid start %q", v)
+		}
+	}
+	if limit, err = s.limit(r); err != nil {
+		return 0, 0, 0, err
+	}
+	start = min(start, total)
+	return start, min(start+limit, total), limit, nil
+}
+
+// limit returns the limit parameter of a request, capped to the maximum
+// page size. It defaults to 25, as in the API.
+func (s *Server) limit(r *http.Request) (int, error) {
+	v := r.URL.Query().Get("limit")
+	if v == "" {
+		return min(25, s.maxPageSize), nil
+	}
+	limit, err := strconv.Atoi(v)
+	if err != nil || limit < 0 {
+		return 0, fmt.Errorf("invalid limit %q", v)
+	}
+	return min(limit, s.maxPageSize), nil
+}
+
+// nextLink returns the links of a result page ending at end among total
+// results, with the next link when results remain.
+func nextLink(r *http.Request, end, total int) confluence.ListLinks {
+	if end >= total {
+		return confluence.ListLinks{}
+	}
+	next := url.Values{}
+	for key, values := range r.URL.Query() {
+		next[key] = values
+	}
+	next.Set("start", strconv.Itoa(end))
+	return confluence.ListLinks{Next: strings.TrimPrefix(r.URL.Path, "/wiki") + "?" + next.Encode()}
+}
+
+// excerpt returns the start of a text as a search excerpt.
+func excerpt(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= excerptLength {
+		return text
+	}
+	return string([]rune(text)[:excerptLength]) + "..."
+}
+
+// hasAny reports whether any of values is in names.
+func hasAny(names, values []string) bool {
+	for _, value := range values {
+		if slices.Contains(names, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// nonNil returns s, or an empty slice when s is nil, so lists encode as
+// empty arrays.
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
+
+// writeJSON writes v as a JSON response.
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error response in the format of the API.
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{
+		"statusCode": status,
+		"message":    message,
+	})
+}
@@ -0,0 +1,100 @@
+package confluence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/resolute-sh/resolute/core"
+)
+
+// GetCurrentUser fetches the user the client authenticates as.
+func (c *Client) GetCurrentUser(ctx context.Context) (*User, error) {
+	endpoint := c.apiEndpoint(nil, "user", "current")
+
+	var user User
+	if err := c.getJSON(ctx, endpoint, &user); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// ValidateConnectionInput is the input for ValidateConnectionActivity.
+type ValidateConnectionInput struct {
+	BaseURL  string `validate:"required,url"`
+	Email    string `validate:"required"`
+	APIToken string `validate:"required"`
+	Site     string
+
+	// SpaceKeys lists spaces that must be visible to the user.
+	SpaceKeys []string
+}
+
+// ValidateConnectionOutput is the output of ValidateConnectionActivity.
+type ValidateConnectionOutput struct {
+	AccountID   string
+	DisplayName string
+}
+
+// ValidateConnectionActivity checks that the base URL points to a Confluence
+// site, that the credentials are accepted, and that the given spaces are
+// visible. Run it as the first step of a workflow so misconfiguration fails
+// fast with an actionable, non-retryable error.
+func ValidateConnectionActivity(ctx context.Context, input ValidateConnectionInput) (_ ValidateConnectionOutput, err error) {
+	defer classifyError(&err)
+
+	if err := resolveCredentials(ctx, input.Site, &input.BaseURL, &input.Email, &input.APIToken); err != nil {
+		return ValidateConnectionOutput{}, err
+	}
+	if err := validateInput(input); err != nil {
+		return ValidateConnectionOutput{}, err
+	}
+
+	client := NewClient(ClientConfig{
+		BaseURL:  input.BaseURL,
+		Email:    input.Email,
+		APIToken: input.APIToken,
+	})
+
+	user, err := client.GetCurrentUser(ctx)
+	var syntaxErr *json.SyntaxError
+	switch {
+	case hasStatus(err, http.StatusNotFound) || errors.As(err, &syntaxErr):
+		return ValidateConnectionOutput{}, invalidInputError(
+			fmt.Errorf("%s does not look like a Confluence Cloud site; use the site root, such as https://your-domain.atlassian.net", input.BaseURL))
+	case hasStatus(err, http.StatusUnauthorized) || hasStatus(err, http.StatusForbidden):
+		return ValidateConnectionOutput{}, fmt.Errorf("credentials for %s were rejected; check the email and API token: %w", input.Email, err)
+	case err != nil:
+		return ValidateConnectionOutput{}, fmt.Errorf("get current user: %w", err)
+	}
+
+	var missing []string
+	for _, key := range input.SpaceKeys {
+		_, err := client.GetSpace(ctx, key)
+		if hasStatus(err, http.StatusNotFound) || hasStatus(err, http.StatusForbidden) {
+			missing = append(missing, key)
+			continue
+		}
+		if err != nil {
+			return ValidateConnectionOutput{}, fmt.Errorf("get space %s: %w", key, err)
+		}
+	}
+	if len(missing) > 0 {
+		return ValidateConnectionOutput{}, invalidInputError(
+			fmt.Errorf("spaces %s do not exist or are not visible to %s", strings.Join(missing, ", "), input.Email))
+	}
+
+	return ValidateConnectionOutput{
+		AccountID:   user.AccountID,
+		DisplayName: user.DisplayName,
+	}, nil
+}
+
+// ValidateConnection creates a node for validating a Confluence connection.
+func ValidateConnection(input ValidateConnectionInput) *core.Node[ValidateConnectionInput, ValidateConnectionOutput] {
+	return withPolicy(core.NewNode("confluence.ValidateConnection", ValidateConnectionActivity, input))
+}
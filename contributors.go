@@ -0,0 +1,355 @@
+package confluence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/resolute-sh/resolute-confluence/cql"
+	transform "github.com/resolute-sh/resolute-transform"
+	"github.com/resolute-sh/resolute/core"
+)
+
+// Contributor roles.
+const (
+	RoleCreator   = "creator"
+	RoleEditor    = "editor"
+	RoleCommenter = "commenter"
+)
+
+// Contributor is a user who created, last edited, or commented on pages.
+type Contributor struct {
+	AccountID   string
+	DisplayName string
+
+	// Roles lists the roles the user had on any of the pages, sorted.
+	Roles []string
+	// PageIDs lists the pages the user contributed to, in fetch order.
+	PageIDs []string
+}
+
+// FetchContributorsInput is the input for FetchContributorsActivity.
+type FetchContributorsInput struct {
+	BaseURL  string `validate:"required,url"`
+	Email    string `validate:"required"`
+	APIToken string `validate:"required"`
+	Site     string
+
+	// SpaceKey selects every page of a space. PageIDs selects pages
+	// individually; one of the two is required.
+	SpaceKey string
+	PageIDs  []string
+
+	// IncludeComments adds comment authors, at the cost of one listing per
+	// page.
+	IncludeComments bool
+
+	// Limit is the number of items requested per API call, up to the
+	// API maximum of 250. Defaults to 250.
+	Limit int `validate:"min=0"`
+}
+
+// FetchContributorsOutput is the output of FetchContributorsActivity.
+type FetchContributorsOutput struct {
+	// Contributors is sorted by number of pages, most active first.
+	Contributors []Contributor
+	Pages        int
+	// Denied lists the pages, by ID, left out because the credentials
+	// cannot read them.
+	Denied []ItemError
+	// Errors lists the pages, by ID, left out, or whose comments were left
+	// out, because they failed with a server error or a malformed response.
+	Errors []ItemError
+}
+
+// FetchContributorsActivity collects the creators, last editors, and
+// optionally comment authors of a space or a set of pages. Pages the
+// credentials cannot read are reported in Denied, and pages that fail on
+// their own in Errors, without failing the fetch.
+func FetchContributorsActivity(ctx context.Context, input FetchContributorsInput) (_ FetchContributorsOutput, err error) {
+	defer classifyError(&err)
+
+	if err := resolveCredentials(ctx, input.Site, &input.BaseURL, &input.Email, &input.APIToken); err != nil {
+		return FetchContributorsOutput{}, err
+	}
+	if err := validateInput(input); err != nil {
+		return FetchContributorsOutput{}, err
+	}
+	if input.SpaceKey == "" && len(input.PageIDs) == 0 {
+		return FetchContributorsOutput{}, invalidInputError(errors.New("one of SpaceKey or PageIDs is required"))
+	}
+
+	client := NewClient(ClientConfig{
+		BaseURL:  input.BaseURL,
+		Email:    input.Email,
+		APIToken: input.APIToken,
+	})
+
+	limit := pageSize(ctx, input.Limit)
+
+	expand := []string{"version", "history"}
+
+	var pages []Page
+	if input.SpaceKey != "" {
+		pages, err = listAllPages(ctx, limit, func(start, limit int) (*PageList, error) {
+			return client.ListContent(ctx, ContentQuery{SpaceKey: input.SpaceKey, Expand: expand}, start, limit)
+		})
+		if err != nil {
+			return FetchContributorsOutput{}, fmt.Errorf("list space pages: %w", err)
+		}
+	}
+	var denied, failed []ItemError
+	for _, pageID := range input.PageIDs {
+		page, err := client.GetContent(ctx, pageID, expand)
+		if isInaccessible(err) {
+			denied = append(denied, newItemError(pageID, err))
+			continue
+		}
+		if isItemFailure(err) {
+			failed = append(failed, newItemError(pageID, err))
+			continue
+		}
+		if err != nil {
+			return FetchContributorsOutput{}, fmt.Errorf("get page %s: %w", pageID, err)
+		}
+		pages = append(pages, *page)
+	}
+
+	contributors := newContributorSet()
+	for i, page := range pages {
+		if page.History != nil {
+			contributors.add(page.History.CreatedBy, RoleCreator, page.ID)
+		}
+		contributors.add(page.Version.By, RoleEditor, page.ID)
+
+		if input.IncludeComments {
+			start := 0
+			for {
+				list, err := client.ListPageComments(ctx, page.ID, start, limit)
+				if isInaccessible(err) {
+					denied = append(denied, newItemError(page.ID, err))
+					break
+				}
+				if isItemFailure(err) {
+					failed = append(failed, newItemError(page.ID, err))
+					break
+				}
+				if err != nil {
+					return FetchContributorsOutput{}, fmt.Errorf("list comments of %s at %d: %w", page.ID, start, err)
+				}
+				for _, comment := range list.Results {
+					if comment.History != nil {
+						contributors.add(comment.History.CreatedBy, RoleCommenter, page.ID)
+					}
+				}
+
+				start += len(list.Results)
+				if !list.HasMore() || len(list.Results) == 0 {
+					break
+				}
+			}
+		}
+		recordHeartbeat(ctx, i+1)
+	}
+
+	return FetchContributorsOutput{
+		Contributors: contributors.list(),
+		Pages:        len(pages),
+		Denied:       denied,
+		Errors:       failed,
+	}, nil
+}
+
+// contributorSet accumulates contributors by account ID.
+type contributorSet struct {
+	byID  map[string]*Contributor
+	order []string
+}
+
+func newContributorSet() *contributorSet {
+	return &contributorSet{byID: make(map[string]*Contributor)}
+}
+
+// add records that user had role on a page. Users without an account ID,
+// such as anonymous or deleted users, are ignored.
+func (s *contributorSet) add(user User, role, pageID string) {
+	if user.AccountID == "" {
+		return
+	}
+
+	c, ok := s.byID[user.AccountID]
+	if !ok {
+		c = &Contributor{AccountID: user.AccountID, DisplayName: user.DisplayName}
+		s.byID[user.AccountID] = c
+		s.order = append(s.order, user.AccountID)
+	}
+	if !slices.Contains(c.Roles, role) {
+		c.Roles = append(c.Roles, role)
+		sort.Strings(c.Roles)
+	}
+	if !slices.Contains(c.PageIDs, pageID) {
+		c.PageIDs = append(c.PageIDs, pageID)
+	}
+}
+
+// list returns the contributors, most pages first and otherwise in the
+// order they were first seen.
+func (s *contributorSet) list() []Contributor {
+	list := make([]Contributor, 0, len(s.order))
+	for _, id := range s.order {
+		list = append(list, *s.byID[id])
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return len(list[i].PageIDs) > len(list[j].PageIDs)
+	})
+	return list
+}
+
+// FetchContributors creates a node for collecting Confluence contributors.
+func FetchContributors(input FetchContributorsInput) *core.Node[FetchContributorsInput, FetchContributorsOutput] {
+	return withPolicy(core.NewNode("confluence.FetchContributors", FetchContributorsActivity, input))
+}
+
+// FetchContentByContributorInput is the input for
+// FetchContentByContributorActivity.
+type FetchContentByContributorInput struct {
+	BaseURL  string `validate:"required,url"`
+	Email    string `validate:"required"`
+	APIToken string `validate:"required"`
+	Site     string
+
+	// AccountID is the user whose content is fetched.
+	AccountID string `validate:"required"`
+	// CreatedOnly restricts the fetch to content the user created. By
+	// default, content the user created or edited is fetched.
+	CreatedOnly bool
+
+	// SpaceKeys restricts the fetch to these spaces. Empty covers the whole
+	// site.
+	SpaceKeys []string
+	// Types restricts the fetch to these content types. Defaults to pages
+	// and blog posts.
+	Types []string
+	// Since restricts the fetch to content modified at or after this time.
+	Since *time.Time
+	// IncludeArchivedSpaces covers archived spaces too.
+	IncludeArchivedSpaces bool
+
+	// SkipBodies stores Documents without content, for reviews that only
+	// need to know what the user touched.
+	SkipBodies bool
+
+	// Limit is the number of items requested per API call, up to the
+	// API maximum of 250. Defaults to 250.
+	Limit int `validate:"min=0"`
+
+	// Source is the Source of the stored Documents, which tells apart the
+	// Documents of several sites. Defaults to the hostname of BaseURL.
+	Source string
+}
+
+// FetchContentByContributorOutput is the output of
+// FetchContentByContributorActivity.
+type FetchContentByContributorOutput struct {
+	Ref   core.DataRef
+	Count int
+	// Created is the number of fetched items the user created; the others
+	// were only edited by them.
+	Created int
+}
+
+// FetchContentByContributorActivity fetches the content a user created or
+// edited and stores it as Documents, for offboarding reviews and for
+// finding who knows about a topic. Each Document records the user's role in
+// the "contributor_role" metadata field: RoleCreator or RoleEditor.
+func FetchContentByContributorActivity(ctx context.Context, input FetchContentByContributorInput) (_ FetchContentByContributorOutput, err error) {
+	defer classifyError(&err)
+
+	if err := resolveCredentials(ctx, input.Site, &input.BaseURL, &input.Email, &input.APIToken); err != nil {
+		return FetchContentByContributorOutput{}, err
+	}
+	if err := validateInput(input); err != nil {
+		return FetchContentByContributorOutput{}, err
+	}
+
+	client := NewClient(ClientConfig{
+		BaseURL:  input.BaseURL,
+		Email:    input.Email,
+		APIToken: input.APIToken,
+	})
+
+	source := documentSource(input.BaseURL, input.Source)
+	limit := pageSize(ctx, input.Limit)
+	expand := documentExpand
+	if input.SkipBodies {
+		expand = recentlyUpdatedExpand
+	}
+	opts := SearchOptions{
+		Expand:                expand,
+		IncludeArchivedSpaces: input.IncludeArchivedSpaces,
+	}
+
+	query := contributorCQL(input)
+	pages, err := listAllPages(ctx, limit, func(start, limit int) (*PageList, error) {
+		return client.SearchContentWithOptions(ctx, query, start, limit, opts)
+	})
+	if err != nil {
+		return FetchContentByContributorOutput{}, fmt.Errorf("search content by contributor: %w", err)
+	}
+
+	var output FetchContentByContributorOutput
+	docs := make([]transform.Document, 0, len(pages))
+	for _, page := range pages {
+		if input.Since != nil && page.Version.ModifiedAt().Before(*input.Since) {
+			continue
+		}
+		doc := pageToDocument(page, input.BaseURL, source, ConvertOptions{})
+		role := RoleEditor
+		if page.History != nil && page.History.CreatedBy.AccountID == input.AccountID {
+			role = RoleCreator
+			output.Created++
+		}
+		doc.Metadata["contributor_role"] = role
+		docs = append(docs, doc)
+	}
+
+	ref, err := transform.StoreDocuments(ctx, docs)
+	if err != nil {
+		return FetchContentByContributorOutput{}, fmt.Errorf("store documents: %w", err)
+	}
+	output.Ref = ref
+	output.Count = len(docs)
+
+	return output, nil
+}
+
+// contributorCQL builds the query for the content of a contributor. A Since
+// filter is widened by a day, as CQL dates are only precise to the minute
+// in the caller's time zone, and trimmed client-side.
+func contributorCQL(input FetchContentByContributorInput) string {
+	query := cql.Contributor(input.AccountID)
+	if input.CreatedOnly {
+		query = cql.Creator(input.AccountID)
+	}
+	types := input.Types
+	if len(types) == 0 {
+		types = []string{cql.TypePage, cql.TypeBlogPost}
+	}
+	query = query.And(cql.TypeIn(types...))
+	if len(input.SpaceKeys) > 0 {
+		query = query.And(cql.SpaceIn(input.SpaceKeys...))
+	}
+	if input.Since != nil {
+		query = query.And(cql.LastModifiedAfter(input.Since.Add(-24 * time.Hour)))
+	}
+	return query.OrderBy(cql.FieldLastModified, cql.Descending).String()
+}
+
+// FetchContentByContributor creates a node for fetching the content a
+// Confluence user created or edited.
+func FetchContentByContributor(input FetchContentByContributorInput) *core.Node[FetchContentByContributorInput, FetchContentByContributorOutput] {
+	return withPolicy(core.NewNode("confluence.FetchContentByContributor", FetchContentByContributorActivity, input))
+}
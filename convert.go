@@ -0,0 +1,150 @@
+package confluence
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ConvertOptions controls how storage-format bodies are converted to text.
+type ConvertOptions struct {
+	// CollectCommentRefs records the refs of inline comment markers so
+	// extracted text can be correlated with inline comments.
+	CollectCommentRefs bool
+}
+
+// ConvertResult is the result of converting a storage-format body.
+type ConvertResult struct {
+	Text        string
+	CommentRefs []string
+}
+
+// ConvertStorage converts a Confluence storage-format body to plain text.
+func ConvertStorage(storage string, opts ConvertOptions) ConvertResult {
+	var result ConvertResult
+	if opts.CollectCommentRefs {
+		result.CommentRefs = inlineCommentRefs(storage)
+	}
+
+	storage = stripInlineCommentMarkers(storage)
+	storage = replaceEmoticons(storage)
+	storage = unwrapCDATA(storage)
+	storage = stripMacroParameters(storage)
+	storage = replaceInlineElements(storage)
+
+	blocks := flattenLayout(storage)
+	texts := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		if text := replaceShortnames(tidyPunctuation(stripHTML(block))); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	result.Text = strings.Join(texts, "\n\n")
+
+	return result
+}
+
+var (
+	cdataRegex   = regexp.MustCompile(`(?s)<!\[CDATA\[(.*?)\]\]>`)
+	cdataEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+)
+
+// unwrapCDATA replaces CDATA sections, which hold the bodies of code and
+// other plain-text macros, with their escaped text, so markup-like text in
+// them is kept rather than stripped as tags.
+func unwrapCDATA(storage string) string {
+	return cdataRegex.ReplaceAllStringFunc(storage, func(section string) string {
+		return cdataEscaper.Replace(cdataRegex.FindStringSubmatch(section)[1])
+	})
+}
+
+var (
+	macroParameterRegex = regexp.MustCompile(`(?s)<ac:parameter\b([^>]*)>(.*?)</ac:parameter>`)
+	titleParameterRegex = regexp.MustCompile(`\bac:name="title"`)
+)
+
+// stripMacroParameters removes the values of macro parameters, such as the
+// language of a code macro or the colour of a status, which configure the
+// macro rather than being text. Titles, as of expand and status macros, are
+// kept.
+func stripMacroParameters(storage string) string {
+	return macroParameterRegex.ReplaceAllStringFunc(storage, func(parameter string) string {
+		m := macroParameterRegex.FindStringSubmatch(parameter)
+		if titleParameterRegex.MatchString(m[1]) {
+			return " " + m[2] + " "
+		}
+		return " "
+	})
+}
+
+var punctuationSpaceRegex = regexp.MustCompile(`\s+([.,;:!?]+)(\s|$)`)
+
+// tidyPunctuation removes the space left before punctuation where an inline
+// tag ended, as in "the docs ." for a link followed by a period.
+func tidyPunctuation(text string) string {
+	return punctuationSpaceRegex.ReplaceAllString(text, "$1$2")
+}
+
+var (
+	taskMetadataRegex = regexp.MustCompile(`(?s)<ac:task-(?:id|status)>.*?</ac:task-(?:id|status)>`)
+	mentionRegex      = regexp.MustCompile(`<ri:user\b[^>]*\bri:account-id="([^"]+)"[^>]*>`)
+	timeRegex         = regexp.MustCompile(`<time\b[^>]*\bdatetime="([^"]+)"[^>]*>`)
+)
+
+// replaceInlineElements drops the IDs and statuses of tasks, which are not
+// text, and replaces user mentions and dates, which have no text of their
+// own, with "@" and the account ID and with the date. Mentions are
+// anonymized as configured with SetAnonymization, and left out when users
+// are dropped.
+func replaceInlineElements(storage string) string {
+	storage = taskMetadataRegex.ReplaceAllString(storage, " ")
+	storage = mentionRegex.ReplaceAllStringFunc(storage, func(mention string) string {
+		id := anonymizeAccountID(mentionRegex.FindStringSubmatch(mention)[1])
+		if id == "" {
+			return " "
+		}
+		return " @" + id + " "
+	})
+	return timeRegex.ReplaceAllString(storage, " $1 ")
+}
+
+var layoutTagRegex = regexp.MustCompile(`</?ac:layout(?:-section|-cell)?\b[^>]*>`)
+
+// flattenLayout splits a storage body on layout, section, and cell
+// boundaries so each column is extracted as its own block in reading order.
+// Bodies without layout macros are returned as a single block.
+func flattenLayout(storage string) []string {
+	return layoutTagRegex.Split(storage, -1)
+}
+
+var (
+	inlineCommentMarkerRegex = regexp.MustCompile(`</?ac:inline-comment-marker\b[^>]*>`)
+	inlineCommentRefRegex    = regexp.MustCompile(`<ac:inline-comment-marker\b[^>]*\bac:ref="([^"]*)"`)
+)
+
+// stripInlineCommentMarkers removes inline comment marker tags while keeping
+// the commented text. Markers are removed without a separator so words split
+// by a marker boundary stay intact.
+func stripInlineCommentMarkers(storage string) string {
+	return inlineCommentMarkerRegex.ReplaceAllString(storage, "")
+}
+
+// inlineCommentRefs returns the distinct inline comment marker refs in order
+// of first appearance.
+func inlineCommentRefs(storage string) []string {
+	matches := inlineCommentRefRegex.FindAllStringSubmatch(storage, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	seen := make(map[string]bool, len(matches))
+	refs := make([]string, 0, len(matches))
+	for _, m := range matches {
+		ref := m[1]
+		if ref == "" || seen[ref] {
+			continue
+		}
+		seen[ref] = true
+		refs = append(refs, ref)
+	}
+	return refs
+}
@@ -0,0 +1,37 @@
+package confluence_test
+
+import (
+	"testing"
+
+	"github.com/resolute-sh/resolute-confluence"
+	"github.com/resolute-sh/resolute-confluence/confluencetest"
+)
+
+func TestConvertStorageCorpus(t *testing.T) {
+	confluencetest.CheckCorpus(t)
+}
+
+func TestConvertStorageCDATA(t *testing.T) {
+	storage := `<ac:plain-text-body><![CDATA[if a &lt; b && c > d {}]]></ac:plain-text-body>`
+	got := confluence.ConvertStorage(storage, confluence.ConvertOptions{}).Text
+	if want := "if a &lt; b && c > d {}"; got != want {
+		t.Errorf("ConvertStorage() = %q, want %q", got, want)
+	}
+}
+
+func TestConvertStorageShortnames(t *testing.T) {
+	tests := []struct {
+		storage string
+		want    string
+	}{
+		{`<p>:smile: shipped (:link:)</p>`, "😄 shipped (🔗)"},
+		{`<p>use std::link::Path</p>`, "use std::link::Path"},
+		{`<p>at 10:link:30</p>`, "at 10:link:30"},
+	}
+	for _, tt := range tests {
+		got := confluence.ConvertStorage(tt.storage, confluence.ConvertOptions{}).Text
+		if got != tt.want {
+			t.Errorf("ConvertStorage(%q) = %q, want %q", tt.storage, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+// Package cql builds Confluence Query Language expressions. Values are
+// quoted and escaped, and times formatted, so queries built from untrusted
+// input cannot change the structure of the expression.
+//
+//	q := cql.Space("ENG").
+//		And(cql.LabelIn("runbook", "oncall")).
+//		And(cql.LastModifiedAfter(since)).
+//		OrderBy(cql.FieldLastModified, cql.Descending)
+//	client.SearchPages(ctx, q.String(), 0, 100)
+package cql
+
+import (
+	"strings"
+	"time"
+)
+
+// Fields usable in queries and ordering.
+const (
+	FieldAncestor     = "ancestor"
+	FieldContributor  = "contributor"
+	FieldCreated      = "created"
+	FieldCreator      = "creator"
+	FieldID           = "id"
+	FieldLabel        = "label"
+	FieldLastModified = "lastmodified"
+	FieldParent       = "parent"
+	FieldSiteSearch   = "siteSearch"
+	FieldSpace        = "space"
+	FieldText         = "text"
+	FieldTitle        = "title"
+	FieldType         = "type"
+)
+
+// Content types usable with Type.
+const (
+	TypePage       = "page"
+	TypeBlogPost   = "blogpost"
+	TypeComment    = "comment"
+	TypeAttachment = "attachment"
+)
+
+// Sort directions of OrderBy.
+const (
+	Ascending  = "asc"
+	Descending = "desc"
+)
+
+// DateFormat is the date format of CQL date comparisons.
+const DateFormat = "2006-01-02 15:04"
+
+// Query is a CQL expression. The zero Query matches everything and is
+// dropped when combined with other queries.
+type Query struct {
+	expr string
+	// op is the operator joining the terms of expr, if several.
+	op    string
+	order []string
+}
+
+// Field compares a field with a value using op, such as "=", "!=", "~",
+// "<", or ">=".
+func Field(field, op, value string) Query {
+	return Query{expr: field + " " + op + " " + Quote(value)}
+}
+
+// FieldIn matches a field against any of the values. Without values it
+// matches nothing.
+func FieldIn(field string, values ...string) Query {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = Quote(v)
+	}
+	return Query{expr: inExpr(field, quoted)}
+}
+
+func inExpr(field string, quoted []string) string {
+	return field + " in (" + strings.Join(quoted, ", ") + ")"
+}
+
+// FieldInChunks splits FieldIn(field, values...) into queries whose
+// expressions are at most maxLen bytes long, for value lists too long to
+// send in a single request URL. A value too long to fit alone gets a query
+// of its own.
+func FieldInChunks(field string, maxLen int, values ...string) []Query {
+	var chunks []Query
+	var chunk []string
+	size := len(field) + len(" in ()")
+	for _, v := range values {
+		quoted := Quote(v)
+		if len(chunk) > 0 && size+len(", ")+len(quoted) > maxLen {
+			chunks = append(chunks, Query{expr: inExpr(field, chunk)})
+			chunk = nil
+			size = len(field) + len(" in ()")
+		}
+		if len(chunk) > 0 {
+			size += len(", ")
+		}
+		chunk = append(chunk, quoted)
+		size += len(quoted)
+	}
+	if len(chunk) > 0 {
+		chunks = append(chunks, Query{expr: inExpr(field, chunk)})
+	}
+	return chunks
+}
+
+// Space matches content in a space.
+func Space(key string) Query {
+	return Field(FieldSpace, "=", key)
+}
+
+// SpaceIn matches content in any of the spaces.
+func SpaceIn(keys ...string) Query {
+	return FieldIn(FieldSpace, keys...)
+}
+
+// Type matches content of a type, such as TypePage.
+func Type(contentType string) Query {
+	return Field(FieldType, "=", contentType)
+}
+
+// TypeIn matches content of any of the types.
+func TypeIn(contentTypes ...string) Query {
+	return FieldIn(FieldType, contentTypes...)
+}
+
+// Label matches content with a label.
+func Label(label string) Query {
+	return Field(FieldLabel, "=", label)
+}
+
+// LabelIn matches content with any of the labels.
+func LabelIn(labels ...string) Query {
+	return FieldIn(FieldLabel, labels...)
+}
+
+// Title matches content with exactly this title.
+func Title(title string) Query {
+	return Field(FieldTitle, "=", title)
+}
+
+// TitleContains matches content whose title contains the words of text.
+func TitleContains(text string) Query {
+	return Field(FieldTitle, "~", text)
+}
+
+// Text matches content whose title, body, or labels contain the words of
+// text.
+func Text(text string) Query {
+	return Field(FieldText, "~", text)
+}
+
+// SiteSearch matches content the way the site search box does, with
+// relevance ranking and stemming across titles, bodies, and labels.
+func SiteSearch(text string) Query {
+	return Field(FieldSiteSearch, "~", text)
+}
+
+// ID matches the content with an ID.
+func ID(id string) Query {
+	return Field(FieldID, "=", id)
+}
+
+// IDIn matches the content with any of the IDs.
+func IDIn(ids ...string) Query {
+	return FieldIn(FieldID, ids...)
+}
+
+// Ancestor matches the descendants of a page.
+func Ancestor(pageID string) Query {
+	return Field(FieldAncestor, "=", pageID)
+}
+
+// Parent matches the direct children of a page.
+func Parent(pageID string) Query {
+	return Field(FieldParent, "=", pageID)
+}
+
+// Creator matches content created by a user, by account ID.
+func Creator(accountID string) Query {
+	return Field(FieldCreator, "=", accountID)
+}
+
+// Contributor matches content created or edited by a user, by account ID.
+func Contributor(accountID string) Query {
+	return Field(FieldContributor, "=", accountID)
+}
+
+// LastModifiedAfter matches content modified at or after t. CQL compares
+// dates to the minute in the time zone of the calling user, so callers that
+// need exact bounds should widen the window and filter the results.
+func LastModifiedAfter(t time.Time) Query {
+	return Field(FieldLastModified, ">=", Date(t))
+}
+
+// LastModifiedBefore matches content modified before t.
+func LastModifiedBefore(t time.Time) Query {
+	return Field(FieldLastModified, "<", Date(t))
+}
+
+// CreatedAfter matches content created at or after t.
+func CreatedAfter(t time.Time) Query {
+	return Field(FieldCreated, ">=", Date(t))
+}
+
+// CreatedBefore matches content created before t.
+func CreatedBefore(t time.Time) Query {
+	return Field(FieldCreated, "<", Date(t))
+}
+
+// And matches content matching q and every other query.
+func (q Query) And(others ...Query) Query {
+	return q.join("and", others)
+}
+
+// Or matches content matching q or any other query.
+func (q Query) Or(others ...Query) Query {
+	return q.join("or", others)
+}
+
+// join joins queries with op, parenthesizing those joined by another
+// operator so the result does not depend on CQL operator precedence.
+func (q Query) join(op string, others []Query) Query {
+	parts := make([]string, 0, len(others)+1)
+	for _, part := range append([]Query{q}, others...) {
+		switch {
+		case part.expr == "":
+		case part.op != "" && part.op != op:
+			parts = append(parts, "("+part.expr+")")
+		default:
+			parts = append(parts, part.expr)
+		}
+	}
+
+	joined := Query{expr: strings.Join(parts, " "+op+" "), order: q.order}
+	if len(parts) > 1 {
+		joined.op = op
+	}
+	return joined
+}
+
+// Not matches content that does not match q.
+func Not(q Query) Query {
+	if q.expr == "" {
+		return q
+	}
+	return Query{expr: "not (" + q.expr + ")", order: q.order}
+}
+
+// OrderBy sorts the results by field in direction, after any earlier sort
+// fields.
+func (q Query) OrderBy(field, direction string) Query {
+	q.order = append(append([]string(nil), q.order...), field+" "+direction)
+	return q
+}
+
+// String returns the CQL expression.
+func (q Query) String() string {
+	if len(q.order) == 0 {
+		return q.expr
+	}
+	order := "order by " + strings.Join(q.order, ", ")
+	if q.expr == "" {
+		return order
+	}
+	return q.expr + " " + order
+}
+
+// Quote quotes a value for use in a CQL expression.
+func Quote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	return `"` + value + `"`
+}
+
+// Date formats t for CQL date comparisons, in UTC.
+func Date(t time.Time) string {
+	return t.UTC().Format(DateFormat)
+}
@@ -0,0 +1,195 @@
+package confluence
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Credentials identify a Confluence site and the account used to access it.
+type Credentials struct {
+	BaseURL  string
+	Email    string
+	APIToken string
+}
+
+// CredentialResolver returns the credentials used by activities whose input
+// leaves BaseURL, Email, or APIToken empty. It is called once per activity
+// execution, so it can fetch short-lived secrets from a vault.
+type CredentialResolver func(ctx context.Context) (Credentials, error)
+
+var (
+	credentialsMu      sync.RWMutex
+	credentialResolver CredentialResolver
+	siteResolvers      map[string]CredentialResolver
+)
+
+// SetDefaultCredentials sets the credentials used by activities whose input
+// leaves them empty. Provider's WithCredentials option is equivalent.
+func SetDefaultCredentials(creds Credentials) {
+	SetCredentialResolver(staticCredentials(creds))
+}
+
+// staticCredentials returns a resolver that always returns creds.
+func staticCredentials(creds Credentials) CredentialResolver {
+	return func(context.Context) (Credentials, error) {
+		return creds, nil
+	}
+}
+
+// SetCredentialResolver sets the resolver of default credentials. A nil
+// resolver removes the defaults.
+func SetCredentialResolver(resolver CredentialResolver) {
+	credentialsMu.Lock()
+	defer credentialsMu.Unlock()
+	credentialResolver = resolver
+}
+
+// SetSite registers the credentials of a site under an alias, such as
+// "prod" or "eu", so that one worker can serve several sites and activity
+// inputs name the site in their Site field instead of repeating its
+// connection details. Provider's WithSites option is equivalent.
+func SetSite(alias string, creds Credentials) {
+	SetSiteResolver(alias, staticCredentials(creds))
+}
+
+// SetSiteResolver registers a resolver of the credentials of a site under an
+// alias. A nil resolver removes the site.
+func SetSiteResolver(alias string, resolver CredentialResolver) {
+	credentialsMu.Lock()
+	defer credentialsMu.Unlock()
+	if resolver == nil {
+		delete(siteResolvers, alias)
+		return
+	}
+	if siteResolvers == nil {
+		siteResolvers = make(map[string]CredentialResolver)
+	}
+	siteResolvers[alias] = resolver
+}
+
+// setSiteResolvers replaces every registered site with the resolvers of
+// sites, keyed by alias.
+func setSiteResolvers(sites map[string]CredentialResolver) {
+	credentialsMu.Lock()
+	defer credentialsMu.Unlock()
+	siteResolvers = make(map[string]CredentialResolver, len(sites))
+	for alias, resolver := range sites {
+		if resolver != nil {
+			siteResolvers[alias] = resolver
+		}
+	}
+}
+
+// Sites returns the registered site aliases, sorted.
+func Sites() []string {
+	credentialsMu.RLock()
+	defer credentialsMu.RUnlock()
+	aliases := make([]string, 0, len(siteResolvers))
+	for alias := range siteResolvers {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
+// resolveCredentials fills empty connection fields of an activity input from
+// the credentials of its site, or the default credentials when it names no
+// site, and normalizes the base URL, rejecting an unusable one as invalid
+// input. Defaults are only applied when the input targets the default site,
+// so a default token is never sent to another site.
+func resolveCredentials(ctx context.Context, site string, baseURL, email, apiToken *string) error {
+	var err error
+	if site != "" {
+		err = resolveSite(ctx, site, baseURL, email, apiToken)
+	} else {
+		err = resolveDefaults(ctx, baseURL, email, apiToken)
+	}
+	if err != nil {
+		return err
+	}
+	if *baseURL == "" {
+		return nil
+	}
+
+	normalized, err := NormalizeBaseURL(*baseURL)
+	if err != nil {
+		return invalidInputError(err)
+	}
+	*baseURL = normalized
+	return nil
+}
+
+// resolveDefaults fills empty connection fields from the default
+// credentials.
+func resolveDefaults(ctx context.Context, baseURL, email, apiToken *string) error {
+	if *baseURL != "" && *email != "" && *apiToken != "" {
+		return nil
+	}
+
+	credentialsMu.RLock()
+	resolver := credentialResolver
+	credentialsMu.RUnlock()
+	if resolver == nil {
+		return nil
+	}
+
+	creds, err := resolver(ctx)
+	if err != nil {
+		return fmt.Errorf("resolve credentials: %w", err)
+	}
+	if *baseURL != "" && !sameSite(*baseURL, creds.BaseURL) {
+		return nil
+	}
+
+	fillCredentials(creds, baseURL, email, apiToken)
+	return nil
+}
+
+// resolveSite fills empty connection fields from the credentials of a
+// registered site. An unknown alias, or a base URL of another site, is
+// invalid input.
+func resolveSite(ctx context.Context, site string, baseURL, email, apiToken *string) error {
+	credentialsMu.RLock()
+	resolver, ok := siteResolvers[site]
+	credentialsMu.RUnlock()
+	if !ok {
+		return invalidInputError(fmt.Errorf("unknown site %q", site))
+	}
+
+	creds, err := resolver(ctx)
+	if err != nil {
+		return fmt.Errorf("resolve credentials of site %q: %w", site, err)
+	}
+	if *baseURL != "" && !sameSite(*baseURL, creds.BaseURL) {
+		return invalidInputError(fmt.Errorf("base URL %s is not the URL of site %q", *baseURL, site))
+	}
+
+	fillCredentials(creds, baseURL, email, apiToken)
+	return nil
+}
+
+// fillCredentials sets the empty connection fields from creds.
+func fillCredentials(creds Credentials, baseURL, email, apiToken *string) {
+	if *baseURL == "" {
+		*baseURL = creds.BaseURL
+	}
+	if *email == "" {
+		*email = creds.Email
+	}
+	if *apiToken == "" {
+		*apiToken = creds.APIToken
+	}
+}
+
+// sameSite reports whether two base URLs point to the same site once
+// normalized.
+func sameSite(a, b string) bool {
+	na, errA := NormalizeBaseURL(a)
+	nb, errB := NormalizeBaseURL(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return na == nb
+}
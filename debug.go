@@ -0,0 +1,154 @@
+package confluence
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	debugMu     sync.RWMutex
+	debugWriter io.Writer
+)
+
+// SetDebugWriter sets the writer receiving a dump of the requests of every
+// client whose config has no DebugWriter, including the clients of
+// activities. A nil writer turns the dumps off. Provider's WithDebugWriter
+// option is equivalent.
+func SetDebugWriter(w io.Writer) {
+	debugMu.Lock()
+	defer debugMu.Unlock()
+	debugWriter = w
+}
+
+// defaultDebugWriter returns the writer set with SetDebugWriter.
+func defaultDebugWriter() io.Writer {
+	debugMu.RLock()
+	defer debugMu.RUnlock()
+	return debugWriter
+}
+
+// debugBodyLimit is the number of bytes of each body a debug dump shows.
+const debugBodyLimit = 4 << 10
+
+// debugTransport dumps every request and response passing through it to w.
+// Headers are left out, so credentials never reach the dump, and bodies are
+// truncated to debugBodyLimit bytes.
+type debugTransport struct {
+	next http.RoundTripper
+	mu   *sync.Mutex
+	w    io.Writer
+}
+
+func newDebugTransport(next http.RoundTripper, w io.Writer) *debugTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &debugTransport{next: next, mu: &sync.Mutex{}, w: w}
+}
+
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var dump bytes.Buffer
+	fmt.Fprintf(&dump, "--> %s %s\n", req.Method, req.URL.Redacted())
+	if req.GetBody != nil && req.ContentLength != 0 {
+		if body, err := req.GetBody(); err == nil {
+			if req.Header.Get("Content-Encoding") == "gzip" {
+				if zr, err := gzip.NewReader(body); err == nil {
+					body = zr
+				}
+			}
+			writeDebugBody(&dump, req.Header.Get("Content-Type"), body)
+		}
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		fmt.Fprintf(&dump, "<-- error after %s: %v\n", time.Since(start).Round(time.Millisecond), err)
+		t.write(dump.Bytes())
+		return nil, err
+	}
+
+	fmt.Fprintf(&dump, "<-- %s (%s)\n", resp.Status, time.Since(start).Round(time.Millisecond))
+	resp.Body = &debugBody{
+		ReadCloser: resp.Body,
+		transport:  t,
+		dump:       &dump,
+		mediaType:  resp.Header.Get("Content-Type"),
+	}
+	return resp, nil
+}
+
+// write writes a complete dump at once, so concurrent exchanges do not
+// interleave.
+func (t *debugTransport) write(dump []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.w.Write(dump)
+}
+
+// debugBody captures the start of a response body as it is read, and
+// writes the dump of the exchange once the body is closed.
+type debugBody struct {
+	io.ReadCloser
+	transport *debugTransport
+	dump      *bytes.Buffer
+	mediaType string
+	head      bytes.Buffer
+	size      int64
+	once      sync.Once
+}
+
+func (b *debugBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if room := debugBodyLimit + 1 - b.head.Len(); room > 0 {
+		b.head.Write(p[:min(n, room)])
+	}
+	b.size += int64(n)
+	return n, err
+}
+
+func (b *debugBody) Close() error {
+	b.once.Do(func() {
+		writeDebugBody(b.dump, b.mediaType, &b.head)
+		if b.size > debugBodyLimit {
+			fmt.Fprintf(b.dump, "    [%d bytes read]\n", b.size)
+		}
+		b.dump.WriteByte('\n')
+		b.transport.write(b.dump.Bytes())
+	})
+	return b.ReadCloser.Close()
+}
+
+// writeDebugBody writes up to debugBodyLimit bytes of a textual body, and
+// only the media type of others.
+func writeDebugBody(dump *bytes.Buffer, contentType string, body io.Reader) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	textual := mediaType == "" || strings.HasPrefix(mediaType, "text/") ||
+		strings.HasSuffix(mediaType, "json") || strings.HasSuffix(mediaType, "xml")
+	if !textual {
+		fmt.Fprintf(dump, "    [%s body]\n", mediaType)
+		return
+	}
+
+	data, _ := io.ReadAll(io.LimitReader(body, debugBodyLimit+1))
+	if len(data) == 0 {
+		return
+	}
+	truncated := len(data) > debugBodyLimit
+	if truncated {
+		data = data[:debugBodyLimit]
+	}
+	dump.WriteString("    ")
+	dump.Write(bytes.ReplaceAll(data, []byte("\n"), []byte("\n    ")))
+	if truncated {
+		dump.WriteString(" [truncated]")
+	}
+	dump.WriteByte('\n')
+}
@@ -0,0 +1,141 @@
+package confluence
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.temporal.io/sdk/activity"
+)
+
+// defaultRequestTimeout bounds each API call when neither the client config
+// nor the activity defaults set a timeout.
+const defaultRequestTimeout = 30 * time.Second
+
+// ActivityDefaults are the values activities use for the inputs and settings
+// workflows leave unset, so operators can set guardrails for a worker
+// without editing every workflow definition. Zero fields are not set.
+type ActivityDefaults struct {
+	// Limit is the number of items requested per API call, up to the API
+	// maximum of 250.
+	Limit int
+
+	// MaxResults caps the results fetched by one call of the activities
+	// with a MaxResults input, FetchPages and SearchCQL.
+	MaxResults int
+
+	// Timeout bounds each API call. ClientConfig.Timeout takes precedence.
+	Timeout time.Duration
+
+	// Retry configures how failed API calls are retried, in line with the
+	// retry policy of the activity. ClientConfig.Retry takes precedence.
+	Retry RequestRetryPolicy
+}
+
+// merge returns d with its unset fields taken from fallback.
+func (d ActivityDefaults) merge(fallback ActivityDefaults) ActivityDefaults {
+	if d.Limit <= 0 {
+		d.Limit = fallback.Limit
+	}
+	if d.MaxResults <= 0 {
+		d.MaxResults = fallback.MaxResults
+	}
+	if d.Timeout <= 0 {
+		d.Timeout = fallback.Timeout
+	}
+	if d.Retry.MaxAttempts <= 0 {
+		d.Retry = fallback.Retry
+	}
+	return d
+}
+
+var (
+	defaultsMu             sync.RWMutex
+	globalActivityDefaults ActivityDefaults
+	activityDefaultsByName map[string]ActivityDefaults
+)
+
+// SetDefaults sets the defaults of every activity. Provider's WithDefaults
+// option is equivalent.
+func SetDefaults(d ActivityDefaults) {
+	defaultsMu.Lock()
+	defer defaultsMu.Unlock()
+	globalActivityDefaults = d
+}
+
+// SetActivityDefaults sets the defaults of a named activity, such as
+// "confluence.FetchPages", overriding the fields set with SetDefaults. The
+// defaults apply to every version and alias of the activity. Provider's
+// WithActivityDefaults option is equivalent.
+func SetActivityDefaults(name string, d ActivityDefaults) {
+	defaultsMu.Lock()
+	defer defaultsMu.Unlock()
+	if activityDefaultsByName == nil {
+		activityDefaultsByName = make(map[string]ActivityDefaults)
+	}
+	activityDefaultsByName[baseName(name)] = d
+}
+
+// setActivityDefaultsByName replaces the defaults of every named activity
+// with defaults, keyed by activity name.
+func setActivityDefaultsByName(defaults map[string]ActivityDefaults) {
+	defaultsMu.Lock()
+	defer defaultsMu.Unlock()
+	activityDefaultsByName = make(map[string]ActivityDefaults, len(defaults))
+	for name, d := range defaults {
+		activityDefaultsByName[baseName(name)] = d
+	}
+}
+
+// activityName returns the registered name of the activity running with
+// ctx, resolving local activities, which are named after their function.
+func activityName(ctx context.Context) string {
+	name := activity.GetInfo(ctx).ActivityType.Name
+	if registered, ok := localActivityNames[name]; ok {
+		return registered
+	}
+	return name
+}
+
+// activityDefaults returns the defaults of the activity running with ctx.
+// Outside an activity only the defaults of every activity apply.
+func activityDefaults(ctx context.Context) ActivityDefaults {
+	defaultsMu.RLock()
+	defer defaultsMu.RUnlock()
+	if !activity.IsActivity(ctx) {
+		return globalActivityDefaults
+	}
+	d := activityDefaultsByName[baseName(activityName(ctx))]
+	return d.merge(globalActivityDefaults)
+}
+
+// maxResults returns the MaxResults of an input, or the default of the
+// running activity when the input leaves it zero.
+func maxResults(ctx context.Context, n int) int {
+	if n > 0 {
+		return n
+	}
+	return activityDefaults(ctx).MaxResults
+}
+
+// nearDeadline reports whether the deadline of ctx is too close for
+// another API call, so paginated fetches can stop with the results
+// collected so far and a cursor to resume from rather than time out. The
+// margin is the default request timeout, capped at a quarter of the time
+// the running activity was given.
+func nearDeadline(ctx context.Context) bool {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return false
+	}
+	margin := activityDefaults(ctx).Timeout
+	if margin <= 0 {
+		margin = defaultRequestTimeout
+	}
+	if activity.IsActivity(ctx) {
+		if started := activity.GetInfo(ctx).StartedTime; !started.IsZero() {
+			margin = min(margin, deadline.Sub(started)/4)
+		}
+	}
+	return time.Until(deadline) < margin
+}
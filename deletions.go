@@ -0,0 +1,288 @@
+package confluence
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/resolute-sh/resolute-confluence/cql"
+	transform "github.com/resolute-sh/resolute-transform"
+	"github.com/resolute-sh/resolute/core"
+	"golang.org/x/sync/errgroup"
+)
+
+// Tombstone reasons recorded in the "deleted_reason" metadata field.
+const (
+	DeletedReasonTrashed = "trashed"
+	DeletedReasonMissing = "missing"
+	DeletedReasonRemoved = "removed"
+)
+
+// DetectDeletionsInput is the input for DetectDeletionsActivity.
+type DetectDeletionsInput struct {
+	BaseURL  string `validate:"required,url"`
+	Email    string `validate:"required"`
+	APIToken string `validate:"required"`
+	Site     string
+	SpaceKey string `validate:"required"`
+
+	// PreviousRef optionally references the Documents of a prior sync. Pages
+	// present in the snapshot but no longer listed in the space are reported
+	// as deleted. Only the current pages of SpaceKey in the snapshot are
+	// reconciled; the Documents of other spaces, blog posts, and pages of
+	// other statuses are left out, since the live listing does not cover
+	// them. When empty, only the space trash is inspected.
+	PreviousRef core.DataRef
+
+	// Limit is the number of pages requested per API call, up to the
+	// API maximum of 250. Defaults to 250.
+	Limit int `validate:"min=0"`
+
+	// Concurrency is the number of API pages of the space listing requested
+	// at once. Values of 0 or 1 list the space sequentially.
+	Concurrency int `validate:"min=0"`
+
+	// Source is the Source of the stored Documents, which tells apart the
+	// Documents of several sites. Defaults to the hostname of BaseURL.
+	Source string
+}
+
+// DetectDeletionsOutput is the output of DetectDeletionsActivity.
+type DetectDeletionsOutput struct {
+	Ref   core.DataRef
+	Count int
+
+	// Trashed is the number of tombstones found in the space trash.
+	Trashed int
+	// Missing is the number of tombstones found by snapshot reconciliation.
+	Missing int
+}
+
+// DetectDeletionsActivity finds trashed and removed pages in a space and
+// stores a tombstone Document for each, so downstream indexes can evict them.
+func DetectDeletionsActivity(ctx context.Context, input DetectDeletionsInput) (_ DetectDeletionsOutput, err error) {
+	defer classifyError(&err)
+
+	if err := resolveCredentials(ctx, input.Site, &input.BaseURL, &input.Email, &input.APIToken); err != nil {
+		return DetectDeletionsOutput{}, err
+	}
+	if err := validateInput(input); err != nil {
+		return DetectDeletionsOutput{}, err
+	}
+
+	client := NewClient(ClientConfig{
+		BaseURL:  input.BaseURL,
+		Email:    input.Email,
+		APIToken: input.APIToken,
+	})
+
+	source := documentSource(input.BaseURL, input.Source)
+
+	limit := pageSize(ctx, input.Limit)
+
+	var docs []transform.Document
+	seen := make(map[string]bool)
+
+	trashed, err := listAllPages(ctx, limit, func(start, limit int) (*PageList, error) {
+		return client.ListTrashedPages(ctx, input.SpaceKey, start, limit)
+	})
+	if err != nil {
+		return DetectDeletionsOutput{}, fmt.Errorf("list trashed pages: %w", err)
+	}
+	for _, page := range trashed {
+		seen[page.ID] = true
+		docs = append(docs, tombstoneDocument(page.ID, input.SpaceKey, source, DeletedReasonTrashed, page.Version.ModifiedAt()))
+	}
+
+	missing := 0
+	if !input.PreviousRef.IsEmpty() {
+		previous, err := transform.LoadDocuments(ctx, input.PreviousRef)
+		if err != nil {
+			return DetectDeletionsOutput{}, fmt.Errorf("load previous documents: %w", err)
+		}
+
+		total := 0
+		if input.Concurrency > 1 {
+			total, err = client.CountCQL(ctx, cql.Space(input.SpaceKey).And(cql.Type(cql.TypePage)).String())
+			if err != nil {
+				return DetectDeletionsOutput{}, fmt.Errorf("count space pages: %w", err)
+			}
+		}
+		current, err := listAllPagesConcurrently(ctx, limit, total, input.Concurrency, func(ctx context.Context, start, limit int) (*PageList, error) {
+			return client.ListSpacePageSummaries(ctx, input.SpaceKey, start, limit)
+		})
+		if err != nil {
+			return DetectDeletionsOutput{}, fmt.Errorf("list space pages: %w", err)
+		}
+
+		live := make(map[string]bool, len(current))
+		for _, page := range current {
+			live[page.ID] = true
+		}
+
+		for _, doc := range previous {
+			id := documentPageID(doc)
+			if id == "" || live[id] || seen[id] || !reconcilable(doc, input.SpaceKey) {
+				continue
+			}
+			seen[id] = true
+			docs = append(docs, tombstoneDocument(id, input.SpaceKey, source, DeletedReasonMissing, time.Time{}))
+			missing++
+		}
+	}
+
+	ref, err := transform.StoreDocuments(ctx, docs)
+	if err != nil {
+		return DetectDeletionsOutput{}, fmt.Errorf("store documents: %w", err)
+	}
+
+	return DetectDeletionsOutput{
+		Ref:     ref,
+		Count:   len(docs),
+		Trashed: len(trashed),
+		Missing: missing,
+	}, nil
+}
+
+// listAllPages collects every page of a paginated listing.
+func listAllPages(ctx context.Context, limit int, list func(start, limit int) (*PageList, error)) ([]Page, error) {
+	var pages []Page
+	start := 0
+
+	for {
+		result, err := list(start, limit)
+		if err != nil {
+			return nil, fmt.Errorf("list at %d: %w", start, err)
+		}
+
+		pages = append(pages, result.Results...)
+		recordHeartbeat(ctx, len(pages))
+
+		start += len(result.Results)
+		if !result.HasMore() || len(result.Results) == 0 {
+			break
+		}
+	}
+
+	return pages, nil
+}
+
+// listAllPagesConcurrently collects every page of a paginated listing of
+// about total items, requesting up to concurrency API pages at once. The
+// first page is fetched alone to learn the page size the server applies.
+// Without a known total, from the listing or the caller, the remaining pages
+// are fetched sequentially, and items added while listing are picked up by
+// continuing sequentially past total. list is called with the context to
+// request the page with, which is canceled once a concurrent request fails.
+func listAllPagesConcurrently(ctx context.Context, limit, total, concurrency int, list func(ctx context.Context, start, limit int) (*PageList, error)) ([]Page, error) {
+	first, err := list(ctx, 0, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list at 0: %w", err)
+	}
+	pages := first.Results
+	recordHeartbeat(ctx, len(pages))
+	if !first.HasMore() || len(first.Results) == 0 {
+		return pages, nil
+	}
+
+	if total <= 0 {
+		total = first.TotalSize
+	}
+	step := len(first.Results)
+	start := step
+	if concurrency > 1 && total > start {
+		var offsets []int
+		for offset := start; offset < total; offset += step {
+			offsets = append(offsets, offset)
+		}
+
+		results := make([]*PageList, len(offsets))
+		g, gctx := errgroup.WithContext(ctx)
+		g.SetLimit(concurrency)
+		for i, offset := range offsets {
+			g.Go(func() error {
+				result, err := list(gctx, offset, step)
+				if err != nil {
+					return fmt.Errorf("list at %d: %w", offset, err)
+				}
+				results[i] = result
+				recordHeartbeat(gctx, offset+len(result.Results))
+				return nil
+			})
+		}
+		if err := g.Wait(); err != nil {
+			return nil, err
+		}
+
+		for _, result := range results {
+			pages = append(pages, result.Results...)
+		}
+		last := results[len(results)-1]
+		if !last.HasMore() || len(last.Results) == 0 {
+			return pages, nil
+		}
+		start = offsets[len(offsets)-1] + len(last.Results)
+	}
+
+	for {
+		result, err := list(ctx, start, step)
+		if err != nil {
+			return nil, fmt.Errorf("list at %d: %w", start, err)
+		}
+
+		pages = append(pages, result.Results...)
+		recordHeartbeat(ctx, len(pages))
+
+		start += len(result.Results)
+		if !result.HasMore() || len(result.Results) == 0 {
+			return pages, nil
+		}
+	}
+}
+
+// documentPageID returns the Confluence page ID a Document was built from,
+// resolving chunks to their parent.
+func documentPageID(doc transform.Document) string {
+	if id := doc.Metadata["page_id"]; id != "" {
+		return id
+	}
+	if doc.IsChunk() {
+		return doc.ParentID
+	}
+	return doc.ID
+}
+
+// reconcilable reports whether a snapshot Document is a current page of the
+// space, which the space listing of snapshot reconciliation covers. Other
+// Documents cannot be told missing from that listing. Documents without a
+// content type or status, such as those of snapshots that predate them,
+// are taken for current pages.
+func reconcilable(doc transform.Document, spaceKey string) bool {
+	if doc.Metadata["deleted"] == "true" || doc.Metadata["space_key"] != spaceKey {
+		return false
+	}
+	contentType := doc.Metadata["content_type"]
+	status := doc.Metadata["status"]
+	return (contentType == "" || contentType == cql.TypePage) &&
+		(status == "" || status == StatusCurrent)
+}
+
+// tombstoneDocument builds an empty Document marking a page as deleted.
+func tombstoneDocument(pageID, spaceKey, source, reason string, deletedAt time.Time) transform.Document {
+	return transform.Document{
+		ID:     pageID,
+		Source: source,
+		Metadata: map[string]string{
+			"page_id":        pageID,
+			"space_key":      spaceKey,
+			"deleted":        "true",
+			"deleted_reason": reason,
+		},
+		UpdatedAt: deletedAt,
+	}
+}
+
+// DetectDeletions creates a node for detecting deleted Confluence pages.
+func DetectDeletions(input DetectDeletionsInput) *core.Node[DetectDeletionsInput, DetectDeletionsOutput] {
+	return withPolicy(core.NewNode("confluence.DetectDeletions", DetectDeletionsActivity, input))
+}
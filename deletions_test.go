@@ -0,0 +1,112 @@
+package confluence_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/resolute-sh/resolute-confluence"
+	"github.com/resolute-sh/resolute-confluence/confluencetest"
+	transform "github.com/resolute-sh/resolute-transform"
+)
+
+func TestDetectDeletionsActivity(t *testing.T) {
+	srv := newServer(t)
+	kept := srv.AddPage(confluencetest.NewPage("ENG", "Kept", "<p>kept</p>"))
+	trashed := confluencetest.NewPage("ENG", "Trashed", "<p>trashed</p>")
+	trashed.Status = "trashed"
+	trashed = srv.AddPage(trashed)
+	srv.AddPage(confluencetest.NewPage("OPS", "Other", "<p>other</p>"))
+
+	previous, err := transform.StoreDocuments(context.Background(), []transform.Document{
+		snapshotDocument(kept.ID, "ENG", "page"),
+		snapshotDocument("900", "ENG", "page"),
+		snapshotDocument("901", "OPS", "page"),
+		snapshotDocument("902", "ENG", "blogpost"),
+		snapshotDocument("903", "ENG", ""),
+	})
+	if err != nil {
+		t.Fatalf("StoreDocuments() error = %v", err)
+	}
+
+	out, err := confluence.DetectDeletionsActivity(context.Background(), confluence.DetectDeletionsInput{
+		BaseURL:     srv.URL,
+		Email:       srv.Email,
+		APIToken:    srv.APIToken,
+		SpaceKey:    "ENG",
+		PreviousRef: previous,
+	})
+	if err != nil {
+		t.Fatalf("DetectDeletionsActivity() error = %v", err)
+	}
+	if out.Trashed != 1 || out.Missing != 2 {
+		t.Errorf("Trashed, Missing = %d, %d, want 1, 2", out.Trashed, out.Missing)
+	}
+
+	docs, err := transform.LoadDocuments(context.Background(), out.Ref)
+	if err != nil {
+		t.Fatalf("LoadDocuments() error = %v", err)
+	}
+	reasons := make(map[string]string)
+	for _, doc := range docs {
+		reasons[doc.ID] = doc.Metadata["deleted_reason"]
+	}
+	want := map[string]string{
+		trashed.ID: confluence.DeletedReasonTrashed,
+		"900":      confluence.DeletedReasonMissing,
+		"903":      confluence.DeletedReasonMissing,
+	}
+	if len(reasons) != len(want) {
+		t.Errorf("tombstones = %v, want %v", reasons, want)
+	}
+	for id, reason := range want {
+		if reasons[id] != reason {
+			t.Errorf("tombstone %s reason = %q, want %q", id, reasons[id], reason)
+		}
+	}
+}
+
+// snapshotDocument returns a Document of a prior sync for a page. An empty
+// contentType leaves the field out, as snapshots written before it did.
+func snapshotDocument(pageID, spaceKey, contentType string) transform.Document {
+	doc := transform.Document{
+		ID: pageID,
+		Metadata: map[string]string{
+			"page_id":   pageID,
+			"space_key": spaceKey,
+		},
+	}
+	if contentType != "" {
+		doc.Metadata["content_type"] = contentType
+	}
+	return doc
+}
+
+func TestDetectDeletionsActivityConcurrent(t *testing.T) {
+	srv := newServer(t)
+	srv.SetMaxPageSize(1)
+	var snapshot []transform.Document
+	for _, title := range []string{"Alpha", "Beta", "Gamma", "Delta"} {
+		page := srv.AddPage(confluencetest.NewPage("ENG", title, "<p>"+title+"</p>"))
+		snapshot = append(snapshot, snapshotDocument(page.ID, "ENG", "page"))
+	}
+	snapshot = append(snapshot, snapshotDocument("900", "ENG", "page"))
+	previous, err := transform.StoreDocuments(context.Background(), snapshot)
+	if err != nil {
+		t.Fatalf("StoreDocuments() error = %v", err)
+	}
+
+	out, err := confluence.DetectDeletionsActivity(context.Background(), confluence.DetectDeletionsInput{
+		BaseURL:     srv.URL,
+		Email:       srv.Email,
+		APIToken:    srv.APIToken,
+		SpaceKey:    "ENG",
+		PreviousRef: previous,
+		Concurrency: 3,
+	})
+	if err != nil {
+		t.Fatalf("DetectDeletionsActivity() error = %v", err)
+	}
+	if out.Missing != 1 {
+		t.Errorf("Missing = %d, want 1", out.Missing)
+	}
+}
@@ -0,0 +1,306 @@
+package confluence
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/resolute-sh/resolute/core"
+)
+
+// Section changes of a VersionDiff.
+const (
+	SectionAdded    = "added"
+	SectionRemoved  = "removed"
+	SectionModified = "modified"
+)
+
+// PageVersion is a version of a page with its content.
+type PageVersion struct {
+	Version
+	Content Page `json:"content"`
+}
+
+// GetPageVersion fetches a version of a page with its storage body.
+func (c *Client) GetPageVersion(ctx context.Context, pageID string, version int) (*PageVersion, error) {
+	endpoint := c.apiEndpoint(url.Values{"expand": {"content.body.storage,content.version"}},
+		"content", pageID, "version", strconv.Itoa(version))
+
+	var v PageVersion
+	if err := c.getJSON(ctx, endpoint, &v); err != nil {
+		return nil, err
+	}
+
+	return &v, nil
+}
+
+// VersionDiff is a structural diff of two versions of a page.
+type VersionDiff struct {
+	PageID string
+	From   int
+	To     int
+	// FromTitle and ToTitle are the page titles of the two versions.
+	FromTitle string
+	ToTitle   string
+	// Sections lists the changed sections in the order of the newer version,
+	// followed by the removed ones.
+	Sections []SectionDiff
+}
+
+// SectionDiff is the change of a section, the content under a heading. The
+// content before the first heading is the section with an empty Heading.
+type SectionDiff struct {
+	Heading string
+	// Change is SectionAdded, SectionRemoved, or SectionModified.
+	Change string
+	// Added and Removed list the blocks, such as paragraphs, list items, and
+	// table rows, added to or removed from the section.
+	Added   []string
+	Removed []string
+}
+
+// Changed reports whether the versions differ.
+func (d *VersionDiff) Changed() bool {
+	return d.FromTitle != d.ToTitle || len(d.Sections) > 0
+}
+
+// Summary describes the diff in a few lines of text, for change
+// notifications.
+func (d *VersionDiff) Summary() string {
+	var b strings.Builder
+	if d.FromTitle != d.ToTitle {
+		fmt.Fprintf(&b, "Title changed from %q to %q\n", d.FromTitle, d.ToTitle)
+	}
+	for _, s := range d.Sections {
+		heading := s.Heading
+		if heading == "" {
+			heading = "(introduction)"
+		}
+		switch s.Change {
+		case SectionModified:
+			fmt.Fprintf(&b, "Section %q modified: %d blocks added, %d removed\n", heading, len(s.Added), len(s.Removed))
+		default:
+			fmt.Fprintf(&b, "Section %q %s\n", heading, s.Change)
+		}
+	}
+	if b.Len() == 0 {
+		return "No changes"
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// DiffVersions fetches two versions of a page and diffs their sections.
+func (c *Client) DiffVersions(ctx context.Context, pageID string, v1, v2 int) (*VersionDiff, error) {
+	from, err := c.GetPageVersion(ctx, pageID, v1)
+	if err != nil {
+		return nil, fmt.Errorf("get version %d: %w", v1, err)
+	}
+	to, err := c.GetPageVersion(ctx, pageID, v2)
+	if err != nil {
+		return nil, fmt.Errorf("get version %d: %w", v2, err)
+	}
+
+	return &VersionDiff{
+		PageID:    pageID,
+		From:      v1,
+		To:        v2,
+		FromTitle: from.Content.Title,
+		ToTitle:   to.Content.Title,
+		Sections:  diffSections(splitSections(from.Content.Body.Storage.Value), splitSections(to.Content.Body.Storage.Value)),
+	}, nil
+}
+
+// section is the text blocks under a heading of a storage body.
+type section struct {
+	heading string
+	blocks  []string
+}
+
+var (
+	storageHeadingRegex = regexp.MustCompile(`(?s)<h[1-6]\b[^>]*>(.*?)</h[1-6]>`)
+	blockBoundRegex     = regexp.MustCompile(`</?(?:p|li|tr|th|td|pre|blockquote|div|ac:task|ac:layout-cell)\b[^>]*>`)
+)
+
+// splitSections splits a storage body into sections at its headings.
+func splitSections(storage string) []section {
+	var sections []section
+	add := func(heading, body string) {
+		var blocks []string
+		for _, part := range blockBoundRegex.Split(body, -1) {
+			if text := ConvertStorage(part, ConvertOptions{}).Text; text != "" {
+				blocks = append(blocks, text)
+			}
+		}
+		if heading != "" || len(blocks) > 0 {
+			sections = append(sections, section{heading: heading, blocks: blocks})
+		}
+	}
+
+	heading, start := "", 0
+	for _, m := range storageHeadingRegex.FindAllStringSubmatchIndex(storage, -1) {
+		add(heading, storage[start:m[0]])
+		heading = ConvertStorage(storage[m[2]:m[3]], ConvertOptions{}).Text
+		start = m[1]
+	}
+	add(heading, storage[start:])
+	return sections
+}
+
+// diffSections pairs the sections of two versions by heading, repeated
+// headings by order of appearance, and diffs the blocks of each pair.
+func diffSections(from, to []section) []SectionDiff {
+	type key struct {
+		heading string
+		n       int
+	}
+	keys := func(sections []section) []key {
+		seen := make(map[string]int)
+		out := make([]key, len(sections))
+		for i, s := range sections {
+			out[i] = key{s.heading, seen[s.heading]}
+			seen[s.heading]++
+		}
+		return out
+	}
+
+	fromKeys, toKeys := keys(from), keys(to)
+	old := make(map[key]section, len(from))
+	for i, s := range from {
+		old[fromKeys[i]] = s
+	}
+
+	var diffs []SectionDiff
+	matched := make(map[key]bool)
+	for i, s := range to {
+		k := toKeys[i]
+		prev, ok := old[k]
+		if !ok {
+			diffs = append(diffs, SectionDiff{Heading: s.heading, Change: SectionAdded, Added: s.blocks})
+			continue
+		}
+		matched[k] = true
+		added, removed := diffBlocks(prev.blocks, s.blocks)
+		if len(added) > 0 || len(removed) > 0 {
+			diffs = append(diffs, SectionDiff{Heading: s.heading, Change: SectionModified, Added: added, Removed: removed})
+		}
+	}
+	for i, s := range from {
+		if !matched[fromKeys[i]] {
+			diffs = append(diffs, SectionDiff{Heading: s.heading, Change: SectionRemoved, Removed: s.blocks})
+		}
+	}
+	return diffs
+}
+
+// diffBlocks returns the blocks of b not in the longest common subsequence
+// of a and b, and those of a not in it.
+func diffBlocks(a, b []string) (added, removed []string) {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			removed = append(removed, a[i])
+			i++
+		default:
+			added = append(added, b[j])
+			j++
+		}
+	}
+	removed = append(removed, a[i:]...)
+	added = append(added, b[j:]...)
+	return added, removed
+}
+
+// DiffVersionsInput is the input for DiffVersionsActivity.
+type DiffVersionsInput struct {
+	BaseURL  string `validate:"required,url"`
+	Email    string `validate:"required"`
+	APIToken string `validate:"required"`
+	Site     string
+	PageID   string `validate:"required"`
+
+	// ToVersion is the newer version. Defaults to the current version.
+	ToVersion int `validate:"min=0"`
+	// FromVersion is the older version. Defaults to the version before
+	// ToVersion.
+	FromVersion int `validate:"min=0"`
+}
+
+// DiffVersionsOutput is the output of DiffVersionsActivity.
+type DiffVersionsOutput struct {
+	Diff VersionDiff
+	// Summary is Diff.Summary.
+	Summary string
+	Changed bool
+}
+
+// DiffVersionsActivity diffs two versions of a page section by section, so
+// change notifications can summarize what changed.
+func DiffVersionsActivity(ctx context.Context, input DiffVersionsInput) (_ DiffVersionsOutput, err error) {
+	defer classifyError(&err)
+
+	if err := resolveCredentials(ctx, input.Site, &input.BaseURL, &input.Email, &input.APIToken); err != nil {
+		return DiffVersionsOutput{}, err
+	}
+	if err := validateInput(input); err != nil {
+		return DiffVersionsOutput{}, err
+	}
+
+	client := NewClient(ClientConfig{
+		BaseURL:  input.BaseURL,
+		Email:    input.Email,
+		APIToken: input.APIToken,
+	})
+
+	to := input.ToVersion
+	if to == 0 {
+		page, err := client.GetContent(ctx, input.PageID, []string{"version"})
+		if err != nil {
+			return DiffVersionsOutput{}, fmt.Errorf("get page: %w", err)
+		}
+		to = page.Version.Number
+	}
+	from := input.FromVersion
+	if from == 0 {
+		from = to - 1
+	}
+	if from < 1 {
+		return DiffVersionsOutput{}, invalidInputError(fmt.Errorf("page %s has no version before %d", input.PageID, to))
+	}
+
+	diff, err := client.DiffVersions(ctx, input.PageID, from, to)
+	if err != nil {
+		return DiffVersionsOutput{}, fmt.Errorf("diff versions: %w", err)
+	}
+
+	return DiffVersionsOutput{
+		Diff:    *diff,
+		Summary: diff.Summary(),
+		Changed: diff.Changed(),
+	}, nil
+}
+
+// DiffVersions creates a node for diffing two versions of a Confluence page.
+func DiffVersions(input DiffVersionsInput) *core.Node[DiffVersionsInput, DiffVersionsOutput] {
+	return withPolicy(core.NewNode("confluence.DiffVersions", DiffVersionsActivity, input))
+}
@@ -0,0 +1,167 @@
+package confluence
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// emoticonNames maps legacy ac:emoticon names to their Unicode equivalents.
+var emoticonNames = map[string]string{
+	"smile":        "🙂",
+	"sad":          "🙁",
+	"cheeky":       "😛",
+	"laugh":        "😀",
+	"wink":         "😉",
+	"thumbs-up":    "👍",
+	"thumbs-down":  "👎",
+	"information":  "ℹ️",
+	"tick":         "✅",
+	"cross":        "❌",
+	"warning":      "⚠️",
+	"plus":         "➕",
+	"minus":        "➖",
+	"question":     "❓",
+	"light-on":     "💡",
+	"light-off":    "💡",
+	"yellow-star":  "⭐",
+	"red-star":     "⭐",
+	"green-star":   "⭐",
+	"blue-star":    "⭐",
+	"heart":        "❤️",
+	"broken-heart": "💔",
+}
+
+// emojiShortnames maps common emoji shortnames, including the Atlassian
+// specific ones used by the editor, to their Unicode equivalents.
+var emojiShortnames = map[string]string{
+	":check_mark:":          "✅",
+	":cross_mark:":          "❌",
+	":info:":                "ℹ️",
+	":note:":                "📝",
+	":warning:":             "⚠️",
+	":white_check_mark:":    "✅",
+	":heavy_check_mark:":    "✔️",
+	":x:":                   "❌",
+	":thumbsup:":            "👍",
+	":+1:":                  "👍",
+	":thumbsdown:":          "👎",
+	":-1:":                  "👎",
+	":smile:":               "😄",
+	":slight_smile:":        "🙂",
+	":grinning:":            "😀",
+	":frowning:":            "🙁",
+	":slight_frown:":        "🙁",
+	":stuck_out_tongue:":    "😛",
+	":wink:":                "😉",
+	":question:":            "❓",
+	":exclamation:":         "❗",
+	":bulb:":                "💡",
+	":star:":                "⭐",
+	":heart:":               "❤️",
+	":broken_heart:":        "💔",
+	":heavy_plus_sign:":     "➕",
+	":heavy_minus_sign:":    "➖",
+	":red_circle:":          "🔴",
+	":yellow_circle:":       "🟡",
+	":green_circle:":        "🟢",
+	":large_blue_circle:":   "🔵",
+	":no_entry:":            "⛔",
+	":no_entry_sign:":       "🚫",
+	":construction:":        "🚧",
+	":hourglass:":           "⌛",
+	":calendar:":            "📅",
+	":lock:":                "🔒",
+	":memo:":                "📝",
+	":pushpin:":             "📌",
+	":link:":                "🔗",
+	":rocket:":              "🚀",
+	":fire:":                "🔥",
+	":tada:":                "🎉",
+	":eyes:":                "👀",
+	":arrow_right:":         "➡️",
+	":arrow_up:":            "⬆️",
+	":arrow_down:":          "⬇️",
+	":stop_sign:":           "🛑",
+	":white_circle:":        "⚪",
+	":black_circle:":        "⚫",
+	":large_orange_circle:": "🟠",
+}
+
+var (
+	emoticonRegex      = regexp.MustCompile(`<ac:emoticon\b[^>]*>`)
+	emoticonCloseRegex = regexp.MustCompile(`</ac:emoticon>`)
+	emoticonAttrRegex  = regexp.MustCompile(`([\w:-]+)="([^"]*)"`)
+	shortnameRegex     = regexp.MustCompile(`(^|[^\w:])(:[a-z0-9_+-]+:)`)
+)
+
+// replaceEmoticons replaces ac:emoticon elements with their Unicode
+// equivalents. Emoticons that cannot be resolved fall back to their
+// shortname so the intent survives extraction.
+func replaceEmoticons(storage string) string {
+	storage = emoticonRegex.ReplaceAllStringFunc(storage, func(tag string) string {
+		attrs := make(map[string]string)
+		for _, m := range emoticonAttrRegex.FindAllStringSubmatch(tag, -1) {
+			attrs[m[1]] = m[2]
+		}
+		return resolveEmoticon(attrs)
+	})
+	return emoticonCloseRegex.ReplaceAllString(storage, "")
+}
+
+func resolveEmoticon(attrs map[string]string) string {
+	fallback := attrs["ac:emoji-fallback"]
+	if fallback != "" && !strings.HasPrefix(fallback, ":") {
+		return fallback
+	}
+	if emoji, ok := emojiFromID(attrs["ac:emoji-id"]); ok {
+		return emoji
+	}
+
+	shortname := attrs["ac:emoji-shortname"]
+	if emoji, ok := emojiShortnames[shortname]; ok {
+		return emoji
+	}
+	if emoji, ok := emojiShortnames[fallback]; ok {
+		return emoji
+	}
+	if emoji, ok := emoticonNames[attrs["ac:name"]]; ok {
+		return emoji
+	}
+
+	if shortname != "" {
+		return shortname
+	}
+	return fallback
+}
+
+// emojiFromID decodes emoji IDs made of hyphen-separated hex code points,
+// such as "1f600" or "1f1fa-1f1f8".
+func emojiFromID(id string) (string, bool) {
+	if id == "" {
+		return "", false
+	}
+
+	var b strings.Builder
+	for _, part := range strings.Split(id, "-") {
+		cp, err := strconv.ParseUint(part, 16, 32)
+		if err != nil {
+			return "", false
+		}
+		b.WriteRune(rune(cp))
+	}
+	return b.String(), true
+}
+
+// replaceShortnames replaces known emoji shortnames in extracted text. A
+// shortname must start the text or follow a character other than a letter,
+// digit, or colon, so paths such as std::link::Path in code are kept.
+func replaceShortnames(text string) string {
+	return shortnameRegex.ReplaceAllStringFunc(text, func(match string) string {
+		m := shortnameRegex.FindStringSubmatch(match)
+		if emoji, ok := emojiShortnames[m[2]]; ok {
+			return m[1] + emoji
+		}
+		return match
+	})
+}
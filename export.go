@@ -0,0 +1,210 @@
+package confluence
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/resolute-sh/resolute-confluence/cql"
+	"github.com/resolute-sh/resolute/core"
+)
+
+// SchemaSpaceExport is the DataRef schema for stored SpaceExport archives.
+const SchemaSpaceExport = "confluence.SpaceExport"
+
+// SpaceExport is a structured archive of a space.
+type SpaceExport struct {
+	Space       Space                `json:"space"`
+	ExportedAt  time.Time            `json:"exported_at"`
+	Pages       []ExportedContent    `json:"pages"`
+	BlogPosts   []ExportedContent    `json:"blog_posts"`
+	Attachments []ExportedAttachment `json:"attachments"`
+}
+
+// ExportedContent is a page or blog post in a SpaceExport.
+type ExportedContent struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	Title      string    `json:"title"`
+	ParentID   string    `json:"parent_id,omitempty"`
+	Version    int       `json:"version"`
+	ModifiedAt time.Time `json:"modified_at"`
+	URL        string    `json:"url"`
+	Body       string    `json:"body"`
+}
+
+// ExportedAttachment is the metadata of an attachment in a SpaceExport.
+type ExportedAttachment struct {
+	ID          string `json:"id"`
+	ContentID   string `json:"content_id"`
+	Title       string `json:"title"`
+	MediaType   string `json:"media_type"`
+	FileSize    int64  `json:"file_size"`
+	Version     int    `json:"version"`
+	DownloadURL string `json:"download_url"`
+}
+
+// ExportSpaceInput is the input for ExportSpaceActivity.
+type ExportSpaceInput struct {
+	BaseURL  string `validate:"required,url"`
+	Email    string `validate:"required"`
+	APIToken string `validate:"required"`
+	Site     string
+	SpaceKey string `validate:"required"`
+
+	// IncludeAttachments adds attachment metadata for every page and blog post.
+	IncludeAttachments bool
+
+	// Limit is the number of items requested per API call, up to the
+	// API maximum of 250. Defaults to 250.
+	Limit int `validate:"min=0"`
+
+	// Concurrency is the number of API pages of each content listing
+	// requested at once. Values of 0 or 1 list the space sequentially.
+	Concurrency int `validate:"min=0"`
+}
+
+// ExportSpaceOutput is the output of ExportSpaceActivity.
+type ExportSpaceOutput struct {
+	Ref         core.DataRef
+	Pages       int
+	BlogPosts   int
+	Attachments int
+}
+
+// ExportSpaceActivity exports the pages, blog posts, hierarchy, and
+// attachment metadata of a space into a SpaceExport stored behind a DataRef.
+// Bodies are kept in storage format so the archive can be restored.
+func ExportSpaceActivity(ctx context.Context, input ExportSpaceInput) (_ ExportSpaceOutput, err error) {
+	defer classifyError(&err)
+
+	if err := resolveCredentials(ctx, input.Site, &input.BaseURL, &input.Email, &input.APIToken); err != nil {
+		return ExportSpaceOutput{}, err
+	}
+	if err := validateInput(input); err != nil {
+		return ExportSpaceOutput{}, err
+	}
+
+	client := NewClient(ClientConfig{
+		BaseURL:  input.BaseURL,
+		Email:    input.Email,
+		APIToken: input.APIToken,
+	})
+
+	limit := pageSize(ctx, input.Limit)
+
+	space, err := client.GetSpace(ctx, input.SpaceKey)
+	if err != nil {
+		return ExportSpaceOutput{}, fmt.Errorf("get space: %w", err)
+	}
+
+	export := SpaceExport{
+		Space:      *space,
+		ExportedAt: time.Now().UTC(),
+	}
+
+	for _, contentType := range []string{"page", "blogpost"} {
+		query := ContentQuery{
+			SpaceKey: input.SpaceKey,
+			Type:     contentType,
+			Expand:   []string{"body.storage", "version", "ancestors"},
+		}
+		total := 0
+		if input.Concurrency > 1 {
+			total, err = client.CountCQL(ctx, cql.Space(input.SpaceKey).And(cql.Type(contentType)).String())
+			if err != nil {
+				return ExportSpaceOutput{}, fmt.Errorf("count %s content: %w", contentType, err)
+			}
+		}
+		items, err := listAllPagesConcurrently(ctx, limit, total, input.Concurrency, func(ctx context.Context, start, limit int) (*PageList, error) {
+			return client.ListContent(ctx, query, start, limit)
+		})
+		if err != nil {
+			return ExportSpaceOutput{}, fmt.Errorf("list %s content: %w", contentType, err)
+		}
+
+		for _, item := range items {
+			exported := ExportedContent{
+				ID:         item.ID,
+				Type:       contentType,
+				Title:      item.Title,
+				Version:    item.Version.Number,
+				ModifiedAt: item.Version.ModifiedAt(),
+				URL:        webURL(input.BaseURL, item.Links.WebUI),
+				Body:       item.Body.Storage.Value,
+			}
+			if n := len(item.Ancestors); n > 0 {
+				exported.ParentID = item.Ancestors[n-1].ID
+			}
+
+			if contentType == "page" {
+				export.Pages = append(export.Pages, exported)
+			} else {
+				export.BlogPosts = append(export.BlogPosts, exported)
+			}
+			recordHeartbeat(ctx, len(export.Pages)+len(export.BlogPosts))
+
+			if !input.IncludeAttachments {
+				continue
+			}
+
+			attachments, err := listAllAttachments(ctx, client, item.ID, limit)
+			if err != nil {
+				return ExportSpaceOutput{}, fmt.Errorf("list attachments of %s: %w", item.ID, err)
+			}
+			for _, a := range attachments {
+				export.Attachments = append(export.Attachments, ExportedAttachment{
+					ID:          a.ID,
+					ContentID:   item.ID,
+					Title:       a.Title,
+					MediaType:   a.Extensions.MediaType,
+					FileSize:    a.Extensions.FileSize,
+					Version:     a.Version.Number,
+					DownloadURL: webURL(input.BaseURL, a.Links.Download),
+				})
+			}
+		}
+	}
+
+	storage, err := core.GetStorage()
+	if err != nil {
+		return ExportSpaceOutput{}, fmt.Errorf("get storage: %w", err)
+	}
+
+	ref, err := storage.StoreJSON(ctx, SchemaSpaceExport, export)
+	if err != nil {
+		return ExportSpaceOutput{}, fmt.Errorf("store export: %w", err)
+	}
+	ref.Count = len(export.Pages) + len(export.BlogPosts)
+
+	return ExportSpaceOutput{
+		Ref:         ref,
+		Pages:       len(export.Pages),
+		BlogPosts:   len(export.BlogPosts),
+		Attachments: len(export.Attachments),
+	}, nil
+}
+
+// LoadSpaceExport loads a SpaceExport from a DataRef.
+func LoadSpaceExport(ctx context.Context, ref core.DataRef) (*SpaceExport, error) {
+	if ref.Schema != SchemaSpaceExport {
+		return nil, fmt.Errorf("schema mismatch: expected %s, got %s", SchemaSpaceExport, ref.Schema)
+	}
+
+	storage, err := core.GetStorage()
+	if err != nil {
+		return nil, fmt.Errorf("get storage: %w", err)
+	}
+
+	var export SpaceExport
+	if err := storage.LoadJSON(ctx, ref, &export); err != nil {
+		return nil, fmt.Errorf("load space export: %w", err)
+	}
+
+	return &export, nil
+}
+
+// ExportSpace creates a node for exporting a Confluence space.
+func ExportSpace(input ExportSpaceInput) *core.Node[ExportSpaceInput, ExportSpaceOutput] {
+	return withPolicy(core.NewNode("confluence.ExportSpace", ExportSpaceActivity, input))
+}
@@ -0,0 +1,195 @@
+package confluence
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/resolute-sh/resolute-confluence/cql"
+	transform "github.com/resolute-sh/resolute-transform"
+	"github.com/resolute-sh/resolute/core"
+)
+
+// Changes reported in the "change" metadata field of RecentlyUpdated
+// Documents.
+const (
+	ChangeCreated = "created"
+	ChangeUpdated = "updated"
+)
+
+// recentlyUpdatedExpand lists the properties expanded on recently updated
+// content: enough to describe each change without fetching bodies.
+var recentlyUpdatedExpand = []string{"space", "version", "version.by", "history", "ancestors"}
+
+// RecentlyUpdatedInput is the input for RecentlyUpdatedActivity.
+type RecentlyUpdatedInput struct {
+	BaseURL  string `validate:"required,url"`
+	Email    string `validate:"required"`
+	APIToken string `validate:"required"`
+	Site     string
+
+	// SpaceKeys restricts the feed to these spaces. Empty covers the whole
+	// site.
+	SpaceKeys []string
+	// Types restricts the feed to these content types. Defaults to pages
+	// and blog posts.
+	Types []string
+	// Days is the length of the feed window, ending now. Defaults to 7.
+	Days int `validate:"min=0"`
+	// IncludeArchivedSpaces covers archived spaces too.
+	IncludeArchivedSpaces bool
+
+	// MaxResults caps the number of changes, keeping the most recent. Zero
+	// returns every change in the window.
+	MaxResults int `validate:"min=0"`
+
+	// Limit is the number of items requested per API call, up to the
+	// API maximum of 250. Defaults to 250.
+	Limit int `validate:"min=0"`
+
+	// Source is the Source of the stored Documents, which tells apart the
+	// Documents of several sites. Defaults to the hostname of BaseURL.
+	Source string
+}
+
+// RecentlyUpdatedOutput is the output of RecentlyUpdatedActivity.
+type RecentlyUpdatedOutput struct {
+	Ref   core.DataRef
+	Count int
+	// Since is the start of the feed window.
+	Since time.Time
+}
+
+// RecentlyUpdatedActivity stores the content created or updated in the last
+// Days days, newest first, as compact change Documents for digests and
+// newsletters. Each Document describes one change in a sentence, such as
+// `"Runbook" updated by Ada Lovelace in Operations (version 4): fix typo`,
+// and carries the usual page metadata plus "change", either ChangeCreated or
+// ChangeUpdated. Bodies are not fetched.
+func RecentlyUpdatedActivity(ctx context.Context, input RecentlyUpdatedInput) (_ RecentlyUpdatedOutput, err error) {
+	defer classifyError(&err)
+
+	if err := resolveCredentials(ctx, input.Site, &input.BaseURL, &input.Email, &input.APIToken); err != nil {
+		return RecentlyUpdatedOutput{}, err
+	}
+	if err := validateInput(input); err != nil {
+		return RecentlyUpdatedOutput{}, err
+	}
+
+	client := NewClient(ClientConfig{
+		BaseURL:  input.BaseURL,
+		Email:    input.Email,
+		APIToken: input.APIToken,
+	})
+
+	source := documentSource(input.BaseURL, input.Source)
+	limit := pageSize(ctx, input.Limit)
+	days := input.Days
+	if days == 0 {
+		days = 7
+	}
+	since := time.Now().UTC().AddDate(0, 0, -days)
+
+	query := recentlyUpdatedCQL(input.SpaceKeys, input.Types, since)
+	opts := SearchOptions{
+		Expand:                recentlyUpdatedExpand,
+		IncludeArchivedSpaces: input.IncludeArchivedSpaces,
+	}
+
+	var docs []transform.Document
+	start := 0
+	for {
+		if input.MaxResults > 0 {
+			limit = min(limit, input.MaxResults-len(docs))
+		}
+		list, err := client.SearchContentWithOptions(ctx, query, start, limit, opts)
+		if err != nil {
+			return RecentlyUpdatedOutput{}, fmt.Errorf("search recently updated content at %d: %w", start, err)
+		}
+
+		// Results are newest first, so the first one before the window
+		// ends the feed.
+		done := false
+		for _, page := range list.Results {
+			if page.Version.ModifiedAt().Before(since) {
+				done = true
+				break
+			}
+			docs = append(docs, changeDocument(page, input.BaseURL, source, since))
+		}
+		recordHeartbeat(ctx, len(docs))
+
+		start += len(list.Results)
+		if done || !list.HasMore() || len(list.Results) == 0 {
+			break
+		}
+		if input.MaxResults > 0 && len(docs) >= input.MaxResults {
+			break
+		}
+	}
+
+	ref, err := transform.StoreDocuments(ctx, docs)
+	if err != nil {
+		return RecentlyUpdatedOutput{}, fmt.Errorf("store documents: %w", err)
+	}
+
+	return RecentlyUpdatedOutput{
+		Ref:   ref,
+		Count: len(docs),
+		Since: since,
+	}, nil
+}
+
+// recentlyUpdatedCQL builds the query of the feed, newest first. CQL only
+// compares dates to the minute in the caller's time zone, so the window is
+// widened by a day and trimmed client-side.
+func recentlyUpdatedCQL(spaceKeys, types []string, since time.Time) string {
+	if len(types) == 0 {
+		types = []string{cql.TypePage, cql.TypeBlogPost}
+	}
+	query := cql.TypeIn(types...).And(cql.LastModifiedAfter(since.Add(-24 * time.Hour)))
+	if len(spaceKeys) > 0 {
+		query = query.And(cql.SpaceIn(spaceKeys...))
+	}
+	return query.OrderBy(cql.FieldLastModified, cql.Descending).String()
+}
+
+// changeDocument converts content modified since a time into a Document
+// describing the change.
+func changeDocument(page Page, baseURL, source string, since time.Time) transform.Document {
+	doc := pageToDocument(page, baseURL, source, ConvertOptions{})
+
+	change := ChangeUpdated
+	if page.History != nil && !page.History.CreatedAt().Before(since) {
+		change = ChangeCreated
+	}
+	doc.Metadata["change"] = change
+	doc.Content = describeChange(page, change)
+	return redactDocument(doc)
+}
+
+// describeChange summarizes a change in one sentence.
+func describeChange(page Page, change string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%q %s", page.Title, change)
+	if name := anonymizeUser(page.Version.By).DisplayName; name != "" {
+		fmt.Fprintf(&b, " by %s", name)
+	}
+	if page.Space.Name != "" {
+		fmt.Fprintf(&b, " in %s", page.Space.Name)
+	}
+	if change == ChangeUpdated && page.Version.Number > 0 {
+		fmt.Fprintf(&b, " (version %d)", page.Version.Number)
+	}
+	if message := strings.TrimSpace(page.Version.Message); message != "" {
+		fmt.Fprintf(&b, ": %s", message)
+	}
+	return b.String()
+}
+
+// RecentlyUpdated creates a node for fetching the recently updated content
+// of Confluence spaces.
+func RecentlyUpdated(input RecentlyUpdatedInput) *core.Node[RecentlyUpdatedInput, RecentlyUpdatedOutput] {
+	return withPolicy(core.NewNode("confluence.RecentlyUpdated", RecentlyUpdatedActivity, input))
+}
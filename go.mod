@@ -7,7 +7,9 @@ toolchain go1.24.4
 require (
 	github.com/resolute-sh/resolute v0.1.0-alpha
 	github.com/resolute-sh/resolute-transform v0.1.0-alpha
+	go.temporal.io/api v1.38.0
 	go.temporal.io/sdk v1.29.1
+	golang.org/x/sync v0.16.0
 )
 
 require (
@@ -31,11 +33,9 @@ require (
 	github.com/robfig/cron v1.2.0 // indirect
 	github.com/stretchr/objx v0.5.2 // indirect
 	github.com/stretchr/testify v1.11.1 // indirect
-	go.temporal.io/api v1.38.0 // indirect
 	go.yaml.in/yaml/v2 v2.4.2 // indirect
 	golang.org/x/exp v0.0.0-20231127185646-65229373498e // indirect
 	golang.org/x/net v0.43.0 // indirect
-	golang.org/x/sync v0.16.0 // indirect
 	golang.org/x/sys v0.35.0 // indirect
 	golang.org/x/text v0.28.0 // indirect
 	golang.org/x/time v0.3.0 // indirect
@@ -0,0 +1,126 @@
+package confluence
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/resolute-sh/resolute/core"
+)
+
+// Label represents a Confluence content label.
+type Label struct {
+	Prefix string `json:"prefix"`
+	Name   string `json:"name"`
+}
+
+// AddLabels adds global labels to a page.
+func (c *Client) AddLabels(ctx context.Context, pageID string, names []string) error {
+	labels := make([]Label, 0, len(names))
+	for _, name := range names {
+		labels = append(labels, Label{Prefix: "global", Name: name})
+	}
+
+	endpoint := c.apiEndpoint(nil, "content", pageID, "label")
+	return c.doJSON(ctx, http.MethodPost, endpoint, labels, nil)
+}
+
+// RemoveLabel removes a label from a page.
+func (c *Client) RemoveLabel(ctx context.Context, pageID, name string) error {
+	endpoint := c.apiEndpoint(url.Values{"name": {name}}, "content", pageID, "label")
+	return c.doJSON(ctx, http.MethodDelete, endpoint, nil, nil)
+}
+
+// AddLabelsInput is the input for AddLabelsActivity.
+type AddLabelsInput struct {
+	BaseURL  string `validate:"required,url"`
+	Email    string `validate:"required"`
+	APIToken string `validate:"required"`
+	Site     string
+	PageIDs  []string `validate:"minlen=1"`
+
+	// Add lists the labels to add to every page.
+	Add []string
+	// Remove lists the labels to remove from every page.
+	Remove []string
+}
+
+// AddLabelsOutput is the output of AddLabelsActivity.
+type AddLabelsOutput struct {
+	Updated []string
+	Errors  []ItemError
+}
+
+// AddLabelsActivity adds and removes labels on a set of pages, reporting
+// failures per page instead of failing the activity.
+func AddLabelsActivity(ctx context.Context, input AddLabelsInput) (_ AddLabelsOutput, err error) {
+	defer classifyError(&err)
+
+	if err := resolveCredentials(ctx, input.Site, &input.BaseURL, &input.Email, &input.APIToken); err != nil {
+		return AddLabelsOutput{}, err
+	}
+	if err := validateInput(input); err != nil {
+		return AddLabelsOutput{}, err
+	}
+
+	client := NewClient(ClientConfig{
+		BaseURL:  input.BaseURL,
+		Email:    input.Email,
+		APIToken: input.APIToken,
+	})
+
+	add := make([]string, 0, len(input.Add))
+	for _, label := range input.Add {
+		add = append(add, labelName(label))
+	}
+
+	var output AddLabelsOutput
+	for i, pageID := range input.PageIDs {
+		if err := updateLabels(ctx, client, pageID, add, input.Remove); err != nil {
+			output.Errors = append(output.Errors, newItemError(pageID, err))
+		} else {
+			output.Updated = append(output.Updated, pageID)
+		}
+		recordHeartbeat(ctx, i+1)
+	}
+
+	return output, nil
+}
+
+func updateLabels(ctx context.Context, client *Client, pageID string, add, remove []string) error {
+	if len(add) > 0 {
+		if err := client.AddLabels(ctx, pageID, add); err != nil {
+			return fmt.Errorf("add labels: %w", err)
+		}
+	}
+	for _, label := range remove {
+		err := client.RemoveLabel(ctx, pageID, labelName(label))
+		if err != nil && !hasStatus(err, http.StatusNotFound) {
+			return fmt.Errorf("remove label %s: %w", label, err)
+		}
+	}
+	return nil
+}
+
+// AddLabels creates a node for adding and removing labels on Confluence pages.
+func AddLabels(input AddLabelsInput) *core.Node[AddLabelsInput, AddLabelsOutput] {
+	return withPolicy(core.NewNode("confluence.AddLabels", AddLabelsActivity, input))
+}
+
+// labelName normalizes a value into a valid label name: lowercase, with
+// whitespace and characters Confluence rejects replaced by hyphens.
+func labelName(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == ' ' || r == '\t' || r == '\n':
+			return '-'
+		case strings.ContainsRune(":;,.?&[]()#^*@!", r):
+			return '-'
+		default:
+			return r
+		}
+	}, value)
+}
@@ -0,0 +1,45 @@
+package confluence_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/resolute-sh/resolute-confluence"
+	"github.com/resolute-sh/resolute-confluence/confluencetest"
+)
+
+func TestAddLabelsActivity(t *testing.T) {
+	srv := newServer(t)
+	labeled := confluencetest.NewPage("ENG", "Labeled", "<p>labeled</p>")
+	labeled.Metadata = &confluence.ContentMetadata{Labels: confluence.LabelList{
+		Results: []confluence.Label{{Prefix: "global", Name: "draft"}},
+	}}
+	labeled = srv.AddPage(labeled)
+	unlabeled := srv.AddPage(confluencetest.NewPage("ENG", "Unlabeled", "<p>unlabeled</p>"))
+	restricted := srv.AddPage(confluencetest.NewPage("ENG", "Restricted", "<p>restricted</p>"))
+	srv.Restrict(restricted.ID)
+
+	out, err := confluence.AddLabelsActivity(context.Background(), confluence.AddLabelsInput{
+		BaseURL:  srv.URL,
+		Email:    srv.Email,
+		APIToken: srv.APIToken,
+		PageIDs:  []string{labeled.ID, restricted.ID, unlabeled.ID},
+		Add:      []string{"Indexed"},
+		Remove:   []string{"draft"},
+	})
+	if err != nil {
+		t.Fatalf("AddLabelsActivity() error = %v", err)
+	}
+	if want := []string{labeled.ID, unlabeled.ID}; !slices.Equal(out.Updated, want) {
+		t.Errorf("Updated = %v, want %v", out.Updated, want)
+	}
+	if len(out.Errors) != 1 || out.Errors[0].ID != restricted.ID {
+		t.Errorf("Errors = %+v, want one for page %s", out.Errors, restricted.ID)
+	}
+	for _, id := range out.Updated {
+		if page, _ := srv.Page(id); !slices.Equal(page.LabelNames(), []string{"indexed"}) {
+			t.Errorf("page %s labels = %v, want [indexed]", id, page.LabelNames())
+		}
+	}
+}
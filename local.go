@@ -0,0 +1,55 @@
+package confluence
+
+import (
+	"go.temporal.io/sdk/workflow"
+)
+
+// localActivityNames maps the names Temporal gives the local activities,
+// those of their functions, to the names the functions are registered
+// under, so local runs get the same defaults and policy as regular ones.
+var localActivityNames = map[string]string{
+	"FetchPageActivity":          "confluence.FetchPage",
+	"ValidateConnectionActivity": "confluence.ValidateConnection",
+	"AddLabelsActivity":          "confluence.AddLabels",
+}
+
+// LocalActivityOptions returns the policy as Temporal local activity options.
+// Heartbeat timeouts do not apply to local activities and are dropped.
+func (p ActivityPolicy) LocalActivityOptions() workflow.LocalActivityOptions {
+	return workflow.LocalActivityOptions{
+		StartToCloseTimeout: p.StartToCloseTimeout,
+		RetryPolicy:         p.temporalRetryPolicy(),
+	}
+}
+
+// FetchPageLocal runs FetchPageActivity as a local activity, skipping the
+// scheduling round trip of a regular activity. Use it from workflows that
+// make many small calls; local activities need no worker registration.
+func FetchPageLocal(ctx workflow.Context, input FetchPageInput) (FetchPageOutput, error) {
+	var output FetchPageOutput
+	err := executeLocal(ctx, "confluence.FetchPage", FetchPageActivity, input, &output)
+	return output, err
+}
+
+// ValidateConnectionLocal runs ValidateConnectionActivity as a local
+// activity.
+func ValidateConnectionLocal(ctx workflow.Context, input ValidateConnectionInput) (ValidateConnectionOutput, error) {
+	var output ValidateConnectionOutput
+	err := executeLocal(ctx, "confluence.ValidateConnection", ValidateConnectionActivity, input, &output)
+	return output, err
+}
+
+// AddLabelsLocal runs AddLabelsActivity as a local activity. It suits a few
+// pages; label many pages with the regular activity, which heartbeats.
+func AddLabelsLocal(ctx workflow.Context, input AddLabelsInput) (AddLabelsOutput, error) {
+	var output AddLabelsOutput
+	err := executeLocal(ctx, "confluence.AddLabels", AddLabelsActivity, input, &output)
+	return output, err
+}
+
+// executeLocal runs fn, registered as name, as a local activity with the
+// policy of name.
+func executeLocal(ctx workflow.Context, name string, fn, input, output any) error {
+	ctx = workflow.WithLocalActivityOptions(ctx, Policy(name).LocalActivityOptions())
+	return workflow.ExecuteLocalActivity(ctx, fn, input).Get(ctx, output)
+}
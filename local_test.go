@@ -0,0 +1,56 @@
+package confluence
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+)
+
+func TestLocalActivityNames(t *testing.T) {
+	functions := make(map[string]string)
+	for _, r := range registrations {
+		name := runtime.FuncForPC(reflect.ValueOf(r.fn).Pointer()).Name()
+		functions[r.name] = name[strings.LastIndex(name, ".")+1:]
+	}
+	for function, name := range localActivityNames {
+		if functions[name] != function {
+			t.Errorf("%s is registered with function %q, not %q", name, functions[name], function)
+		}
+	}
+}
+
+// probeLimitActivity returns the Limit of the activity defaults it runs
+// with.
+func probeLimitActivity(ctx context.Context, _ string) (int, error) {
+	return activityDefaults(ctx).Limit, nil
+}
+
+func TestLocalActivityDefaults(t *testing.T) {
+	localActivityNames["probeLimitActivity"] = "confluence.Probe"
+	t.Cleanup(func() { delete(localActivityNames, "probeLimitActivity") })
+	SetActivityDefaults("confluence.Probe", ActivityDefaults{Limit: 7})
+	t.Cleanup(func() { setActivityDefaultsByName(nil) })
+
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	env.ExecuteWorkflow(func(ctx workflow.Context) (int, error) {
+		var limit int
+		err := executeLocal(ctx, "confluence.Probe", probeLimitActivity, "", &limit)
+		return limit, err
+	})
+	if err := env.GetWorkflowError(); err != nil {
+		t.Fatalf("workflow error = %v", err)
+	}
+	var limit int
+	if err := env.GetWorkflowResult(&limit); err != nil {
+		t.Fatalf("GetWorkflowResult() error = %v", err)
+	}
+	if limit != 7 {
+		t.Errorf("Limit = %d, want the default of 7", limit)
+	}
+}
@@ -0,0 +1,269 @@
+package confluence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	transform "github.com/resolute-sh/resolute-transform"
+	"github.com/resolute-sh/resolute/core"
+)
+
+// Body formats accepted by write activities.
+const (
+	BodyFormatStorage  = "storage"
+	BodyFormatMarkdown = "markdown"
+)
+
+// toStorage converts a body in the given format to storage format.
+func toStorage(body, format string) string {
+	if format == BodyFormatMarkdown {
+		return MarkdownToStorage(body)
+	}
+	return body
+}
+
+var (
+	headingRegex     = regexp.MustCompile(`^(#{1,6})\s+(.*?)(?:\s+#+)?\s*$`)
+	unorderedRegex   = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	orderedRegex     = regexp.MustCompile(`^\s*\d+[.)]\s+(.*)$`)
+	ruleRegex        = regexp.MustCompile(`^(?:-\s*){3,}$|^(?:\*\s*){3,}$|^(?:_\s*){3,}$`)
+	fenceRegex       = regexp.MustCompile("^\\s*(```|~~~)\\s*([\\w+-]*)")
+	codeSpanRegex    = regexp.MustCompile("`([^`]+)`")
+	linkRegex        = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	boldRegex        = regexp.MustCompile(`(\*\*|__)(.+?)(\*\*|__)`)
+	italicStarRegex  = regexp.MustCompile(`\*([^*]+)\*`)
+	italicUnderRegex = regexp.MustCompile(`\b_([^_]+)_\b`)
+	tableSepRegex    = regexp.MustCompile(`^\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?$`)
+)
+
+// MarkdownToStorage converts Markdown to Confluence storage format. It
+// supports headings, paragraphs, lists, block quotes, rules, fenced code
+// blocks, pipe tables, and inline emphasis, code, and links.
+func MarkdownToStorage(markdown string) string {
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+
+	var b strings.Builder
+	var paragraph []string
+	listTag := ""
+
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			b.WriteString("<p>" + renderInline(strings.Join(paragraph, " ")) + "</p>")
+			paragraph = nil
+		}
+	}
+	closeList := func() {
+		if listTag != "" {
+			b.WriteString("</" + listTag + ">")
+			listTag = ""
+		}
+	}
+	openList := func(tag string) {
+		if listTag != tag {
+			closeList()
+			b.WriteString("<" + tag + ">")
+			listTag = tag
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if m := fenceRegex.FindStringSubmatch(line); m != nil {
+			flushParagraph()
+			closeList()
+
+			var code []string
+			for i++; i < len(lines); i++ {
+				if strings.HasPrefix(strings.TrimSpace(lines[i]), m[1]) {
+					break
+				}
+				code = append(code, lines[i])
+			}
+			b.WriteString(codeMacro(m[2], strings.Join(code, "\n")))
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			flushParagraph()
+			closeList()
+
+		case headingRegex.MatchString(trimmed):
+			flushParagraph()
+			closeList()
+			m := headingRegex.FindStringSubmatch(trimmed)
+			level := string(rune('0' + len(m[1])))
+			b.WriteString("<h" + level + ">" + renderInline(m[2]) + "</h" + level + ">")
+
+		case ruleRegex.MatchString(trimmed):
+			flushParagraph()
+			closeList()
+			b.WriteString("<hr />")
+
+		case unorderedRegex.MatchString(line):
+			flushParagraph()
+			openList("ul")
+			b.WriteString("<li>" + renderInline(unorderedRegex.FindStringSubmatch(line)[1]) + "</li>")
+
+		case orderedRegex.MatchString(line):
+			flushParagraph()
+			openList("ol")
+			b.WriteString("<li>" + renderInline(orderedRegex.FindStringSubmatch(line)[1]) + "</li>")
+
+		case strings.Contains(trimmed, "|") && i+1 < len(lines) && tableSepRegex.MatchString(strings.TrimSpace(lines[i+1])):
+			flushParagraph()
+			closeList()
+			b.WriteString("<table><tbody>")
+			b.WriteString(tableRow("th", trimmed))
+			for i += 2; i < len(lines); i++ {
+				t := strings.TrimSpace(lines[i])
+				if t == "" || !strings.Contains(t, "|") {
+					i--
+					break
+				}
+				b.WriteString(tableRow("td", t))
+			}
+			b.WriteString("</tbody></table>")
+
+		case strings.HasPrefix(trimmed, ">"):
+			flushParagraph()
+			closeList()
+			var quote []string
+			for ; i < len(lines); i++ {
+				t := strings.TrimSpace(lines[i])
+				if !strings.HasPrefix(t, ">") {
+					i--
+					break
+				}
+				quote = append(quote, strings.TrimSpace(strings.TrimPrefix(t, ">")))
+			}
+			b.WriteString("<blockquote><p>" + renderInline(strings.Join(quote, " ")) + "</p></blockquote>")
+
+		default:
+			closeList()
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+
+	flushParagraph()
+	closeList()
+
+	return b.String()
+}
+
+// codeMacro renders a code block as a Confluence code macro.
+func codeMacro(language, code string) string {
+	var b strings.Builder
+	b.WriteString(`<ac:structured-macro ac:name="code">`)
+	if language != "" {
+		b.WriteString(`<ac:parameter ac:name="language">` + html.EscapeString(language) + `</ac:parameter>`)
+	}
+	b.WriteString(`<ac:plain-text-body><![CDATA[`)
+	b.WriteString(strings.ReplaceAll(code, "]]>", "]]]]><![CDATA[>"))
+	b.WriteString(`]]></ac:plain-text-body></ac:structured-macro>`)
+	return b.String()
+}
+
+// tableRow renders a pipe table row with cells of the given tag. Escaped
+// pipes are kept as cell content.
+func tableRow(cell, row string) string {
+	row = strings.TrimPrefix(strings.TrimSuffix(row, "|"), "|")
+	row = strings.ReplaceAll(row, `\|`, "\x01")
+
+	var b strings.Builder
+	b.WriteString("<tr>")
+	for _, value := range strings.Split(row, "|") {
+		value = strings.ReplaceAll(value, "\x01", "|")
+		b.WriteString("<" + cell + ">" + renderInline(strings.TrimSpace(value)) + "</" + cell + ">")
+	}
+	b.WriteString("</tr>")
+	return b.String()
+}
+
+// renderInline escapes text and converts inline Markdown to storage markup.
+// Code spans are rendered first and protected from further formatting.
+func renderInline(text string) string {
+	var spans []string
+	text = codeSpanRegex.ReplaceAllStringFunc(text, func(m string) string {
+		spans = append(spans, "<code>"+html.EscapeString(m[1:len(m)-1])+"</code>")
+		return "\x00" + string(rune('a'+len(spans)-1)) + "\x00"
+	})
+
+	text = html.EscapeString(text)
+	text = linkRegex.ReplaceAllString(text, `<a href="$2">$1</a>`)
+	text = boldRegex.ReplaceAllString(text, `<strong>$2</strong>`)
+	text = italicStarRegex.ReplaceAllString(text, `<em>$1</em>`)
+	text = italicUnderRegex.ReplaceAllString(text, `<em>$1</em>`)
+
+	for i, span := range spans {
+		text = strings.Replace(text, "\x00"+string(rune('a'+i))+"\x00", span, 1)
+	}
+	return text
+}
+
+// ConvertMarkdownInput is the input for ConvertMarkdownActivity. Exactly one
+// of Markdown or DocumentsRef is required.
+type ConvertMarkdownInput struct {
+	// Markdown is converted and returned inline.
+	Markdown string
+
+	// DocumentsRef references Documents whose Markdown content is converted
+	// and stored under a new ref.
+	DocumentsRef core.DataRef
+}
+
+// ConvertMarkdownOutput is the output of ConvertMarkdownActivity.
+type ConvertMarkdownOutput struct {
+	// Storage is the converted Markdown input.
+	Storage string
+
+	// Ref and Count describe the converted Documents.
+	Ref   core.DataRef
+	Count int
+}
+
+// ConvertMarkdownActivity converts Markdown to Confluence storage format.
+// Converted Documents can be published with Format "storage".
+func ConvertMarkdownActivity(ctx context.Context, input ConvertMarkdownInput) (_ ConvertMarkdownOutput, err error) {
+	defer classifyError(&err)
+
+	if err := validateInput(input); err != nil {
+		return ConvertMarkdownOutput{}, err
+	}
+	if (input.Markdown == "") == input.DocumentsRef.IsEmpty() {
+		return ConvertMarkdownOutput{}, invalidInputError(errors.New("exactly one of Markdown or DocumentsRef is required"))
+	}
+
+	if input.Markdown != "" {
+		return ConvertMarkdownOutput{Storage: MarkdownToStorage(input.Markdown)}, nil
+	}
+
+	docs, err := transform.LoadDocuments(ctx, input.DocumentsRef)
+	if err != nil {
+		return ConvertMarkdownOutput{}, fmt.Errorf("load documents: %w", err)
+	}
+	for i := range docs {
+		docs[i].Content = MarkdownToStorage(docs[i].Content)
+	}
+
+	ref, err := transform.StoreDocuments(ctx, docs)
+	if err != nil {
+		return ConvertMarkdownOutput{}, fmt.Errorf("store documents: %w", err)
+	}
+
+	return ConvertMarkdownOutput{
+		Ref:   ref,
+		Count: len(docs),
+	}, nil
+}
+
+// ConvertMarkdown creates a node for converting Markdown to storage format.
+func ConvertMarkdown(input ConvertMarkdownInput) *core.Node[ConvertMarkdownInput, ConvertMarkdownOutput] {
+	return withPolicy(core.NewNode("confluence.ConvertMarkdown", ConvertMarkdownActivity, input))
+}
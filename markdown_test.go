@@ -0,0 +1,24 @@
+package confluence_test
+
+import (
+	"testing"
+
+	"github.com/resolute-sh/resolute-confluence"
+)
+
+func TestMarkdownToStorageHeadings(t *testing.T) {
+	tests := []struct {
+		markdown string
+		want     string
+	}{
+		{"# Title", "<h1>Title</h1>"},
+		{"## Title ##", "<h2>Title</h2>"},
+		{"# C#", "<h1>C#</h1>"},
+		{"### F# and C# ###  ", "<h3>F# and C#</h3>"},
+	}
+	for _, tt := range tests {
+		if got := confluence.MarkdownToStorage(tt.markdown); got != tt.want {
+			t.Errorf("MarkdownToStorage(%q) = %q, want %q", tt.markdown, got, tt.want)
+		}
+	}
+}
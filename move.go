@@ -0,0 +1,317 @@
+package confluence
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/resolute-sh/resolute/core"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+)
+
+// Move positions relative to the target page.
+const (
+	// MoveAppend makes the page the last child of the target.
+	MoveAppend = "append"
+	// MoveBefore makes the page the previous sibling of the target.
+	MoveBefore = "before"
+	// MoveAfter makes the page the next sibling of the target.
+	MoveAfter = "after"
+)
+
+// MovePage moves a page, with its descendants, relative to a target page.
+func (c *Client) MovePage(ctx context.Context, pageID, position, targetID string) error {
+	endpoint := c.apiEndpoint(nil, "content", pageID, "move", position, targetID)
+	return c.doJSON(ctx, http.MethodPut, endpoint, nil, nil)
+}
+
+// CopyPageTreeRequest describes a page hierarchy copy.
+type CopyPageTreeRequest struct {
+	PageID            string
+	DestinationPageID string
+
+	CopyAttachments bool
+	CopyPermissions bool
+	CopyProperties  bool
+	CopyLabels      bool
+
+	// TitlePrefix is prepended to every copied title.
+	TitlePrefix string
+	// TitleSearch is replaced by TitleReplace in every copied title.
+	TitleSearch  string
+	TitleReplace string
+}
+
+type copyPageTreeBody struct {
+	CopyAttachments   bool             `json:"copyAttachments"`
+	CopyPermissions   bool             `json:"copyPermissions"`
+	CopyProperties    bool             `json:"copyProperties"`
+	CopyLabels        bool             `json:"copyLabels"`
+	CopyCustomContent bool             `json:"copyCustomContents"`
+	DestinationPageID string           `json:"destinationPageId"`
+	TitleOptions      copyTitleOptions `json:"titleOptions"`
+}
+
+type copyTitleOptions struct {
+	Prefix  string `json:"prefix,omitempty"`
+	Search  string `json:"search,omitempty"`
+	Replace string `json:"replace,omitempty"`
+}
+
+// LongTask reports the progress of an asynchronous Confluence operation.
+type LongTask struct {
+	ID                 string              `json:"id"`
+	PercentageComplete int                 `json:"percentageComplete"`
+	Finished           bool                `json:"finished"`
+	Successful         bool                `json:"successful"`
+	Messages           []LongTaskMessage   `json:"messages"`
+	AdditionalDetails  LongTaskAdditionals `json:"additionalDetails"`
+}
+
+// LongTaskMessage is a progress or error message of a long task.
+type LongTaskMessage struct {
+	Translation string `json:"translation"`
+}
+
+// LongTaskAdditionals holds operation-specific long task details.
+type LongTaskAdditionals struct {
+	DestinationID  string `json:"destinationId"`
+	DestinationURL string `json:"destinationUrl"`
+	// DownloadURL is the download link of finished exports.
+	DownloadURL string `json:"downloadUrl"`
+}
+
+// message joins the task messages.
+func (t *LongTask) message() string {
+	messages := make([]string, 0, len(t.Messages))
+	for _, m := range t.Messages {
+		if m.Translation != "" {
+			messages = append(messages, m.Translation)
+		}
+	}
+	return strings.Join(messages, "; ")
+}
+
+// CopyPageTree starts copying a page and its descendants under a destination
+// page and returns the ID of the long task tracking the copy.
+func (c *Client) CopyPageTree(ctx context.Context, req CopyPageTreeRequest) (string, error) {
+	body := copyPageTreeBody{
+		CopyAttachments:   req.CopyAttachments,
+		CopyPermissions:   req.CopyPermissions,
+		CopyProperties:    req.CopyProperties,
+		CopyLabels:        req.CopyLabels,
+		DestinationPageID: req.DestinationPageID,
+		TitleOptions: copyTitleOptions{
+			Prefix:  req.TitlePrefix,
+			Search:  req.TitleSearch,
+			Replace: req.TitleReplace,
+		},
+	}
+
+	endpoint := c.apiEndpoint(nil, "content", req.PageID, "pagehierarchy", "copy")
+
+	var task LongTask
+	if err := c.doJSON(ctx, http.MethodPost, endpoint, body, &task); err != nil {
+		return "", err
+	}
+
+	return task.ID, nil
+}
+
+// GetLongTask fetches the progress of a long task.
+func (c *Client) GetLongTask(ctx context.Context, taskID string) (*LongTask, error) {
+	endpoint := c.apiEndpoint(nil, "longtask", taskID)
+
+	var task LongTask
+	if err := c.getJSON(ctx, endpoint, &task); err != nil {
+		return nil, err
+	}
+
+	return &task, nil
+}
+
+// MovePageInput is the input for MovePageActivity.
+type MovePageInput struct {
+	BaseURL  string `validate:"required,url"`
+	Email    string `validate:"required"`
+	APIToken string `validate:"required"`
+	Site     string
+	PageID   string `validate:"required"`
+	TargetID string `validate:"required"`
+
+	// Position is "append" (default), "before", or "after".
+	Position string `validate:"oneof=|append|before|after"`
+}
+
+// MovePageOutput is the output of MovePageActivity.
+type MovePageOutput struct {
+	PageID string
+}
+
+// MovePageActivity moves a page and its descendants relative to a target
+// page, which may be in another space.
+func MovePageActivity(ctx context.Context, input MovePageInput) (_ MovePageOutput, err error) {
+	defer classifyError(&err)
+
+	if err := resolveCredentials(ctx, input.Site, &input.BaseURL, &input.Email, &input.APIToken); err != nil {
+		return MovePageOutput{}, err
+	}
+	if err := validateInput(input); err != nil {
+		return MovePageOutput{}, err
+	}
+
+	client := NewClient(ClientConfig{
+		BaseURL:  input.BaseURL,
+		Email:    input.Email,
+		APIToken: input.APIToken,
+	})
+
+	position := input.Position
+	if position == "" {
+		position = MoveAppend
+	}
+
+	if err := client.MovePage(ctx, input.PageID, position, input.TargetID); err != nil {
+		return MovePageOutput{}, fmt.Errorf("move page %s: %w", input.PageID, err)
+	}
+
+	return MovePageOutput{PageID: input.PageID}, nil
+}
+
+// MovePage creates a node for moving a Confluence page.
+func MovePage(input MovePageInput) *core.Node[MovePageInput, MovePageOutput] {
+	return withPolicy(core.NewNode("confluence.MovePage", MovePageActivity, input))
+}
+
+// ErrTypeCopyFailed is the application error type of page tree copies that
+// Confluence reports as unsuccessful.
+const ErrTypeCopyFailed = "confluence.CopyFailed"
+
+// CopyPageTreeInput is the input for CopyPageTreeActivity.
+type CopyPageTreeInput struct {
+	BaseURL           string `validate:"required,url"`
+	Email             string `validate:"required"`
+	APIToken          string `validate:"required"`
+	Site              string
+	PageID            string `validate:"required"`
+	DestinationPageID string `validate:"required"`
+
+	CopyAttachments bool
+	CopyPermissions bool
+	CopyProperties  bool
+	CopyLabels      bool
+
+	// TitlePrefix is prepended to every copied title. Copies within a space
+	// need a prefix or replacement because titles must be unique.
+	TitlePrefix string
+	// TitleSearch is replaced by TitleReplace in every copied title.
+	TitleSearch  string
+	TitleReplace string
+
+	// PollInterval is the delay between progress checks. Defaults to 2s.
+	PollInterval time.Duration
+}
+
+// CopyPageTreeOutput is the output of CopyPageTreeActivity.
+type CopyPageTreeOutput struct {
+	TaskID string
+	// PageID is the ID of the copied root page, when Confluence reports it.
+	PageID string
+	URL    string
+}
+
+// CopyPageTreeActivity copies a page and its descendants under a destination
+// page and waits for the copy to finish, heartbeating its progress. A retried
+// attempt resumes waiting on the copy started by the previous attempt.
+func CopyPageTreeActivity(ctx context.Context, input CopyPageTreeInput) (_ CopyPageTreeOutput, err error) {
+	defer classifyError(&err)
+
+	if err := resolveCredentials(ctx, input.Site, &input.BaseURL, &input.Email, &input.APIToken); err != nil {
+		return CopyPageTreeOutput{}, err
+	}
+	if err := validateInput(input); err != nil {
+		return CopyPageTreeOutput{}, err
+	}
+
+	client := NewClient(ClientConfig{
+		BaseURL:  input.BaseURL,
+		Email:    input.Email,
+		APIToken: input.APIToken,
+	})
+
+	interval := input.PollInterval
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+
+	var taskID string
+	if activity.IsActivity(ctx) && activity.HasHeartbeatDetails(ctx) {
+		if err := activity.GetHeartbeatDetails(ctx, &taskID); err != nil {
+			return CopyPageTreeOutput{}, fmt.Errorf("get heartbeat details: %w", err)
+		}
+	}
+	if taskID == "" {
+		taskID, err = client.CopyPageTree(ctx, CopyPageTreeRequest{
+			PageID:            input.PageID,
+			DestinationPageID: input.DestinationPageID,
+			CopyAttachments:   input.CopyAttachments,
+			CopyPermissions:   input.CopyPermissions,
+			CopyProperties:    input.CopyProperties,
+			CopyLabels:        input.CopyLabels,
+			TitlePrefix:       input.TitlePrefix,
+			TitleSearch:       input.TitleSearch,
+			TitleReplace:      input.TitleReplace,
+		})
+		if err != nil {
+			return CopyPageTreeOutput{}, fmt.Errorf("copy page tree %s: %w", input.PageID, err)
+		}
+		recordHeartbeat(ctx, taskID)
+	}
+
+	task, err := waitLongTask(ctx, client, taskID, interval)
+	if err != nil {
+		return CopyPageTreeOutput{}, fmt.Errorf("wait for copy task %s: %w", taskID, err)
+	}
+	if !task.Successful {
+		return CopyPageTreeOutput{}, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("copy of page tree %s failed: %s", input.PageID, task.message()),
+			ErrTypeCopyFailed, nil)
+	}
+
+	output := CopyPageTreeOutput{
+		TaskID: taskID,
+		PageID: task.AdditionalDetails.DestinationID,
+	}
+	output.URL = webURL(input.BaseURL, task.AdditionalDetails.DestinationURL)
+	return output, nil
+}
+
+// waitLongTask polls a long task until it finishes, heartbeating the task ID
+// and its progress.
+func waitLongTask(ctx context.Context, client *Client, taskID string, interval time.Duration) (*LongTask, error) {
+	for {
+		task, err := client.GetLongTask(ctx, taskID)
+		if err != nil {
+			return nil, err
+		}
+		recordHeartbeat(ctx, taskID, task.PercentageComplete)
+
+		if task.Finished {
+			return task, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// CopyPageTree creates a node for copying a Confluence page tree.
+func CopyPageTree(input CopyPageTreeInput) *core.Node[CopyPageTreeInput, CopyPageTreeOutput] {
+	return withPolicy(core.NewNode("confluence.CopyPageTree", CopyPageTreeActivity, input))
+}
@@ -2,139 +2,1030 @@ package confluence
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
-	"github.com/resolute-sh/resolute/core"
+	"github.com/resolute-sh/resolute-confluence/cql"
 	transform "github.com/resolute-sh/resolute-transform"
+	"github.com/resolute-sh/resolute/core"
+	"go.temporal.io/sdk/activity"
+	"golang.org/x/sync/errgroup"
 )
 
 // FetchPagesInput is the input for FetchPagesActivity.
 type FetchPagesInput struct {
-	BaseURL  string
-	Email    string
-	APIToken string
+	BaseURL  string `validate:"required,url"`
+	Email    string `validate:"required"`
+	APIToken string `validate:"required"`
+	Site     string
+
+	// SpaceKey is the space to fetch. SpaceKeys, SpaceLabels,
+	// SpaceCategories, and PersonalSpacesOf add more spaces; at least one of
+	// them must select a space.
 	SpaceKey string
-	Since    *time.Time
-	Limit    int
+	// SpaceKeys lists further spaces to fetch.
+	SpaceKeys []string
+	// SpaceLabels selects further spaces by label. Spaces with any of these
+	// labels are fetched.
+	SpaceLabels []string
+	// SpaceCategories selects further spaces by space category. Spaces in
+	// any of these categories are fetched.
+	SpaceCategories []string
+	// ExcludeSpaceLabels leaves out the spaces selected by SpaceLabels or
+	// SpaceCategories that have any of these labels or categories, such as
+	// "internal-only". Spaces selected by key are always fetched.
+	ExcludeSpaceLabels []string
+	// PersonalSpacesOf selects the personal spaces of users by account ID.
+	// Users without a personal space are skipped.
+	PersonalSpacesOf []string
+	// IncludeArchivedSpaces selects archived spaces by label too, and finds
+	// the pages of archived spaces when Since is filtered through CQL, which
+	// leaves archived spaces out by default. Set it for compliance exports.
+	IncludeArchivedSpaces bool
+	// SpaceConcurrency is the number of spaces fetched at once. Defaults to 4.
+	SpaceConcurrency int `validate:"min=0"`
+
+	// Concurrency is the number of page bodies fetched at once. Above one,
+	// pages are listed without their bodies, which are then fetched in
+	// parallel. Defaults to one, which lists pages with their bodies.
+	Concurrency int `validate:"min=0"`
+	// LazyBodies lists pages without their bodies and only fetches the
+	// bodies of pages that pass the Since and KnownVersions filters. It
+	// saves bandwidth when few pages changed since a previous sync, at the
+	// cost of a request per changed page. Implied by a Concurrency above one.
+	LazyBodies bool
+
+	// Since restricts the fetch to pages modified at or after this time.
+	// The filter is applied server-side through CQL when only current pages
+	// are fetched, and client-side otherwise.
+	Since *time.Time
+
+	// Statuses selects pages by status: StatusCurrent, StatusDraft, or
+	// StatusArchived. Empty fetches current pages only.
+	Statuses []string
+
+	// Limit is the number of pages requested per API call, up to the
+	// API maximum of 250. Defaults to 250.
+	Limit int `validate:"min=0"`
+
+	// MaxResults caps the number of pages fetched per space. Zero fetches
+	// whole spaces, unless the activity defaults set MaxResults.
+	MaxResults int `validate:"min=0"`
+
+	// Start is the pagination offset to begin at. Pass NextStart from a
+	// previous output to continue a fetch that stopped at MaxResults. It
+	// requires the input to select a single space.
+	Start int `validate:"min=0"`
+
+	// CollectCommentRefs adds the refs of inline comment markers found in
+	// each page body to the "inline_comment_refs" metadata field.
+	CollectCommentRefs bool
+
+	// KnownVersions maps page IDs to the version numbers seen by a previous
+	// sync. Pages still at that version are skipped.
+	KnownVersions map[string]int
+	// KnownVersionsRef references the Documents of a previous sync, whose
+	// "version" metadata is added to KnownVersions.
+	KnownVersionsRef core.DataRef
+
+	// BatchSize stores the Documents in batches of at least this many, rounded
+	// up to whole API pages, as they are fetched instead of holding the whole
+	// space in memory. Each batch gets its own ref in Refs. Zero stores
+	// everything under a single ref.
+	BatchSize int `validate:"min=0"`
+	// Spool buffers the Documents of a single ref in a temporary file while
+	// the space is fetched, instead of in memory. Storing them still reads
+	// their encoded form into memory whole; use BatchSize to bound it. It
+	// applies to single-space fetches without BatchSize.
+	Spool bool
+
+	// Source is the Source of the stored Documents, which tells apart the
+	// Documents of several sites. Defaults to the hostname of BaseURL.
+	Source string
 }
 
 // FetchPagesOutput is the output of FetchPagesActivity.
 type FetchPagesOutput struct {
-	Ref   core.DataRef
+	// Ref references the stored Documents. It is only set when they were
+	// stored under a single ref; batched fetches list their refs in Refs.
+	Ref core.DataRef
+	// Refs references the stored Documents, one ref per batch.
+	Refs  []core.DataRef
 	Count int
+
+	// Fetched is the number of pages retrieved from the API.
+	Fetched int
+	// Skipped is the number of fetched pages filtered out before storage.
+	Skipped int
+	// Unchanged is the number of skipped pages whose version matched
+	// KnownVersions.
+	Unchanged int
+
+	// Spaces breaks the counts down per fetched space.
+	Spaces []SpaceFetchCount
+	// Errors lists the spaces, by key, that failed when several spaces
+	// were fetched, and the pages, by ID, that failed on their own with a
+	// server error or a malformed body. The other Documents are still
+	// stored.
+	Errors []ItemError
+	// Denied lists the pages, by ID, left out because the credentials
+	// cannot read them, with the reason.
+	Denied []ItemError
+
+	// NextStart is the offset to pass as Start to fetch the next batch.
+	// It and HasMore are only set when a single space was fetched.
+	NextStart int
+	// HasMore reports whether pages remain after NextStart.
+	HasMore bool
+	// Stopped reports that the fetch stopped early, with HasMore set,
+	// because the activity deadline was near. Fetch from NextStart in a
+	// new call to continue.
+	Stopped bool
+	// Watermark is the latest modification time among the stored pages.
+	Watermark time.Time
+}
+
+// SpaceFetchCount reports the pages fetched from one space.
+type SpaceFetchCount struct {
+	SpaceKey  string
+	Count     int
+	Fetched   int
+	Skipped   int
+	Unchanged int
 }
 
 // FetchPagesActivity fetches pages from a Confluence space and stores them.
-func FetchPagesActivity(ctx context.Context, input FetchPagesInput) (FetchPagesOutput, error) {
+// It paginates through the entire space unless MaxResults is set, and
+// checkpoints its progress in heartbeats so a retry resumes where the
+// previous attempt stopped. Pages that fail on their own are reported in
+// Errors without failing the fetch. A single-space fetch that nears the
+// activity deadline stops with the pages fetched so far and reports
+// Stopped. When the input selects several spaces they are fetched
+// concurrently and their Documents stored together; a space that fails is
+// reported in Errors without failing the others.
+func FetchPagesActivity(ctx context.Context, input FetchPagesInput) (_ FetchPagesOutput, err error) {
+	defer classifyError(&err)
+
+	if err := resolveCredentials(ctx, input.Site, &input.BaseURL, &input.Email, &input.APIToken); err != nil {
+		return FetchPagesOutput{}, err
+	}
+	if err := validateInput(input); err != nil {
+		return FetchPagesOutput{}, err
+	}
+	input.MaxResults = maxResults(ctx, input.MaxResults)
+
+	if err := checkStatuses(input.Statuses); err != nil {
+		return FetchPagesOutput{}, invalidInputError(err)
+	}
+
+	if !input.KnownVersionsRef.IsEmpty() {
+		known, err := knownVersions(ctx, input.KnownVersionsRef, input.KnownVersions)
+		if err != nil {
+			return FetchPagesOutput{}, err
+		}
+		input.KnownVersions = known
+	}
+
 	client := NewClient(ClientConfig{
 		BaseURL:  input.BaseURL,
 		Email:    input.Email,
 		APIToken: input.APIToken,
 	})
 
-	limit := input.Limit
-	if limit <= 0 {
-		limit = 100
+	spaceKeys, err := selectSpaces(ctx, client, input)
+	if err != nil {
+		return FetchPagesOutput{}, err
+	}
+
+	var result spaceFetch
+	var spaces []SpaceFetchCount
+	var spaceErrors []ItemError
+	if len(spaceKeys) == 1 {
+		input.SpaceKey = spaceKeys[0]
+		var spool *documentSpool
+		if input.Spool && input.BatchSize == 0 {
+			if spool, err = newDocumentSpool(); err != nil {
+				return FetchPagesOutput{}, err
+			}
+			defer spool.Close()
+		}
+		result, err = fetchSpacePages(ctx, input, true, true, spool)
+		if err != nil {
+			return FetchPagesOutput{}, err
+		}
+		spaces = []SpaceFetchCount{result.spaceCount(input.SpaceKey)}
+	} else {
+		result, spaces, spaceErrors, err = fetchSpaces(ctx, input, spaceKeys)
+		if err != nil {
+			return FetchPagesOutput{}, err
+		}
+	}
+
+	if len(result.docs) > 0 || result.spool != nil || len(result.refs) == 0 {
+		ref, err := result.store(ctx)
+		if err != nil {
+			return FetchPagesOutput{}, fmt.Errorf("store documents: %w", err)
+		}
+		result.refs = append(result.refs, ref)
 	}
 
-	pages, err := client.GetSpacePages(ctx, input.SpaceKey, limit)
+	output := FetchPagesOutput{
+		Refs:      result.refs,
+		Count:     result.count,
+		Fetched:   result.fetched,
+		Skipped:   result.skipped,
+		Unchanged: result.unchanged,
+		Spaces:    spaces,
+		Errors:    append(result.errors, spaceErrors...),
+		Denied:    result.denied,
+		Watermark: result.watermark,
+	}
+	if len(spaceKeys) == 1 {
+		output.NextStart = result.next
+		output.HasMore = result.more
+		output.Stopped = result.stopped
+	}
+	if len(result.refs) == 1 {
+		output.Ref = result.refs[0]
+	}
+	return output, nil
+}
+
+// spaceFetch is the result of collecting the pages of a space. Documents
+// already flushed to storage by a batched fetch are listed in refs; the
+// rest are held in docs, or moved to spool when set.
+type spaceFetch struct {
+	docs      []transform.Document
+	spool     *documentSpool
+	refs      []core.DataRef
+	count     int
+	fetched   int
+	skipped   int
+	unchanged int
+	denied    []ItemError
+	errors    []ItemError
+	// retryFrom is just before the earliest modification time among the
+	// pages in errors, from which a later sync must fetch again to pick
+	// them up, or nil when no page failed. Pages of unknown modification
+	// time count as modified at the Since of the fetch.
+	retryFrom *time.Time
+	next      int
+	more      bool
+	stopped   bool
+	watermark time.Time
+}
+
+// spaceCount returns the counts of the result as a SpaceFetchCount.
+func (r *spaceFetch) spaceCount(spaceKey string) SpaceFetchCount {
+	return SpaceFetchCount{
+		SpaceKey:  spaceKey,
+		Count:     r.count,
+		Fetched:   r.fetched,
+		Skipped:   r.skipped,
+		Unchanged: r.unchanged,
+	}
+}
+
+// merge folds the result of another space fetch into r.
+func (r *spaceFetch) merge(other spaceFetch) {
+	r.docs = append(r.docs, other.docs...)
+	r.refs = append(r.refs, other.refs...)
+	r.count += other.count
+	r.fetched += other.fetched
+	r.skipped += other.skipped
+	r.unchanged += other.unchanged
+	r.denied = append(r.denied, other.denied...)
+	r.errors = append(r.errors, other.errors...)
+	if other.retryFrom != nil {
+		r.failedAt(*other.retryFrom)
+	}
+	if other.watermark.After(r.watermark) {
+		r.watermark = other.watermark
+	}
+}
+
+// failedAt lowers retryFrom to t for a failed page.
+func (r *spaceFetch) failedAt(t time.Time) {
+	if r.retryFrom == nil || t.Before(*r.retryFrom) {
+		r.retryFrom = &t
+	}
+}
+
+// add appends Documents to the result.
+func (r *spaceFetch) add(docs ...transform.Document) {
+	for _, doc := range docs {
+		if doc.UpdatedAt.After(r.watermark) {
+			r.watermark = doc.UpdatedAt
+		}
+	}
+	r.docs = append(r.docs, docs...)
+	r.count += len(docs)
+}
+
+// spoolDocs moves the Documents held in memory to the spool.
+func (r *spaceFetch) spoolDocs() error {
+	if err := r.spool.add(r.docs...); err != nil {
+		return err
+	}
+	r.docs = nil
+	return nil
+}
+
+// store stores the Documents of the result that are not in refs under a
+// single ref.
+func (r *spaceFetch) store(ctx context.Context) (core.DataRef, error) {
+	if r.spool == nil {
+		return transform.StoreDocuments(ctx, r.docs)
+	}
+	if err := r.spoolDocs(); err != nil {
+		return core.DataRef{}, err
+	}
+	return r.spool.store(ctx)
+}
+
+// knownVersions merges the page versions recorded in the Documents of ref
+// into a copy of known.
+func knownVersions(ctx context.Context, ref core.DataRef, known map[string]int) (map[string]int, error) {
+	docs, err := transform.LoadDocuments(ctx, ref)
 	if err != nil {
-		return FetchPagesOutput{}, fmt.Errorf("get space pages: %w", err)
+		return nil, fmt.Errorf("load known versions: %w", err)
 	}
 
-	docs := make([]transform.Document, 0, len(pages))
-	for _, page := range pages {
-		if input.Since != nil && page.Version.CreatedAt.Before(*input.Since) {
+	merged := make(map[string]int, len(known)+len(docs))
+	for _, doc := range docs {
+		version, err := strconv.Atoi(doc.Metadata["version"])
+		if err != nil {
 			continue
 		}
-		doc := pageToDocument(page, input.BaseURL)
-		docs = append(docs, doc)
+		merged[documentPageID(doc)] = version
 	}
+	for id, version := range known {
+		merged[id] = version
+	}
+	return merged, nil
+}
 
-	ref, err := transform.StoreDocuments(ctx, docs)
-	if err != nil {
-		return FetchPagesOutput{}, fmt.Errorf("store documents: %w", err)
+// Content statuses accepted by FetchPagesInput.Statuses.
+const (
+	StatusCurrent  = "current"
+	StatusDraft    = "draft"
+	StatusArchived = "archived"
+)
+
+// onlyCurrent reports whether statuses selects current content only, the
+// API default.
+func onlyCurrent(statuses []string) bool {
+	for _, status := range statuses {
+		if status != StatusCurrent {
+			return false
+		}
 	}
+	return true
+}
 
-	return FetchPagesOutput{
-		Ref:   ref,
-		Count: len(docs),
-	}, nil
+// checkStatuses rejects statuses FetchPagesInput.Statuses does not accept.
+func checkStatuses(statuses []string) error {
+	for _, status := range statuses {
+		switch status {
+		case StatusCurrent, StatusDraft, StatusArchived:
+		default:
+			return fmt.Errorf("unknown status %q", status)
+		}
+	}
+	return nil
+}
+
+// selectSpaces resolves the spaces selected by the input, in input order
+// and without duplicates.
+func selectSpaces(ctx context.Context, client *Client, input FetchPagesInput) ([]string, error) {
+	if input.SpaceKey == "" && len(input.SpaceKeys) == 0 && len(input.SpaceLabels) == 0 &&
+		len(input.SpaceCategories) == 0 && len(input.PersonalSpacesOf) == 0 {
+		return nil, invalidInputError(errors.New("one of SpaceKey, SpaceKeys, SpaceLabels, SpaceCategories or PersonalSpacesOf is required"))
+	}
+
+	keys := append([]string{input.SpaceKey}, input.SpaceKeys...)
+	var selections []ListSpacesOptions
+	if len(input.SpaceLabels) > 0 {
+		selections = append(selections, ListSpacesOptions{Labels: input.SpaceLabels})
+	}
+	if len(input.SpaceCategories) > 0 {
+		selections = append(selections, ListSpacesOptions{Categories: input.SpaceCategories})
+	}
+	for _, opts := range selections {
+		opts.ExcludeLabels = input.ExcludeSpaceLabels
+		opts.IncludeArchivedSpaces = input.IncludeArchivedSpaces
+		spaces, err := listAllSpaces(ctx, client, opts, maxPageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, space := range spaces {
+			keys = append(keys, space.Key)
+		}
+	}
+	for _, accountID := range input.PersonalSpacesOf {
+		space, err := client.GetPersonalSpace(ctx, accountID)
+		if err != nil {
+			return nil, fmt.Errorf("get personal space of %s: %w", accountID, err)
+		}
+		if space != nil {
+			keys = append(keys, space.Key)
+		}
+	}
+
+	seen := make(map[string]bool, len(keys))
+	selected := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		selected = append(selected, key)
+	}
+
+	if len(selected) > 1 && input.Start > 0 {
+		return nil, invalidInputError(errors.New("a start offset requires a single space"))
+	}
+	return selected, nil
+}
+
+// fetchSpaces fetches several spaces concurrently and merges the results.
+// Spaces that fail are reported as item errors, and an error is only
+// returned when every space fails or the activity is canceled. Progress is
+// not checkpointed, so a retry starts over.
+func fetchSpaces(ctx context.Context, input FetchPagesInput, spaceKeys []string) (spaceFetch, []SpaceFetchCount, []ItemError, error) {
+	concurrency := input.SpaceConcurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+
+	results := make([]spaceFetch, len(spaceKeys))
+	errs := make([]error, len(spaceKeys))
+	var g errgroup.Group
+	g.SetLimit(concurrency)
+	for i, key := range spaceKeys {
+		spaceInput := input
+		spaceInput.SpaceKey = key
+		g.Go(func() error {
+			results[i], errs[i] = fetchSpacePages(ctx, spaceInput, false, false, nil)
+			return nil
+		})
+	}
+	g.Wait()
+	if err := ctx.Err(); err != nil {
+		return spaceFetch{}, nil, nil, err
+	}
+
+	var merged spaceFetch
+	var itemErrors []ItemError
+	var failed []error
+	counts := make([]SpaceFetchCount, 0, len(spaceKeys))
+	for i, result := range results {
+		if errs[i] != nil {
+			itemErrors = append(itemErrors, newItemError(spaceKeys[i], errs[i]))
+			failed = append(failed, fmt.Errorf("space %s: %w", spaceKeys[i], errs[i]))
+			continue
+		}
+		merged.merge(result)
+		counts = append(counts, result.spaceCount(spaceKeys[i]))
+	}
+	if len(failed) == len(spaceKeys) {
+		return spaceFetch{}, nil, nil, errors.Join(failed...)
+	}
+	return merged, counts, itemErrors, nil
+}
+
+// fetchSpacePages paginates through the pages of a space and converts them
+// to Documents. When input.BatchSize is set, Documents are flushed to
+// storage in batches as they are fetched. Resumable fetches checkpoint their
+// progress in heartbeats and resume from the last checkpoint when retried;
+// only one resumable fetch may run per activity. Stoppable fetches stop
+// early when the activity deadline is near, leaving result.next and
+// result.more to resume from.
+func fetchSpacePages(ctx context.Context, input FetchPagesInput, resumable, stoppable bool, spool *documentSpool) (spaceFetch, error) {
+	client := NewClient(ClientConfig{
+		BaseURL:  input.BaseURL,
+		Email:    input.Email,
+		APIToken: input.APIToken,
+	})
+
+	source := documentSource(input.BaseURL, input.Source)
+
+	limit := pageSize(ctx, input.Limit)
+	batched := input.BatchSize > 0
+
+	lazy := input.LazyBodies || input.Concurrency > 1
+	expand := documentExpand
+	if lazy {
+		expand = []string{"space", "version"}
+	}
+
+	result := spaceFetch{spool: spool}
+	start := input.Start
+
+	var progress *fetchProgress
+	if resumable {
+		var err error
+		if progress, err = resumeFetch(ctx); err != nil {
+			return spaceFetch{}, err
+		}
+	}
+	if progress != nil {
+		for _, ref := range progress.Refs {
+			if batched {
+				result.refs = append(result.refs, ref)
+				result.count += ref.Count
+				continue
+			}
+			docs, err := transform.LoadDocuments(ctx, ref)
+			if err != nil {
+				return spaceFetch{}, fmt.Errorf("load checkpoint: %w", err)
+			}
+			result.add(docs...)
+			if spool != nil {
+				if err := result.spoolDocs(); err != nil {
+					return spaceFetch{}, err
+				}
+			}
+		}
+		start = progress.Start
+		result.fetched = progress.Fetched
+		result.skipped = progress.Skipped
+		result.unchanged = progress.Unchanged
+		result.denied = progress.Denied
+		result.errors = progress.Errors
+		result.retryFrom = progress.RetryFrom
+		if progress.Watermark.After(result.watermark) {
+			result.watermark = progress.Watermark
+		}
+	} else {
+		progress = &fetchProgress{Start: start}
+	}
+	var since time.Time
+	if input.Since != nil {
+		since = *input.Since
+	}
+	flushed := len(result.docs)
+	listed := 0
+	result.next, result.more = start, true
+
+	// flush stores the Documents added since the last flush. Batched
+	// fetches release them; others keep them for the final combined ref,
+	// in memory or in the spool.
+	flush := func() error {
+		if len(result.docs) == flushed {
+			return nil
+		}
+		ref, err := transform.StoreDocuments(ctx, result.docs[flushed:])
+		if err != nil {
+			return err
+		}
+		progress.Refs = append(progress.Refs, ref)
+		switch {
+		case batched:
+			result.refs = append(result.refs, ref)
+			result.docs = nil
+		case spool != nil:
+			if err := result.spoolDocs(); err != nil {
+				return err
+			}
+		}
+		flushed = len(result.docs)
+		return nil
+	}
+
+	for {
+		if input.MaxResults > 0 && result.fetched >= input.MaxResults {
+			break
+		}
+		if input.MaxResults > 0 {
+			limit = min(limit, input.MaxResults-result.fetched)
+		}
+
+		// Listed pages are converted as they are decoded, so that only one
+		// page body is held in memory at a time.
+		opts := ConvertOptions{CollectCommentRefs: input.CollectCommentRefs}
+		var pages []Page
+		n := 0
+		each := func(page Page) error {
+			n++
+			result.fetched++
+			if input.Since != nil && page.Version.ModifiedAt().Before(*input.Since) {
+				result.skipped++
+				return nil
+			}
+			if v, ok := input.KnownVersions[page.ID]; ok && v == page.Version.Number {
+				result.skipped++
+				result.unchanged++
+				return nil
+			}
+			if lazy {
+				pages = append(pages, page)
+				return nil
+			}
+			result.add(pageToDocument(page, input.BaseURL, source, opts))
+			return nil
+		}
+
+		var list *PageList
+		var err error
+		if input.Since != nil && onlyCurrent(input.Statuses) {
+			list, err = client.StreamSearchContentWithOptions(ctx, sinceCQL(input.SpaceKey, *input.Since), start, limit, SearchOptions{
+				Expand:                expand,
+				IncludeArchivedSpaces: input.IncludeArchivedSpaces,
+			}, each)
+		} else {
+			list, err = client.StreamContent(ctx, ContentQuery{
+				SpaceKey: input.SpaceKey,
+				Statuses: input.Statuses,
+				Expand:   expand,
+			}, start, limit, each)
+		}
+		if err != nil {
+			return spaceFetch{}, fmt.Errorf("list space pages at %d: %w", start, err)
+		}
+		result.errors = append(result.errors, list.Malformed...)
+		if len(list.Malformed) > 0 {
+			result.failedAt(since)
+		}
+
+		if lazy {
+			docs, denied, failed, err := fetchPageDocuments(ctx, client, pages, input.BaseURL, source, opts, max(input.Concurrency, 1))
+			if err != nil {
+				return spaceFetch{}, err
+			}
+			result.add(docs...)
+			result.denied = append(result.denied, denied...)
+			result.errors = append(result.errors, failed...)
+			for _, item := range failed {
+				result.failedAt(retryTime(pages, item.ID, since))
+			}
+		}
+
+		start += n
+		listed++
+		full := batched && len(result.docs) >= input.BatchSize
+		checkpoint := resumable && listed%checkpointInterval == 0 && activity.IsActivity(ctx)
+		if full || checkpoint {
+			if err := flush(); err != nil {
+				return spaceFetch{}, fmt.Errorf("store batch: %w", err)
+			}
+			progress.Start = start
+			progress.Fetched = result.fetched
+			progress.Skipped = result.skipped
+			progress.Unchanged = result.unchanged
+			progress.Denied = result.denied
+			progress.Errors = result.errors
+			progress.RetryFrom = result.retryFrom
+			progress.Watermark = result.watermark
+		}
+		if resumable {
+			recordHeartbeat(ctx, *progress)
+		} else {
+			recordHeartbeat(ctx, input.SpaceKey, result.fetched)
+		}
+
+		result.next = start
+		result.more = list.HasMore() && n > 0
+		if !result.more {
+			break
+		}
+		if stoppable && nearDeadline(ctx) {
+			result.stopped = true
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// fetchPageDocuments fetches the bodies of listed pages with up to
+// concurrency requests in flight and converts them to Documents, keeping
+// the listing order. Pages the credentials cannot read are left out and
+// returned as denied, and pages that fail on their own, with a server
+// error or a malformed body, are returned as failed instead of failing the
+// fetch.
+func fetchPageDocuments(ctx context.Context, client *Client, pages []Page, baseURL, source string, opts ConvertOptions, concurrency int) (docs []transform.Document, denied, failed []ItemError, err error) {
+	docs = make([]transform.Document, len(pages))
+	errs := make([]error, len(pages))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(concurrency)
+	for i, listed := range pages {
+		g.Go(func() error {
+			page, err := client.GetPage(gctx, listed.ID)
+			if isInaccessible(err) || isItemFailure(err) {
+				errs[i] = err
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("get page %s: %w", listed.ID, err)
+			}
+			docs[i] = pageToDocument(*page, baseURL, source, opts)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	kept := docs[:0]
+	for i, doc := range docs {
+		switch {
+		case errs[i] == nil:
+			kept = append(kept, doc)
+		case isInaccessible(errs[i]):
+			denied = append(denied, newItemError(pages[i].ID, errs[i]))
+		default:
+			failed = append(failed, newItemError(pages[i].ID, errs[i]))
+		}
+	}
+	return kept, denied, failed, nil
+}
+
+// retryTime returns the time from which a sync must fetch again to pick up
+// the listed page with the given ID that failed: just before its
+// modification time, or since when it is unknown.
+func retryTime(pages []Page, id string, since time.Time) time.Time {
+	for _, page := range pages {
+		if page.ID == id {
+			if modified := page.Version.ModifiedAt(); !modified.IsZero() {
+				return modified.Add(-time.Nanosecond)
+			}
+			break
+		}
+	}
+	return since
+}
+
+// checkpointInterval is the number of API pages between the checkpoints
+// fetchSpacePages records in its heartbeat details.
+const checkpointInterval = 10
+
+// fetchProgress is the heartbeat detail recorded by fetchSpacePages. The
+// documents fetched before Start are flushed to storage so that a retried
+// attempt resumes from the last checkpoint instead of from page zero.
+type fetchProgress struct {
+	Start     int
+	Fetched   int
+	Skipped   int
+	Unchanged int
+	Denied    []ItemError
+	Errors    []ItemError
+	RetryFrom *time.Time
+	Watermark time.Time
+	Refs      []core.DataRef
+}
+
+// resumeFetch returns the progress recorded by a previous attempt of the
+// running activity, or nil when there is none.
+func resumeFetch(ctx context.Context) (*fetchProgress, error) {
+	if !activity.IsActivity(ctx) || !activity.HasHeartbeatDetails(ctx) {
+		return nil, nil
+	}
+
+	var progress fetchProgress
+	if err := activity.GetHeartbeatDetails(ctx, &progress); err != nil {
+		return nil, fmt.Errorf("get heartbeat details: %w", err)
+	}
+	return &progress, nil
 }
 
 // FetchPageInput is the input for FetchPageActivity.
 type FetchPageInput struct {
-	BaseURL  string
-	Email    string
-	APIToken string
-	PageID   string
+	BaseURL  string `validate:"required,url"`
+	Email    string `validate:"required"`
+	APIToken string `validate:"required"`
+	Site     string
+	PageID   string `validate:"required"`
+
+	// CollectCommentRefs adds the refs of inline comment markers found in
+	// the page body to the "inline_comment_refs" metadata field.
+	CollectCommentRefs bool
+
+	// LatestDraft fetches the latest draft of the page instead of its last
+	// published version, falling back to the published version when the
+	// page has no unpublished edits.
+	LatestDraft bool
+
+	// Source is the Source of the stored Documents, which tells apart the
+	// Documents of several sites. Defaults to the hostname of BaseURL.
+	Source string
 }
 
 // FetchPageOutput is the output of FetchPageActivity.
 type FetchPageOutput struct {
 	Document transform.Document
 	Found    bool
+	// Draft reports that Document holds the latest draft of the page.
+	Draft bool
 }
 
-// FetchPageActivity fetches a single page by ID.
-func FetchPageActivity(ctx context.Context, input FetchPageInput) (FetchPageOutput, error) {
+// FetchPageActivity fetches a single page by ID. A page that does not exist
+// is reported with Found set to false rather than as an error.
+func FetchPageActivity(ctx context.Context, input FetchPageInput) (_ FetchPageOutput, err error) {
+	defer classifyError(&err)
+
+	if err := resolveCredentials(ctx, input.Site, &input.BaseURL, &input.Email, &input.APIToken); err != nil {
+		return FetchPageOutput{}, err
+	}
+	if err := validateInput(input); err != nil {
+		return FetchPageOutput{}, err
+	}
+
 	client := NewClient(ClientConfig{
 		BaseURL:  input.BaseURL,
 		Email:    input.Email,
 		APIToken: input.APIToken,
 	})
 
-	page, err := client.GetPage(ctx, input.PageID)
-	if err != nil {
-		return FetchPageOutput{}, fmt.Errorf("get page: %w", err)
+	var page *Page
+	draft := false
+	if input.LatestDraft {
+		page, err = client.GetPageDraft(ctx, input.PageID)
+		if err != nil && !hasStatus(err, http.StatusNotFound) {
+			return FetchPageOutput{}, fmt.Errorf("get page draft: %w", err)
+		}
+		draft = page != nil
+	}
+	if page == nil {
+		page, err = client.GetPage(ctx, input.PageID)
+		if hasStatus(err, http.StatusNotFound) {
+			return FetchPageOutput{Found: false}, nil
+		}
+		if err != nil {
+			return FetchPageOutput{}, fmt.Errorf("get page: %w", err)
+		}
 	}
 
 	return FetchPageOutput{
-		Document: pageToDocument(*page, input.BaseURL),
-		Found:    true,
+		Document: pageToDocument(*page, input.BaseURL, documentSource(input.BaseURL, input.Source), ConvertOptions{
+			CollectCommentRefs: input.CollectCommentRefs,
+		}),
+		Found: true,
+		Draft: draft,
 	}, nil
 }
 
 // SearchCQLInput is the input for SearchCQLActivity.
 type SearchCQLInput struct {
-	BaseURL  string
-	Email    string
-	APIToken string
-	CQL      string
-	Limit    int
+	BaseURL  string `validate:"required,url"`
+	Email    string `validate:"required"`
+	APIToken string `validate:"required"`
+	Site     string
+	CQL      string `validate:"required"`
+
+	// Limit is the number of results requested per API call, up to the
+	// API maximum of 250. Defaults to 250.
+	Limit int `validate:"min=0"`
+
+	// MaxResults caps the number of results fetched by one call. Zero
+	// fetches every match, unless the activity defaults set MaxResults.
+	MaxResults int `validate:"min=0"`
+
+	// Cursor continues a previous search from its output Cursor.
+	Cursor string
+
+	// Excerpt is the excerpt strategy: ExcerptHighlight, ExcerptIndexed, or
+	// ExcerptNone. Excerpts are stored in the "excerpt" metadata field.
+	// Empty uses the API default, highlighted excerpts.
+	Excerpt string `validate:"oneof=|highlight|indexed|none"`
+	// OrderBy sorts the results by a field: SearchOrderLastModified,
+	// SearchOrderCreated, or SearchOrderTitle. Empty or SearchOrderRelevance
+	// keeps the API ranking. It cannot be combined with a CQL query that
+	// has its own order by clause.
+	OrderBy string `validate:"oneof=|relevance|lastmodified|created|title"`
+	// OrderDirection is cql.Ascending or cql.Descending. Defaults to
+	// descending.
+	OrderDirection string `validate:"oneof=|asc|desc"`
+	// IncludeArchivedSpaces includes the content of archived spaces.
+	IncludeArchivedSpaces bool
+
+	// Source is the Source of the stored Documents, which tells apart the
+	// Documents of several sites. Defaults to the hostname of BaseURL.
+	Source string
 }
 
+// Result orderings of SearchCQLInput.OrderBy.
+const (
+	SearchOrderRelevance    = "relevance"
+	SearchOrderLastModified = cql.FieldLastModified
+	SearchOrderCreated      = cql.FieldCreated
+	SearchOrderTitle        = cql.FieldTitle
+)
+
 // SearchCQLOutput is the output of SearchCQLActivity.
 type SearchCQLOutput struct {
 	Ref   core.DataRef
 	Count int
+
+	// Cursor continues the search where this call stopped. It is empty
+	// when every match has been fetched.
+	Cursor string
+	// HasMore reports whether matches remain after Cursor.
+	HasMore bool
+}
+
+// orderedCQL appends an order by clause for orderBy to query. The API
+// ranking is kept for an empty orderBy or SearchOrderRelevance.
+func orderedCQL(query, orderBy, direction string) (string, error) {
+	if orderBy == "" || orderBy == SearchOrderRelevance {
+		return query, nil
+	}
+	if strings.Contains(strings.ToLower(query), "order by") {
+		return "", errors.New("cql already has an order by clause")
+	}
+	if direction == "" {
+		direction = cql.Descending
+	}
+	return query + " order by " + orderBy + " " + direction, nil
 }
 
-// SearchCQLActivity searches for content using CQL and stores results.
-func SearchCQLActivity(ctx context.Context, input SearchCQLInput) (SearchCQLOutput, error) {
+// SearchCQLActivity searches for content using CQL and stores results. It
+// paginates until MaxResults is reached, or until the activity deadline is
+// near, and returns a cursor for continuing the search in a later call.
+func SearchCQLActivity(ctx context.Context, input SearchCQLInput) (_ SearchCQLOutput, err error) {
+	defer classifyError(&err)
+
+	if err := resolveCredentials(ctx, input.Site, &input.BaseURL, &input.Email, &input.APIToken); err != nil {
+		return SearchCQLOutput{}, err
+	}
+	if err := validateInput(input); err != nil {
+		return SearchCQLOutput{}, err
+	}
+	input.MaxResults = maxResults(ctx, input.MaxResults)
+
+	if err := checkCQLSyntax(input.CQL); err != nil {
+		return SearchCQLOutput{}, invalidInputError(err)
+	}
+
+	query, err := orderedCQL(input.CQL, input.OrderBy, input.OrderDirection)
+	if err != nil {
+		return SearchCQLOutput{}, invalidInputError(err)
+	}
+
 	client := NewClient(ClientConfig{
 		BaseURL:  input.BaseURL,
 		Email:    input.Email,
 		APIToken: input.APIToken,
 	})
 
-	limit := input.Limit
-	if limit <= 0 {
-		limit = 100
+	// A cursor continues a search whose first page validated the query.
+	if input.Cursor == "" {
+		if err := client.ValidateCQL(ctx, query); err != nil {
+			var cqlErr *InvalidCQLError
+			if errors.As(err, &cqlErr) {
+				return SearchCQLOutput{}, invalidInputError(err)
+			}
+			return SearchCQLOutput{}, fmt.Errorf("validate cql: %w", err)
+		}
 	}
 
-	result, err := client.SearchCQL(ctx, input.CQL, limit)
-	if err != nil {
-		return SearchCQLOutput{}, fmt.Errorf("search cql: %w", err)
+	source := documentSource(input.BaseURL, input.Source)
+
+	limit := pageSize(ctx, input.Limit)
+
+	opts := SearchOptions{
+		Excerpt:               input.Excerpt,
+		IncludeArchivedSpaces: input.IncludeArchivedSpaces,
 	}
 
-	docs := make([]transform.Document, 0, len(result.Results))
-	for _, item := range result.Results {
-		doc := pageToDocument(item.Content, input.BaseURL)
-		docs = append(docs, doc)
+	var docs []transform.Document
+	cursor := input.Cursor
+	for {
+		if input.MaxResults > 0 {
+			limit = min(limit, input.MaxResults-len(docs))
+		}
+
+		result, err := client.SearchCQLPageWithOptions(ctx, query, cursor, limit, opts)
+		if err != nil {
+			return SearchCQLOutput{}, fmt.Errorf("search cql: %w", err)
+		}
+
+		for _, item := range result.Results {
+			doc := pageToDocument(item.Content, input.BaseURL, source, ConvertOptions{})
+			if item.Excerpt != "" {
+				doc.Metadata["excerpt"] = item.Excerpt
+			}
+			docs = append(docs, doc)
+		}
+		recordHeartbeat(ctx, len(docs))
+
+		cursor = result.NextCursor()
+		if cursor == "" || len(result.Results) == 0 {
+			cursor = ""
+			break
+		}
+		if input.MaxResults > 0 && len(docs) >= input.MaxResults {
+			break
+		}
+		if nearDeadline(ctx) {
+			break
+		}
 	}
 
 	ref, err := transform.StoreDocuments(ctx, docs)
@@ -143,18 +1034,21 @@ func SearchCQLActivity(ctx context.Context, input SearchCQLInput) (SearchCQLOutp
 	}
 
 	return SearchCQLOutput{
-		Ref:   ref,
-		Count: len(docs),
+		Ref:     ref,
+		Count:   len(docs),
+		Cursor:  cursor,
+		HasMore: cursor != "",
 	}, nil
 }
 
-func pageToDocument(page Page, baseURL string) transform.Document {
-	content := stripHTML(page.Body.Storage.Value)
+func pageToDocument(page Page, baseURL, source string, opts ConvertOptions) transform.Document {
+	converted := ConvertStorage(page.Body.Storage.Value, opts)
+	content := converted.Text
 	if content == "" {
 		content = stripHTML(page.Body.View.Value)
 	}
 
-	pageURL := baseURL + page.Links.WebUI
+	pageURL := webURL(baseURL, page.Links.WebUI)
 
 	metadata := map[string]string{
 		"page_id":    page.ID,
@@ -163,43 +1057,202 @@ func pageToDocument(page Page, baseURL string) transform.Document {
 		"status":     page.Status,
 		"version":    fmt.Sprintf("%d", page.Version.Number),
 	}
+	if page.Status == StatusDraft {
+		metadata["draft"] = "true"
+	}
+	if at, ok := page.ScheduledPublishAt(); ok {
+		metadata["scheduled_publish_at"] = at.Format(time.RFC3339)
+	}
+	if page.Type != "" {
+		metadata["content_type"] = page.Type
+	}
+	if page.Space.Type != "" {
+		metadata["space_type"] = page.Space.Type
+	}
+	if len(converted.CommentRefs) > 0 {
+		metadata["inline_comment_refs"] = strings.Join(converted.CommentRefs, ",")
+	}
+	if page.Title != "" {
+		metadata["breadcrumb"] = breadcrumb(page)
+	}
+	if n := len(page.Ancestors); n > 0 {
+		metadata["parent_id"] = page.Ancestors[n-1].ID
+	}
+	if position, ok := page.Position(); ok {
+		metadata["position"] = strconv.Itoa(position)
+	}
+	if page.History != nil {
+		setUser(metadata, "created_by", page.History.CreatedBy)
+		if created := page.History.CreatedAt(); !created.IsZero() {
+			metadata["created_at"] = created.Format(time.RFC3339)
+		}
+	}
+	setUser(metadata, "last_modified_by", page.Version.By)
+	if labels := page.LabelNames(); len(labels) > 0 {
+		metadata["labels"] = strings.Join(labels, ",")
+	}
+	if page.Children != nil && page.Children.Attachment != nil {
+		setAttachmentSummary(metadata, page.Children.Attachment)
+	}
+	if page.Restrictions != nil && !page.Restrictions.Read.Restrictions.Empty() {
+		users, groups := subjectNames(page.Restrictions.Read.Restrictions)
+		metadata["restricted"] = "true"
+		metadata["restricted_users"] = strings.Join(anonymizeAccountIDs(users), ",")
+		metadata["restricted_groups"] = strings.Join(groups, ",")
+	}
 
-	return transform.Document{
+	return redactDocument(transform.Document{
 		ID:        page.ID,
 		Content:   content,
 		Title:     page.Title,
-		Source:    "confluence",
+		Source:    source,
 		URL:       pageURL,
 		Metadata:  metadata,
-		UpdatedAt: page.Version.CreatedAt,
+		UpdatedAt: page.Version.ModifiedAt(),
+	})
+}
+
+// documentSource returns the Source of Documents built from the content of
+// a site: the given alias, or the hostname of baseURL.
+func documentSource(baseURL, alias string) string {
+	if alias != "" {
+		return alias
+	}
+	if u, err := url.Parse(baseURL); err == nil && u.Hostname() != "" {
+		return u.Hostname()
+	}
+	return "confluence"
+}
+
+// setAttachmentSummary sets the number, total size in bytes, and comma
+// separated names of the attachments of a page. The expansion only returns
+// the first page of attachments, so "attachments_truncated" marks pages
+// with more.
+func setAttachmentSummary(metadata map[string]string, list *AttachmentList) {
+	if len(list.Results) == 0 {
+		return
+	}
+
+	var size int64
+	names := make([]string, 0, len(list.Results))
+	for _, attachment := range list.Results {
+		size += attachment.Extensions.FileSize
+		names = append(names, attachment.Title)
+	}
+	metadata["attachment_count"] = strconv.Itoa(len(list.Results))
+	metadata["attachment_size"] = strconv.FormatInt(size, 10)
+	metadata["attachment_names"] = strings.Join(names, ",")
+	if list.HasMore() {
+		metadata["attachments_truncated"] = "true"
+	}
+}
+
+// setUser sets the display name of a user as the key metadata field and its
+// account ID as key_account_id, anonymized as configured with
+// SetAnonymization. Unknown and dropped users are left out.
+func setUser(metadata map[string]string, key string, user User) {
+	user = anonymizeUser(user)
+	if user.AccountID == "" && user.DisplayName == "" {
+		return
+	}
+	metadata[key] = user.DisplayName
+	metadata[key+"_account_id"] = user.AccountID
+}
+
+// breadcrumbSeparator joins the segments of the "breadcrumb" metadata field.
+const breadcrumbSeparator = " > "
+
+// breadcrumb returns the location of a page as "Space > Parent > Page". It
+// relies on the space and ancestors being expanded.
+func breadcrumb(page Page) string {
+	trail := make([]string, 0, len(page.Ancestors)+2)
+	if page.Space.Name != "" {
+		trail = append(trail, page.Space.Name)
+	}
+	for _, ancestor := range page.Ancestors {
+		trail = append(trail, ancestor.Title)
+	}
+	trail = append(trail, page.Title)
+	return strings.Join(trail, breadcrumbSeparator)
+}
+
+// sinceCQL builds a CQL query for pages in a space modified since t.
+// CQL evaluates dates in the caller's time zone and only to the minute, so
+// the window is widened by a day; callers trim the results client-side.
+func sinceCQL(spaceKey string, t time.Time) string {
+	return cql.Space(spaceKey).
+		And(cql.Type(cql.TypePage), cql.LastModifiedAfter(t.Add(-24*time.Hour))).
+		OrderBy(cql.FieldLastModified, cql.Ascending).
+		String()
+}
+
+// recordHeartbeat records activity progress when running inside a Temporal
+// activity. It is a no-op otherwise, so activities can be called directly.
+func recordHeartbeat(ctx context.Context, details ...any) {
+	if activity.IsActivity(ctx) {
+		activity.RecordHeartbeat(ctx, details...)
 	}
 }
 
 var htmlTagRegex = regexp.MustCompile(`<[^>]*>`)
 
+// stripHTML removes the tags of html and decodes its common entities. &amp;
+// is decoded last, so escaped entities such as &amp;lt; keep their text.
 func stripHTML(html string) string {
 	text := htmlTagRegex.ReplaceAllString(html, " ")
 	text = strings.ReplaceAll(text, "&nbsp;", " ")
-	text = strings.ReplaceAll(text, "&amp;", "&")
 	text = strings.ReplaceAll(text, "&lt;", "<")
 	text = strings.ReplaceAll(text, "&gt;", ">")
 	text = strings.ReplaceAll(text, "&quot;", "\"")
+	text = strings.ReplaceAll(text, "&amp;", "&")
 
 	words := strings.Fields(text)
 	return strings.Join(words, " ")
 }
 
+// v1PageLimit is the default number of results of the v1 activities, which
+// fetched a single API page.
+const v1PageLimit = 100
+
+// fetchPagesV1Activity is version 1 of FetchPagesActivity, registered under
+// its former name. Unless the input sets MaxResults, which v1 did not have,
+// it fetches a single page of Limit pages, 100 by default, rather than the
+// whole space.
+func fetchPagesV1Activity(ctx context.Context, input FetchPagesInput) (FetchPagesOutput, error) {
+	if input.MaxResults == 0 {
+		if input.Limit <= 0 {
+			input.Limit = v1PageLimit
+		}
+		input.MaxResults = input.Limit
+	}
+	return FetchPagesActivity(ctx, input)
+}
+
+// searchCQLV1Activity is version 1 of SearchCQLActivity, registered under
+// its former name. Unless the input sets MaxResults, which v1 did not have,
+// it fetches a single page of Limit results, 100 by default, rather than
+// every match.
+func searchCQLV1Activity(ctx context.Context, input SearchCQLInput) (SearchCQLOutput, error) {
+	if input.MaxResults == 0 {
+		if input.Limit <= 0 {
+			input.Limit = v1PageLimit
+		}
+		input.MaxResults = input.Limit
+	}
+	return SearchCQLActivity(ctx, input)
+}
+
 // FetchPages creates a node for fetching Confluence pages.
 func FetchPages(input FetchPagesInput) *core.Node[FetchPagesInput, FetchPagesOutput] {
-	return core.NewNode("confluence.FetchPages", FetchPagesActivity, input)
+	return withPolicy(core.NewNode(VersionedName("confluence.FetchPages", 2), FetchPagesActivity, input))
 }
 
 // FetchPage creates a node for fetching a single Confluence page.
 func FetchPage(input FetchPageInput) *core.Node[FetchPageInput, FetchPageOutput] {
-	return core.NewNode("confluence.FetchPage", FetchPageActivity, input)
+	return withPolicy(core.NewNode("confluence.FetchPage", FetchPageActivity, input))
 }
 
 // SearchCQL creates a node for searching Confluence with CQL.
 func SearchCQL(input SearchCQLInput) *core.Node[SearchCQLInput, SearchCQLOutput] {
-	return core.NewNode("confluence.SearchCQL", SearchCQLActivity, input)
+	return withPolicy(core.NewNode(VersionedName("confluence.SearchCQL", 2), SearchCQLActivity, input))
 }
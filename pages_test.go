@@ -0,0 +1,127 @@
+package confluence_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/resolute-sh/resolute-confluence"
+	"github.com/resolute-sh/resolute-confluence/confluencetest"
+	transform "github.com/resolute-sh/resolute-transform"
+)
+
+func TestFetchPagesActivity(t *testing.T) {
+	srv := newServer(t)
+	srv.SetMaxPageSize(2)
+	for _, title := range []string{"Alpha", "Beta", "Gamma", "Delta", "Epsilon"} {
+		srv.AddPage(confluencetest.NewPage("ENG", title, "<p>"+title+" body</p>"))
+	}
+	restricted := srv.AddPage(confluencetest.NewPage("ENG", "Secret", "<p>hidden</p>"))
+	srv.Restrict(restricted.ID)
+	srv.AddPage(confluencetest.NewPage("OPS", "Other", "<p>other space</p>"))
+
+	out, err := confluence.FetchPagesActivity(context.Background(), confluence.FetchPagesInput{
+		BaseURL:  srv.URL,
+		Email:    srv.Email,
+		APIToken: srv.APIToken,
+		SpaceKey: "ENG",
+	})
+	if err != nil {
+		t.Fatalf("FetchPagesActivity() error = %v", err)
+	}
+	if out.Count != 5 || out.HasMore {
+		t.Errorf("Count, HasMore = %d, %t, want 5, false", out.Count, out.HasMore)
+	}
+	want := []string{"Alpha", "Beta", "Delta", "Epsilon", "Gamma"}
+	if got := loadTitles(t, out.Ref); !slices.Equal(got, want) {
+		t.Errorf("titles = %v, want %v", got, want)
+	}
+}
+
+func TestFetchPagesActivityMaxResults(t *testing.T) {
+	srv := newServer(t)
+	for _, title := range []string{"Alpha", "Beta", "Gamma"} {
+		srv.AddPage(confluencetest.NewPage("ENG", title, "<p>"+title+"</p>"))
+	}
+
+	input := confluence.FetchPagesInput{
+		BaseURL:    srv.URL,
+		Email:      srv.Email,
+		APIToken:   srv.APIToken,
+		SpaceKey:   "ENG",
+		MaxResults: 2,
+	}
+	first, err := confluence.FetchPagesActivity(context.Background(), input)
+	if err != nil {
+		t.Fatalf("FetchPagesActivity() error = %v", err)
+	}
+	if first.Count != 2 || !first.HasMore {
+		t.Fatalf("first Count, HasMore = %d, %t, want 2, true", first.Count, first.HasMore)
+	}
+
+	input.Start = first.NextStart
+	second, err := confluence.FetchPagesActivity(context.Background(), input)
+	if err != nil {
+		t.Fatalf("FetchPagesActivity() error = %v", err)
+	}
+	if second.Count != 1 || second.HasMore {
+		t.Errorf("second Count, HasMore = %d, %t, want 1, false", second.Count, second.HasMore)
+	}
+}
+
+func TestFetchPagesActivityURLs(t *testing.T) {
+	srv := newServer(t)
+	page := srv.AddPage(confluencetest.NewPage("ENG", "Runbook", "<p>Restart the service.</p>"))
+
+	out, err := confluence.FetchPagesActivity(context.Background(), confluence.FetchPagesInput{
+		BaseURL:  srv.URL,
+		Email:    srv.Email,
+		APIToken: srv.APIToken,
+		SpaceKey: "ENG",
+	})
+	if err != nil {
+		t.Fatalf("FetchPagesActivity() error = %v", err)
+	}
+	docs, err := transform.LoadDocuments(context.Background(), out.Ref)
+	if err != nil {
+		t.Fatalf("LoadDocuments() error = %v", err)
+	}
+	if want := srv.URL + "/wiki" + page.Links.WebUI; len(docs) != 1 || docs[0].URL != want {
+		t.Errorf("Documents = %+v, want one with URL %q", docs, want)
+	}
+}
+
+func TestSearchCQLActivityCursor(t *testing.T) {
+	srv := newServer(t)
+	for _, title := range []string{"Alpha", "Beta", "Gamma"} {
+		srv.AddPage(confluencetest.NewPage("ENG", title, "<p>"+title+"</p>"))
+	}
+
+	input := confluence.SearchCQLInput{
+		BaseURL:    srv.URL,
+		Email:      srv.Email,
+		APIToken:   srv.APIToken,
+		CQL:        `space = "ENG"`,
+		MaxResults: 2,
+	}
+	first, err := confluence.SearchCQLActivity(context.Background(), input)
+	if err != nil {
+		t.Fatalf("SearchCQLActivity() error = %v", err)
+	}
+	if first.Count != 2 || !first.HasMore || first.Cursor == "" {
+		t.Fatalf("first Count, HasMore, Cursor = %d, %t, %q, want 2, true, a cursor", first.Count, first.HasMore, first.Cursor)
+	}
+
+	input.Cursor = first.Cursor
+	requests := srv.Requests()
+	second, err := confluence.SearchCQLActivity(context.Background(), input)
+	if err != nil {
+		t.Fatalf("SearchCQLActivity() error = %v", err)
+	}
+	if second.Count != 1 || second.HasMore {
+		t.Errorf("second Count, HasMore = %d, %t, want 1, false", second.Count, second.HasMore)
+	}
+	if got := srv.Requests() - requests; got != 1 {
+		t.Errorf("continuation made %d requests, want 1 without validating the query again", got)
+	}
+}
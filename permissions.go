@@ -0,0 +1,372 @@
+package confluence
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	transform "github.com/resolute-sh/resolute-transform"
+	"github.com/resolute-sh/resolute/core"
+)
+
+// Access sources reported in the "access_source" metadata field.
+const (
+	AccessSourceSpace = "space"
+	AccessSourcePage  = "page"
+)
+
+// Group represents a Confluence group.
+type Group struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// UserList is a list of users embedded in permission responses.
+type UserList struct {
+	Results []User `json:"results"`
+}
+
+// GroupList is a list of groups embedded in permission responses.
+type GroupList struct {
+	Results []Group `json:"results"`
+}
+
+// PermissionSubjects lists the users and groups a permission applies to.
+type PermissionSubjects struct {
+	User  UserList  `json:"user"`
+	Group GroupList `json:"group"`
+}
+
+// Empty reports whether no user or group is listed.
+func (s PermissionSubjects) Empty() bool {
+	return len(s.User.Results) == 0 && len(s.Group.Results) == 0
+}
+
+// PermissionOperation identifies what a space permission grants.
+type PermissionOperation struct {
+	Operation  string `json:"operation"`
+	TargetType string `json:"targetType"`
+}
+
+// SpacePermission is a single permission granted on a space.
+type SpacePermission struct {
+	Subjects        PermissionSubjects  `json:"subjects"`
+	Operation       PermissionOperation `json:"operation"`
+	AnonymousAccess bool                `json:"anonymousAccess"`
+}
+
+// ContentRestriction lists the users and groups a content operation is
+// restricted to. Empty restrictions leave the operation open to everyone
+// with space access.
+type ContentRestriction struct {
+	Operation    string             `json:"operation"`
+	Restrictions PermissionSubjects `json:"restrictions"`
+}
+
+// GetSpacePermissions fetches the permissions granted on a space.
+func (c *Client) GetSpacePermissions(ctx context.Context, spaceKey string) ([]SpacePermission, error) {
+	endpoint := c.apiEndpoint(url.Values{"expand": {"permissions"}}, "space", spaceKey)
+
+	var space struct {
+		Permissions []SpacePermission `json:"permissions"`
+	}
+	if err := c.getJSON(ctx, endpoint, &space); err != nil {
+		return nil, err
+	}
+
+	return space.Permissions, nil
+}
+
+// GetReadRestriction fetches the read restriction set directly on content.
+func (c *Client) GetReadRestriction(ctx context.Context, contentID string) (*ContentRestriction, error) {
+	endpoint := c.apiEndpoint(url.Values{"expand": {"restrictions.user,restrictions.group"}},
+		"content", contentID, "restriction", "byOperation", "read")
+
+	var restriction ContentRestriction
+	if err := c.getJSON(ctx, endpoint, &restriction); err != nil {
+		return nil, err
+	}
+
+	return &restriction, nil
+}
+
+// FetchPermissionsInput is the input for FetchPermissionsActivity.
+type FetchPermissionsInput struct {
+	BaseURL  string `validate:"required,url"`
+	Email    string `validate:"required"`
+	APIToken string `validate:"required"`
+	Site     string
+
+	// DocumentsRef references the page Documents to annotate.
+	DocumentsRef core.DataRef `validate:"required"`
+}
+
+// FetchPermissionsOutput is the output of FetchPermissionsActivity.
+type FetchPermissionsOutput struct {
+	Ref   core.DataRef
+	Count int
+
+	// Restricted is the number of Documents whose page, or one of its
+	// ancestors, has read restrictions.
+	Restricted int
+
+	// Denied lists the pages, by ID, whose Documents were left out because
+	// the credentials cannot read them.
+	Denied []ItemError
+	// Errors lists the pages, by ID, whose Documents were left out because
+	// resolving their access failed with a server error or a malformed
+	// response.
+	Errors []ItemError
+}
+
+// FetchPermissionsActivity resolves who can read each Document's page and
+// adds it to the metadata: "allowed_users" holds account IDs, anonymized as
+// configured with SetAnonymization, and "allowed_groups" group names, both
+// comma separated, and "access_source" tells whether they come from the
+// space permissions or page restrictions. Pages restricted directly or
+// through an ancestor get "restricted" set to "true". The Documents of pages
+// the credentials cannot read are left out and reported in Denied, and
+// those whose access fails to resolve in Errors, without failing the batch.
+//
+// A page is readable by users who pass the read restrictions of the page
+// and of every ancestor. When several levels are restricted the allowed
+// users and groups are intersected, which errs on the side of denying
+// access. Unrestricted pages get the space read permissions, and spaces
+// open to anonymous users add "anonymous_access" metadata.
+func FetchPermissionsActivity(ctx context.Context, input FetchPermissionsInput) (_ FetchPermissionsOutput, err error) {
+	defer classifyError(&err)
+
+	if err := resolveCredentials(ctx, input.Site, &input.BaseURL, &input.Email, &input.APIToken); err != nil {
+		return FetchPermissionsOutput{}, err
+	}
+	if err := validateInput(input); err != nil {
+		return FetchPermissionsOutput{}, err
+	}
+
+	client := NewClient(ClientConfig{
+		BaseURL:  input.BaseURL,
+		Email:    input.Email,
+		APIToken: input.APIToken,
+	})
+
+	docs, err := transform.LoadDocuments(ctx, input.DocumentsRef)
+	if err != nil {
+		return FetchPermissionsOutput{}, fmt.Errorf("load documents: %w", err)
+	}
+
+	resolver := &accessResolver{
+		client:       client,
+		spaces:       make(map[string]pageAccess),
+		pages:        make(map[string]pageAccess),
+		restrictions: make(map[string]*ContentRestriction),
+	}
+
+	var output FetchPermissionsOutput
+	kept := docs[:0]
+	for i, doc := range docs {
+		pageID := documentPageID(doc)
+		if pageID == "" {
+			kept = append(kept, doc)
+			continue
+		}
+
+		access, err := resolver.page(ctx, pageID, doc.Metadata["space_key"])
+		if isInaccessible(err) {
+			output.Denied = append(output.Denied, newItemError(pageID, err))
+			continue
+		}
+		if isItemFailure(err) {
+			output.Errors = append(output.Errors, newItemError(pageID, err))
+			continue
+		}
+		if err != nil {
+			return FetchPermissionsOutput{}, err
+		}
+		if access.source == AccessSourcePage {
+			output.Restricted++
+		}
+
+		if doc.Metadata == nil {
+			doc.Metadata = make(map[string]string)
+		}
+		doc.Metadata["allowed_users"] = strings.Join(anonymizeAccountIDs(access.users), ",")
+		doc.Metadata["allowed_groups"] = strings.Join(access.groups, ",")
+		doc.Metadata["access_source"] = access.source
+		if access.source == AccessSourcePage {
+			doc.Metadata["restricted"] = "true"
+		}
+		if access.anonymous {
+			doc.Metadata["anonymous_access"] = "true"
+		}
+		kept = append(kept, doc)
+
+		recordHeartbeat(ctx, i+1)
+	}
+
+	output.Ref, err = transform.StoreDocuments(ctx, kept)
+	if err != nil {
+		return FetchPermissionsOutput{}, fmt.Errorf("store documents: %w", err)
+	}
+	output.Count = len(kept)
+	return output, nil
+}
+
+// pageAccess is the resolved read access to a page.
+type pageAccess struct {
+	users     []string
+	groups    []string
+	source    string
+	anonymous bool
+}
+
+// accessResolver resolves read access, caching space permissions and
+// content restrictions shared by several pages.
+type accessResolver struct {
+	client       *Client
+	spaces       map[string]pageAccess
+	pages        map[string]pageAccess
+	restrictions map[string]*ContentRestriction
+}
+
+func (r *accessResolver) page(ctx context.Context, pageID, spaceKey string) (pageAccess, error) {
+	if access, ok := r.pages[pageID]; ok {
+		return access, nil
+	}
+
+	page, err := r.client.GetContent(ctx, pageID, []string{"ancestors", "space"})
+	if err != nil {
+		return pageAccess{}, fmt.Errorf("get page %s: %w", pageID, err)
+	}
+	if page.Space.Key != "" {
+		spaceKey = page.Space.Key
+	}
+
+	ids := make([]string, 0, len(page.Ancestors)+1)
+	for _, ancestor := range page.Ancestors {
+		ids = append(ids, ancestor.ID)
+	}
+	ids = append(ids, pageID)
+
+	var access pageAccess
+	for _, id := range ids {
+		restriction, err := r.restriction(ctx, id)
+		if err != nil {
+			return pageAccess{}, err
+		}
+		if restriction.Restrictions.Empty() {
+			continue
+		}
+
+		users, groups := subjectNames(restriction.Restrictions)
+		if access.source == "" {
+			access = pageAccess{users: users, groups: groups, source: AccessSourcePage}
+			continue
+		}
+		access.users = intersect(access.users, users)
+		access.groups = intersect(access.groups, groups)
+	}
+
+	if access.source == "" {
+		access, err = r.space(ctx, spaceKey)
+		if err != nil {
+			return pageAccess{}, err
+		}
+	}
+
+	r.pages[pageID] = access
+	return access, nil
+}
+
+func (r *accessResolver) restriction(ctx context.Context, contentID string) (*ContentRestriction, error) {
+	if restriction, ok := r.restrictions[contentID]; ok {
+		return restriction, nil
+	}
+
+	restriction, err := r.client.GetReadRestriction(ctx, contentID)
+	if err != nil {
+		return nil, fmt.Errorf("get read restriction of %s: %w", contentID, err)
+	}
+
+	r.restrictions[contentID] = restriction
+	return restriction, nil
+}
+
+func (r *accessResolver) space(ctx context.Context, spaceKey string) (pageAccess, error) {
+	if access, ok := r.spaces[spaceKey]; ok {
+		return access, nil
+	}
+
+	permissions, err := r.client.GetSpacePermissions(ctx, spaceKey)
+	if err != nil {
+		return pageAccess{}, fmt.Errorf("get permissions of space %s: %w", spaceKey, err)
+	}
+
+	access := pageAccess{source: AccessSourceSpace}
+	var subjects PermissionSubjects
+	for _, permission := range permissions {
+		if permission.Operation.Operation != "read" || permission.Operation.TargetType != "space" {
+			continue
+		}
+		if permission.AnonymousAccess {
+			access.anonymous = true
+		}
+		subjects.User.Results = append(subjects.User.Results, permission.Subjects.User.Results...)
+		subjects.Group.Results = append(subjects.Group.Results, permission.Subjects.Group.Results...)
+	}
+	access.users, access.groups = subjectNames(subjects)
+
+	r.spaces[spaceKey] = access
+	return access, nil
+}
+
+// subjectNames returns the sorted, deduplicated account IDs and group names
+// of subjects.
+func subjectNames(subjects PermissionSubjects) (users, groups []string) {
+	for _, user := range subjects.User.Results {
+		if user.AccountID != "" {
+			users = append(users, user.AccountID)
+		}
+	}
+	for _, group := range subjects.Group.Results {
+		if group.Name != "" {
+			groups = append(groups, group.Name)
+		}
+	}
+	return dedupeSorted(users), dedupeSorted(groups)
+}
+
+func dedupeSorted(values []string) []string {
+	sort.Strings(values)
+	out := values[:0]
+	for i, v := range values {
+		if i == 0 || v != values[i-1] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// intersect returns the values present in both sorted slices.
+func intersect(a, b []string) []string {
+	var out []string
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			out = append(out, a[i])
+			i++
+			j++
+		case a[i] < b[j]:
+			i++
+		default:
+			j++
+		}
+	}
+	return out
+}
+
+// FetchPermissions creates a node for annotating Documents with read access.
+func FetchPermissions(input FetchPermissionsInput) *core.Node[FetchPermissionsInput, FetchPermissionsOutput] {
+	return withPolicy(core.NewNode("confluence.FetchPermissions", FetchPermissionsActivity, input))
+}
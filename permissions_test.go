@@ -0,0 +1,186 @@
+package confluence_test
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/resolute-sh/resolute-confluence"
+	"github.com/resolute-sh/resolute-confluence/confluencetest"
+	transform "github.com/resolute-sh/resolute-transform"
+)
+
+func TestFetchPermissionsActivityAnonymizesUsers(t *testing.T) {
+	srv := newServer(t)
+	page := confluencetest.NewPage("ENG", "Secret", "<p>secret</p>")
+	page.Restrictions = &confluence.ContentRestrictions{
+		Read: confluence.ContentRestriction{Restrictions: confluence.PermissionSubjects{
+			User: confluence.UserList{Results: []confluence.User{{AccountID: "557058:reader"}}},
+		}},
+	}
+	srv.AddPage(page)
+
+	confluence.SetAnonymization(confluence.Anonymization{Mode: confluence.AnonymizeHash, Key: "secret"})
+	t.Cleanup(func() { confluence.SetAnonymization(confluence.Anonymization{}) })
+
+	fetched, err := confluence.FetchPagesActivity(context.Background(), confluence.FetchPagesInput{
+		BaseURL:  srv.URL,
+		Email:    srv.Email,
+		APIToken: srv.APIToken,
+		SpaceKey: "ENG",
+	})
+	if err != nil {
+		t.Fatalf("FetchPagesActivity() error = %v", err)
+	}
+	out, err := confluence.FetchPermissionsActivity(context.Background(), confluence.FetchPermissionsInput{
+		BaseURL:      srv.URL,
+		Email:        srv.Email,
+		APIToken:     srv.APIToken,
+		DocumentsRef: fetched.Ref,
+	})
+	if err != nil {
+		t.Fatalf("FetchPermissionsActivity() error = %v", err)
+	}
+
+	docs, err := transform.LoadDocuments(context.Background(), out.Ref)
+	if err != nil {
+		t.Fatalf("LoadDocuments() error = %v", err)
+	}
+	allowed := docs[0].Metadata["allowed_users"]
+	if allowed == "" || strings.Contains(allowed, "557058:reader") {
+		t.Errorf("allowed_users = %q, want the hashed account ID", allowed)
+	}
+	if restricted := docs[0].Metadata["restricted_users"]; restricted != allowed {
+		t.Errorf("restricted_users = %q, want %q", restricted, allowed)
+	}
+}
+
+func TestFetchPermissionsActivityReportsFailedPages(t *testing.T) {
+	srv := newServer(t)
+	kept := srv.AddPage(confluencetest.NewPage("ENG", "Kept", "<p>kept</p>"))
+	denied := srv.AddPage(confluencetest.NewPage("ENG", "Denied", "<p>denied</p>"))
+	broken := srv.AddPage(confluencetest.NewPage("ENG", "Broken", "<p>broken</p>"))
+
+	fetched, err := confluence.FetchPagesActivity(context.Background(), confluence.FetchPagesInput{
+		BaseURL:  srv.URL,
+		Email:    srv.Email,
+		APIToken: srv.APIToken,
+		SpaceKey: "ENG",
+	})
+	if err != nil {
+		t.Fatalf("FetchPagesActivity() error = %v", err)
+	}
+	srv.Restrict(denied.ID)
+	srv.Fail(http.StatusInternalServerError, broken.ID)
+
+	out, err := confluence.FetchPermissionsActivity(context.Background(), confluence.FetchPermissionsInput{
+		BaseURL:      srv.URL,
+		Email:        srv.Email,
+		APIToken:     srv.APIToken,
+		DocumentsRef: fetched.Ref,
+	})
+	if err != nil {
+		t.Fatalf("FetchPermissionsActivity() error = %v", err)
+	}
+	if len(out.Denied) != 1 || out.Denied[0].ID != denied.ID {
+		t.Errorf("Denied = %+v, want page %s", out.Denied, denied.ID)
+	}
+	if len(out.Errors) != 1 || out.Errors[0].ID != broken.ID {
+		t.Errorf("Errors = %+v, want page %s", out.Errors, broken.ID)
+	}
+	if got := loadTitles(t, out.Ref); !slices.Equal(got, []string{kept.Title}) {
+		t.Errorf("titles = %v, want [%s]", got, kept.Title)
+	}
+}
+
+func TestFetchPermissionsActivityResolvesAccess(t *testing.T) {
+	srv := newServer(t)
+	srv.SetSpacePermissions("ENG",
+		confluence.SpacePermission{
+			Operation: confluence.PermissionOperation{Operation: "read", TargetType: "space"},
+			Subjects: confluence.PermissionSubjects{
+				User:  confluence.UserList{Results: []confluence.User{{AccountID: "bob"}, {AccountID: "alice"}}},
+				Group: confluence.GroupList{Results: []confluence.Group{{Name: "confluence-users"}}},
+			},
+		},
+		confluence.SpacePermission{
+			Operation:       confluence.PermissionOperation{Operation: "read", TargetType: "space"},
+			AnonymousAccess: true,
+		},
+		confluence.SpacePermission{
+			Operation: confluence.PermissionOperation{Operation: "administer", TargetType: "space"},
+			Subjects:  confluence.PermissionSubjects{User: confluence.UserList{Results: []confluence.User{{AccountID: "admin"}}}},
+		},
+	)
+	open := srv.AddPage(confluencetest.NewPage("ENG", "Open", "<p>open</p>"))
+	parent := confluencetest.NewPage("ENG", "Parent", "<p>parent</p>")
+	parent.Restrictions = restrictedTo([]string{"alice", "bob"}, []string{"eng"})
+	parent = srv.AddPage(parent)
+	child := confluencetest.NewPage("ENG", "Child", "<p>child</p>")
+	child.Ancestors = []confluence.Page{{ID: parent.ID}}
+	child.Restrictions = restrictedTo([]string{"bob", "carol"}, []string{"eng", "ops"})
+	child = srv.AddPage(child)
+
+	fetched, err := confluence.FetchPagesActivity(context.Background(), confluence.FetchPagesInput{
+		BaseURL:  srv.URL,
+		Email:    srv.Email,
+		APIToken: srv.APIToken,
+		SpaceKey: "ENG",
+	})
+	if err != nil {
+		t.Fatalf("FetchPagesActivity() error = %v", err)
+	}
+
+	out, err := confluence.FetchPermissionsActivity(context.Background(), confluence.FetchPermissionsInput{
+		BaseURL:      srv.URL,
+		Email:        srv.Email,
+		APIToken:     srv.APIToken,
+		DocumentsRef: fetched.Ref,
+	})
+	if err != nil {
+		t.Fatalf("FetchPermissionsActivity() error = %v", err)
+	}
+	if out.Count != 3 || out.Restricted != 2 {
+		t.Errorf("Count, Restricted = %d, %d, want 3, 2", out.Count, out.Restricted)
+	}
+
+	docs, err := transform.LoadDocuments(context.Background(), out.Ref)
+	if err != nil {
+		t.Fatalf("LoadDocuments() error = %v", err)
+	}
+	want := map[string]map[string]string{
+		open.Title: {
+			"allowed_users": "alice,bob", "allowed_groups": "confluence-users",
+			"access_source": confluence.AccessSourceSpace, "restricted": "", "anonymous_access": "true",
+		},
+		parent.Title: {
+			"allowed_users": "alice,bob", "allowed_groups": "eng",
+			"access_source": confluence.AccessSourcePage, "restricted": "true", "anonymous_access": "",
+		},
+		child.Title: {
+			"allowed_users": "bob", "allowed_groups": "eng",
+			"access_source": confluence.AccessSourcePage, "restricted": "true", "anonymous_access": "",
+		},
+	}
+	for _, doc := range docs {
+		for key, value := range want[doc.Title] {
+			if doc.Metadata[key] != value {
+				t.Errorf("%s: %s = %q, want %q", doc.Title, key, doc.Metadata[key], value)
+			}
+		}
+	}
+}
+
+// restrictedTo returns read restrictions to the users and groups.
+func restrictedTo(users, groups []string) *confluence.ContentRestrictions {
+	var subjects confluence.PermissionSubjects
+	for _, id := range users {
+		subjects.User.Results = append(subjects.User.Results, confluence.User{AccountID: id})
+	}
+	for _, name := range groups {
+		subjects.Group.Results = append(subjects.Group.Results, confluence.Group{Name: name})
+	}
+	return &confluence.ContentRestrictions{Read: confluence.ContentRestriction{Restrictions: subjects}}
+}
@@ -0,0 +1,103 @@
+package confluence
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/resolute-sh/resolute/core"
+)
+
+// IsPersonalSpaceKey reports whether a space key is the key of a personal
+// space, which starts with a tilde, such as "~5b10ac8d82e05b22cc7d4ef5".
+// Personal space keys must be quoted in CQL, where an unquoted tilde is the
+// contains operator; the cql package quotes every value.
+func IsPersonalSpaceKey(key string) bool {
+	return strings.HasPrefix(key, "~")
+}
+
+// GetPersonalSpace fetches the personal space of a user, or of the user the
+// client authenticates as when accountID is empty. It returns nil without an
+// error when the user has no personal space.
+func (c *Client) GetPersonalSpace(ctx context.Context, accountID string) (*Space, error) {
+	query := url.Values{}
+	query.Set("expand", "personalSpace")
+	endpoint := c.apiEndpoint(query, "user", "current")
+	if accountID != "" {
+		query.Set("accountId", accountID)
+		endpoint = c.apiEndpoint(query, "user")
+	}
+
+	var user struct {
+		PersonalSpace *Space `json:"personalSpace"`
+	}
+	if err := c.getJSON(ctx, endpoint, &user); err != nil {
+		return nil, err
+	}
+
+	if user.PersonalSpace == nil || user.PersonalSpace.Key == "" {
+		return nil, nil
+	}
+	return user.PersonalSpace, nil
+}
+
+// FetchPersonalSpaceInput is the input for FetchPersonalSpaceActivity.
+type FetchPersonalSpaceInput struct {
+	BaseURL  string `validate:"required,url"`
+	Email    string `validate:"required"`
+	APIToken string `validate:"required"`
+	Site     string
+
+	// AccountID is the user whose personal space is resolved. Defaults to
+	// the user of the credentials.
+	AccountID string
+}
+
+// FetchPersonalSpaceOutput is the output of FetchPersonalSpaceActivity.
+type FetchPersonalSpaceOutput struct {
+	Space Space
+	// SpaceKey is the key of the personal space, for FetchPagesInput.
+	SpaceKey string
+	Found    bool
+}
+
+// FetchPersonalSpaceActivity resolves the personal space of a user, so its
+// pages can be fetched with FetchPages like those of any other space. A
+// user without a personal space is reported with Found set to false.
+func FetchPersonalSpaceActivity(ctx context.Context, input FetchPersonalSpaceInput) (_ FetchPersonalSpaceOutput, err error) {
+	defer classifyError(&err)
+
+	if err := resolveCredentials(ctx, input.Site, &input.BaseURL, &input.Email, &input.APIToken); err != nil {
+		return FetchPersonalSpaceOutput{}, err
+	}
+	if err := validateInput(input); err != nil {
+		return FetchPersonalSpaceOutput{}, err
+	}
+
+	client := NewClient(ClientConfig{
+		BaseURL:  input.BaseURL,
+		Email:    input.Email,
+		APIToken: input.APIToken,
+	})
+
+	space, err := client.GetPersonalSpace(ctx, input.AccountID)
+	if err != nil {
+		return FetchPersonalSpaceOutput{}, fmt.Errorf("get personal space: %w", err)
+	}
+	if space == nil {
+		return FetchPersonalSpaceOutput{Found: false}, nil
+	}
+
+	return FetchPersonalSpaceOutput{
+		Space:    *space,
+		SpaceKey: space.Key,
+		Found:    true,
+	}, nil
+}
+
+// FetchPersonalSpace creates a node for resolving the personal space of a
+// Confluence user.
+func FetchPersonalSpace(input FetchPersonalSpaceInput) *core.Node[FetchPersonalSpaceInput, FetchPersonalSpaceOutput] {
+	return withPolicy(core.NewNode("confluence.FetchPersonalSpace", FetchPersonalSpaceActivity, input))
+}
@@ -0,0 +1,177 @@
+package confluence
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/resolute-sh/resolute/core"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// ErrTypeAuth is the application error type of activities that fail because
+// Confluence rejected the credentials or denied access.
+const ErrTypeAuth = "confluence.Auth"
+
+// nonRetryableErrorTypes are the application error types no retry can fix.
+var nonRetryableErrorTypes = []string{
+	ErrTypeAuth,
+	ErrTypeInvalidInput,
+	ErrTypeVersionConflict,
+	ErrTypeParentMismatch,
+	ErrTypeCopyFailed,
+	ErrTypeArchiveFailed,
+	ErrTypeAmbiguousTitle,
+	ErrTypeSpaceExportFailed,
+}
+
+// ActivityPolicy is the recommended execution policy for an activity.
+type ActivityPolicy struct {
+	StartToCloseTimeout time.Duration
+
+	// HeartbeatTimeout is zero for activities that do not heartbeat.
+	HeartbeatTimeout time.Duration
+
+	RetryPolicy core.RetryPolicy
+
+	// NonRetryableErrorTypes lists the application error types the activity
+	// fails with when retrying cannot help.
+	NonRetryableErrorTypes []string
+}
+
+// ActivityOptions returns the policy as Temporal activity options.
+func (p ActivityPolicy) ActivityOptions() workflow.ActivityOptions {
+	return workflow.ActivityOptions{
+		StartToCloseTimeout: p.StartToCloseTimeout,
+		HeartbeatTimeout:    p.HeartbeatTimeout,
+		RetryPolicy:         p.temporalRetryPolicy(),
+	}
+}
+
+func (p ActivityPolicy) temporalRetryPolicy() *temporal.RetryPolicy {
+	return &temporal.RetryPolicy{
+		InitialInterval:        p.RetryPolicy.InitialInterval,
+		BackoffCoefficient:     p.RetryPolicy.BackoffCoefficient,
+		MaximumInterval:        p.RetryPolicy.MaximumInterval,
+		MaximumAttempts:        p.RetryPolicy.MaximumAttempts,
+		NonRetryableErrorTypes: p.NonRetryableErrorTypes,
+	}
+}
+
+var (
+	// defaultRetryPolicy backs off up to a minute, which rides out the
+	// Confluence Cloud rate limits.
+	defaultRetryPolicy = core.RetryPolicy{
+		InitialInterval:    time.Second,
+		BackoffCoefficient: 2.0,
+		MaximumInterval:    time.Minute,
+		MaximumAttempts:    5,
+	}
+
+	// requestPolicy covers activities that make a handful of API calls.
+	requestPolicy = ActivityPolicy{
+		StartToCloseTimeout:    2 * time.Minute,
+		RetryPolicy:            defaultRetryPolicy,
+		NonRetryableErrorTypes: nonRetryableErrorTypes,
+	}
+
+	// batchPolicy covers activities that paginate or loop over many items
+	// and heartbeat their progress.
+	batchPolicy = ActivityPolicy{
+		StartToCloseTimeout:    30 * time.Minute,
+		HeartbeatTimeout:       2 * time.Minute,
+		RetryPolicy:            defaultRetryPolicy,
+		NonRetryableErrorTypes: nonRetryableErrorTypes,
+	}
+)
+
+// activityPolicies maps registered activity names to their policies.
+var activityPolicies = map[string]ActivityPolicy{
+	"confluence.FetchPages":                batchPolicy,
+	"confluence.FetchPage":                 requestPolicy,
+	"confluence.FetchPageByTitle":          requestPolicy,
+	"confluence.DiffVersions":              requestPolicy,
+	"confluence.FetchChangedPage":          requestPolicy,
+	"confluence.SearchCQL":                 batchPolicy,
+	"confluence.Search":                    requestPolicy,
+	"confluence.IncrementalSync":           batchPolicy,
+	"confluence.DetectDeletions":           batchPolicy,
+	"confluence.FetchPageTree":             batchPolicy,
+	"confluence.FetchBlogPosts":            batchPolicy,
+	"confluence.FetchComments":             batchPolicy,
+	"confluence.FetchSpaces":               batchPolicy,
+	"confluence.FetchPersonalSpace":        requestPolicy,
+	"confluence.CreatePage":                requestPolicy,
+	"confluence.UpdatePage":                requestPolicy,
+	"confluence.RestorePageVersion":        requestPolicy,
+	"confluence.UpsertPage":                requestPolicy,
+	"confluence.AppendToPage":              requestPolicy,
+	"confluence.DeletePages":               batchPolicy,
+	"confluence.ListTrash":                 batchPolicy,
+	"confluence.RestoreFromTrash":          batchPolicy,
+	"confluence.PurgeTrash":                batchPolicy,
+	"confluence.PublishDocuments":          batchPolicy,
+	"confluence.AddLabels":                 batchPolicy,
+	"confluence.ExportSpace":               batchPolicy,
+	"confluence.ExportSpaceArchive":        batchPolicy,
+	"confluence.ChunkDocuments":            requestPolicy,
+	"confluence.FetchContributors":         batchPolicy,
+	"confluence.FetchContentByContributor": batchPolicy,
+	"confluence.FetchPermissions":          batchPolicy,
+	"confluence.ChangesSince":              batchPolicy,
+	"confluence.RecentlyUpdated":           batchPolicy,
+	"confluence.FetchTasks":                batchPolicy,
+	"confluence.FetchAnalytics":            batchPolicy,
+	"confluence.FetchAuditLog":             batchPolicy,
+	"confluence.ConvertMarkdown":           requestPolicy,
+	"confluence.CommentOnPage":             requestPolicy,
+	"confluence.AttachFile":                requestPolicy,
+	"confluence.MovePage":                  requestPolicy,
+	"confluence.CopyPageTree":              batchPolicy,
+	"confluence.ArchiveStaleContent":       batchPolicy,
+	"confluence.FetchWhiteboards":          batchPolicy,
+	"confluence.FetchDatabases":            batchPolicy,
+	"confluence.SpaceStats":                requestPolicy,
+	"confluence.ValidateConnection":        requestPolicy,
+}
+
+// Policy returns the recommended policy for a registered activity name.
+// Unknown names get the policy for short request activities.
+func Policy(activityName string) ActivityPolicy {
+	if p, ok := activityPolicies[baseName(activityName)]; ok {
+		return p
+	}
+	return requestPolicy
+}
+
+// withPolicy applies the recommended policy of a node's activity.
+func withPolicy[I, O any](node *core.Node[I, O]) *core.Node[I, O] {
+	p := Policy(node.Name())
+	return node.
+		WithTimeout(p.StartToCloseTimeout).
+		WithRetry(p.RetryPolicy)
+}
+
+// classifyError converts authentication and authorization failures into
+// non-retryable application errors of type ErrTypeAuth, and lifts wrapped
+// application errors to the top so Temporal sees their type. Activities
+// defer it on their named error result.
+func classifyError(err *error) {
+	if *err == nil {
+		return
+	}
+	var appErr *temporal.ApplicationError
+	if errors.As(*err, &appErr) {
+		if appErr != *err {
+			*err = temporal.NewApplicationErrorWithOptions((*err).Error(), appErr.Type(), temporal.ApplicationErrorOptions{
+				NonRetryable: appErr.NonRetryable(),
+				Cause:        *err,
+			})
+		}
+		return
+	}
+	if hasStatus(*err, http.StatusUnauthorized) || hasStatus(*err, http.StatusForbidden) {
+		*err = temporal.NewNonRetryableApplicationError((*err).Error(), ErrTypeAuth, *err)
+	}
+}
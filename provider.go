@@ -2,8 +2,14 @@
 package confluence
 
 import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
 	"github.com/resolute-sh/resolute/core"
 	"go.temporal.io/sdk/worker"
+	"go.temporal.io/sdk/workflow"
 )
 
 const (
@@ -11,15 +17,325 @@ const (
 	ProviderVersion = "1.0.0"
 )
 
-// Provider returns the Confluence provider for registration.
-func Provider() core.Provider {
-	return core.NewProvider(ProviderName, ProviderVersion).
-		AddActivity("confluence.FetchPages", FetchPagesActivity).
-		AddActivity("confluence.FetchPage", FetchPageActivity).
-		AddActivity("confluence.SearchCQL", SearchCQLActivity)
+// ProviderOption configures the activities registered by Provider.
+type ProviderOption func(*providerConfig)
+
+type providerConfig struct {
+	resolver         CredentialResolver
+	sites            map[string]CredentialResolver
+	defaults         *ActivityDefaults
+	activityDefaults map[string]ActivityDefaults
+	debug            io.Writer
+	redactor         Redactor
+	anonymization    *Anonymization
+	only             map[string]bool
+	except           map[string]bool
+	readOnly         bool
+	noAliases        bool
+}
+
+// includes reports whether a registration passes the configured filters.
+// Filters match the registration by name, former name, or unversioned name.
+func (c *providerConfig) includes(r registration) bool {
+	if c.only != nil && !c.matches(c.only, r) {
+		return false
+	}
+	if c.matches(c.except, r) {
+		return false
+	}
+	return !c.readOnly || !r.writes
+}
+
+func (c *providerConfig) matches(names map[string]bool, r registration) bool {
+	for _, name := range r.names() {
+		if names[name] || names[baseName(name)] {
+			return true
+		}
+	}
+	return false
+}
+
+// registration is an activity registered by Provider.
+type registration struct {
+	name string
+	fn   core.ActivityFunc
+	// writes marks activities that modify Confluence content.
+	writes bool
+}
+
+// names returns the name of the registration and of its former versions.
+func (r registration) names() []string {
+	return append([]string{r.name}, r.formerNames()...)
+}
+
+// formerNames returns the names of the former versions of the registration.
+func (r registration) formerNames() []string {
+	var names []string
+	for _, former := range formerVersions[r.name] {
+		names = append(names, former.name)
+	}
+	return names
+}
+
+// VersionedName returns the name of version v of an activity, such as
+// "confluence.SearchCQL.v2". Versions below 2 use the base name.
+func VersionedName(name string, v int) string {
+	if v < 2 {
+		return name
+	}
+	return fmt.Sprintf("%s.v%d", name, v)
+}
+
+// baseName strips the version suffix from an activity name.
+func baseName(name string) string {
+	i := strings.LastIndex(name, ".v")
+	if i < 0 {
+		return name
+	}
+	if _, err := strconv.Atoi(name[i+2:]); err != nil {
+		return name
+	}
+	return name[:i]
+}
+
+var registrations = []registration{
+	{"confluence.FetchPages.v2", FetchPagesActivity, false},
+	{"confluence.FetchPage", FetchPageActivity, false},
+	{"confluence.FetchPageByTitle", FetchPageByTitleActivity, false},
+	{"confluence.DiffVersions", DiffVersionsActivity, false},
+	{"confluence.SearchCQL.v2", SearchCQLActivity, false},
+	{"confluence.Search", SearchActivity, false},
+	{"confluence.IncrementalSync", IncrementalSyncActivity, false},
+	{"confluence.DetectDeletions", DetectDeletionsActivity, false},
+	{"confluence.FetchPageTree", FetchPageTreeActivity, false},
+	{"confluence.FetchBlogPosts", FetchBlogPostsActivity, false},
+	{"confluence.FetchComments", FetchCommentsActivity, false},
+	{"confluence.FetchSpaces", FetchSpacesActivity, false},
+	{"confluence.FetchPersonalSpace", FetchPersonalSpaceActivity, false},
+	{"confluence.CreatePage", CreatePageActivity, true},
+	{"confluence.UpdatePage", UpdatePageActivity, true},
+	{"confluence.RestorePageVersion", RestorePageVersionActivity, true},
+	{"confluence.UpsertPage", UpsertPageActivity, true},
+	{"confluence.AppendToPage", AppendToPageActivity, true},
+	{"confluence.DeletePages", DeletePagesActivity, true},
+	{"confluence.ListTrash", ListTrashActivity, false},
+	{"confluence.RestoreFromTrash", RestoreFromTrashActivity, true},
+	{"confluence.PurgeTrash", PurgeTrashActivity, true},
+	{"confluence.PublishDocuments", PublishDocumentsActivity, true},
+	{"confluence.AddLabels", AddLabelsActivity, true},
+	{"confluence.ExportSpace", ExportSpaceActivity, false},
+	{"confluence.ExportSpaceArchive", ExportSpaceArchiveActivity, false},
+	{"confluence.ChunkDocuments", ChunkDocumentsActivity, false},
+	{"confluence.FetchContributors", FetchContributorsActivity, false},
+	{"confluence.FetchContentByContributor", FetchContentByContributorActivity, false},
+	{"confluence.FetchPermissions", FetchPermissionsActivity, false},
+	{"confluence.ChangesSince", ChangesSinceActivity, false},
+	{"confluence.RecentlyUpdated", RecentlyUpdatedActivity, false},
+	{"confluence.FetchTasks", FetchTasksActivity, false},
+	{"confluence.FetchAnalytics", FetchAnalyticsActivity, false},
+	{"confluence.FetchAuditLog", FetchAuditLogActivity, false},
+	{"confluence.ConvertMarkdown", ConvertMarkdownActivity, false},
+	{"confluence.CommentOnPage", CommentOnPageActivity, true},
+	{"confluence.AttachFile", AttachFileActivity, true},
+	{"confluence.MovePage", MovePageActivity, true},
+	{"confluence.CopyPageTree", CopyPageTreeActivity, true},
+	{"confluence.ArchiveStaleContent", ArchiveStaleContentActivity, true},
+	{"confluence.FetchWhiteboards", FetchWhiteboardsActivity, false},
+	{"confluence.FetchDatabases", FetchDatabasesActivity, false},
+	{"confluence.SpaceStats", SpaceStatsActivity, false},
+	{"confluence.ValidateConnection", ValidateConnectionActivity, false},
+	{"confluence.FetchChangedPage", FetchChangedPageActivity, false},
+}
+
+// formerVersions maps versioned activity names to the registrations of
+// their former versions, which stay registered under the former names so
+// workflows started before a version bump keep scheduling the semantics they
+// were written for. SearchCQL and FetchPages became v2 when they started
+// paginating through every result by default; v1 still fetches one page.
+var formerVersions = map[string][]registration{
+	"confluence.FetchPages.v2": {{"confluence.FetchPages", fetchPagesV1Activity, false}},
+	"confluence.SearchCQL.v2":  {{"confluence.SearchCQL", searchCQLV1Activity, false}},
+}
+
+// WithCredentials sets default credentials, used by activities whose input
+// leaves BaseURL, Email, or APIToken empty.
+func WithCredentials(creds Credentials) ProviderOption {
+	return func(c *providerConfig) {
+		c.resolver = staticCredentials(creds)
+	}
+}
+
+// WithCredentialResolver sets a resolver of default credentials, used by
+// activities whose input leaves BaseURL, Email, or APIToken empty.
+func WithCredentialResolver(resolver CredentialResolver) ProviderOption {
+	return func(c *providerConfig) {
+		c.resolver = resolver
+	}
+}
+
+// WithSites registers the credentials of several sites under aliases, so
+// activity inputs can name a site in their Site field. See SetSite.
+func WithSites(sites map[string]Credentials) ProviderOption {
+	return func(c *providerConfig) {
+		for alias, creds := range sites {
+			WithSiteResolver(alias, staticCredentials(creds))(c)
+		}
+	}
+}
+
+// WithSiteResolver registers a resolver of the credentials of a site under
+// an alias. See SetSiteResolver.
+func WithSiteResolver(alias string, resolver CredentialResolver) ProviderOption {
+	return func(c *providerConfig) {
+		if c.sites == nil {
+			c.sites = make(map[string]CredentialResolver)
+		}
+		c.sites[alias] = resolver
+	}
+}
+
+// WithDefaults sets the defaults of every activity, such as the page size
+// and request timeout, for inputs that leave them unset. See SetDefaults.
+func WithDefaults(d ActivityDefaults) ProviderOption {
+	return func(c *providerConfig) {
+		c.defaults = &d
+	}
+}
+
+// WithActivityDefaults sets the defaults of a named activity, overriding
+// those set with WithDefaults. See SetActivityDefaults.
+func WithActivityDefaults(name string, d ActivityDefaults) ProviderOption {
+	return func(c *providerConfig) {
+		if c.activityDefaults == nil {
+			c.activityDefaults = make(map[string]ActivityDefaults)
+		}
+		c.activityDefaults[name] = d
+	}
+}
+
+// WithDebugWriter dumps the requests and responses of every activity to w,
+// for diagnosing why a space or page fails to sync. See
+// ClientConfig.DebugWriter.
+func WithDebugWriter(w io.Writer) ProviderOption {
+	return func(c *providerConfig) {
+		c.debug = w
+	}
+}
+
+// WithRedactor masks sensitive strings in the Documents of every activity
+// with r. See SetRedactor.
+func WithRedactor(r Redactor) ProviderOption {
+	return func(c *providerConfig) {
+		c.redactor = r
+	}
+}
+
+// WithAnonymization hashes or drops the account IDs and user names of
+// Atlassian users in the Documents of every activity. See SetAnonymization.
+func WithAnonymization(a Anonymization) ProviderOption {
+	return func(c *providerConfig) {
+		c.anonymization = &a
+	}
+}
+
+// WithActivities registers only the named activities, such as
+// "confluence.FetchPages". Unknown names are ignored.
+func WithActivities(names ...string) ProviderOption {
+	return func(c *providerConfig) {
+		if c.only == nil {
+			c.only = make(map[string]bool)
+		}
+		for _, name := range names {
+			c.only[name] = true
+		}
+	}
+}
+
+// WithoutActivities skips registering the named activities.
+func WithoutActivities(names ...string) ProviderOption {
+	return func(c *providerConfig) {
+		if c.except == nil {
+			c.except = make(map[string]bool)
+		}
+		for _, name := range names {
+			c.except[name] = true
+		}
+	}
+}
+
+// WithoutAliases registers the current version of each activity only,
+// without its former versions. Use it once no running workflow schedules
+// activities by a former name.
+func WithoutAliases() ProviderOption {
+	return func(c *providerConfig) {
+		c.noAliases = true
+	}
+}
+
+// ReadOnly skips registering activities that create, modify, move, archive,
+// or delete Confluence content, for workers that must not write.
+func ReadOnly() ProviderOption {
+	return func(c *providerConfig) {
+		c.readOnly = true
+	}
+}
+
+// Provider returns the Confluence provider for registration. Policy returns
+// the recommended timeouts and retry policy for each registered activity.
+// ProviderCapabilities describes the registered activities.
+//
+// The credentials, sites, defaults, debug writer, redactor, and
+// anonymization given as options are package-level settings that apply to
+// every activity of the process. Provider replaces all of them, resetting
+// the ones opts leave unset, so the last call wins over earlier Provider
+// calls and the Set functions. Build one provider per process, and call the
+// Set functions after it.
+func Provider(opts ...ProviderOption) core.Provider {
+	var cfg providerConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	SetCredentialResolver(cfg.resolver)
+	setSiteResolvers(cfg.sites)
+	var defaults ActivityDefaults
+	if cfg.defaults != nil {
+		defaults = *cfg.defaults
+	}
+	SetDefaults(defaults)
+	setActivityDefaultsByName(cfg.activityDefaults)
+	SetDebugWriter(cfg.debug)
+	SetRedactor(cfg.redactor)
+	var anonymization Anonymization
+	if cfg.anonymization != nil {
+		anonymization = *cfg.anonymization
+	}
+	SetAnonymization(anonymization)
+
+	p := core.NewProvider(ProviderName, ProviderVersion)
+	for _, r := range registrations {
+		if !cfg.includes(r) {
+			continue
+		}
+		p.AddActivity(r.name, r.fn)
+		if !cfg.noAliases {
+			for _, former := range formerVersions[r.name] {
+				p.AddActivity(former.name, former.fn)
+			}
+		}
+	}
+	return p
+}
+
+// RegisterActivities registers the Confluence activities with a Temporal
+// worker, all of them unless options select a subset. Like Provider, it
+// replaces the package-level settings.
+func RegisterActivities(w worker.Worker, opts ...ProviderOption) {
+	core.RegisterProviderActivities(w, Provider(opts...))
 }
 
-// RegisterActivities registers all Confluence activities with a Temporal worker.
-func RegisterActivities(w worker.Worker) {
-	core.RegisterProviderActivities(w, Provider())
+// RegisterWorkflows registers the ready-made Confluence workflows with a
+// Temporal worker.
+func RegisterWorkflows(w worker.Worker) {
+	w.RegisterWorkflowWithOptions(SyncSpaceWorkflow, workflow.RegisterOptions{Name: SyncSpaceWorkflowName})
+	w.RegisterWorkflowWithOptions(WatchSpaceWorkflow, workflow.RegisterOptions{Name: WatchSpaceWorkflowName})
 }
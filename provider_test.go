@@ -0,0 +1,22 @@
+package confluence_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/resolute-sh/resolute-confluence"
+)
+
+func TestProviderResetsUnsetOptions(t *testing.T) {
+	confluence.Provider(confluence.WithSites(map[string]confluence.Credentials{
+		"prod": {BaseURL: "https://prod.atlassian.net", Email: "a@example.com", APIToken: "token"},
+	}))
+	if got := confluence.Sites(); !slices.Equal(got, []string{"prod"}) {
+		t.Fatalf("Sites() = %v, want [prod]", got)
+	}
+
+	confluence.Provider()
+	if got := confluence.Sites(); len(got) != 0 {
+		t.Errorf("Sites() after Provider() = %v, want none", got)
+	}
+}
@@ -0,0 +1,168 @@
+package confluence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	transform "github.com/resolute-sh/resolute-transform"
+	"github.com/resolute-sh/resolute/core"
+)
+
+// PublishDocumentsInput is the input for PublishDocumentsActivity.
+type PublishDocumentsInput struct {
+	BaseURL      string `validate:"required,url"`
+	Email        string `validate:"required"`
+	APIToken     string `validate:"required"`
+	Site         string
+	DocumentsRef core.DataRef
+	SpaceKey     string `validate:"required"`
+	ParentID     string
+
+	// Format is the format of Document content: "markdown" (default) or "storage".
+	Format string `validate:"oneof=|storage|markdown"`
+
+	// Labels are added to every published page.
+	Labels []string
+
+	// LabelKeys names metadata fields whose comma-separated values are
+	// added to the page as labels.
+	LabelKeys []string
+
+	// PropertyKeys names metadata fields stored as content properties on
+	// the page, keyed by the metadata field name.
+	PropertyKeys []string
+}
+
+// PublishedPage maps a published Document to its Confluence page.
+type PublishedPage struct {
+	DocumentID string
+	PageID     string
+	URL        string
+	Created    bool
+}
+
+// PublishDocumentsOutput is the output of PublishDocumentsActivity.
+type PublishDocumentsOutput struct {
+	Pages   []PublishedPage
+	Created int
+	Updated int
+	Errors  []ItemError
+}
+
+// PublishDocumentsActivity publishes each Document behind a DataRef as a
+// Confluence page, creating or updating pages by title. Failures are
+// reported per Document, keyed by Document ID.
+func PublishDocumentsActivity(ctx context.Context, input PublishDocumentsInput) (_ PublishDocumentsOutput, err error) {
+	defer classifyError(&err)
+
+	if err := resolveCredentials(ctx, input.Site, &input.BaseURL, &input.Email, &input.APIToken); err != nil {
+		return PublishDocumentsOutput{}, err
+	}
+	if err := validateInput(input); err != nil {
+		return PublishDocumentsOutput{}, err
+	}
+	if input.DocumentsRef.IsEmpty() {
+		return PublishDocumentsOutput{}, invalidInputError(errors.New("DocumentsRef is required"))
+	}
+	if input.DocumentsRef.Schema != transform.SchemaDocuments {
+		return PublishDocumentsOutput{}, invalidInputError(fmt.Errorf("DocumentsRef has schema %q, not %q", input.DocumentsRef.Schema, transform.SchemaDocuments))
+	}
+
+	docs, err := transform.LoadDocuments(ctx, input.DocumentsRef)
+	if err != nil {
+		return PublishDocumentsOutput{}, fmt.Errorf("load documents: %w", err)
+	}
+
+	client := NewClient(ClientConfig{
+		BaseURL:  input.BaseURL,
+		Email:    input.Email,
+		APIToken: input.APIToken,
+	})
+
+	format := input.Format
+	if format == "" {
+		format = BodyFormatMarkdown
+	}
+
+	var output PublishDocumentsOutput
+	for i, doc := range docs {
+		page, err := publishDocument(ctx, client, input, doc, format)
+		if err != nil {
+			output.Errors = append(output.Errors, newItemError(doc.ID, err))
+		} else {
+			output.Pages = append(output.Pages, page)
+			if page.Created {
+				output.Created++
+			} else {
+				output.Updated++
+			}
+		}
+		recordHeartbeat(ctx, i+1)
+	}
+
+	return output, nil
+}
+
+func publishDocument(ctx context.Context, client *Client, input PublishDocumentsInput, doc transform.Document, format string) (PublishedPage, error) {
+	title := doc.Title
+	if title == "" {
+		title = doc.ID
+	}
+
+	upserted, err := UpsertPageActivity(ctx, UpsertPageInput{
+		BaseURL:  input.BaseURL,
+		Email:    input.Email,
+		APIToken: input.APIToken,
+		SpaceKey: input.SpaceKey,
+		Title:    title,
+		ParentID: input.ParentID,
+		Body:     doc.Content,
+		Format:   format,
+	})
+	if err != nil {
+		return PublishedPage{}, err
+	}
+
+	labels := make([]string, 0, len(input.Labels))
+	for _, label := range input.Labels {
+		if label := labelName(label); label != "" {
+			labels = append(labels, label)
+		}
+	}
+	for _, key := range input.LabelKeys {
+		for _, value := range strings.Split(doc.Metadata[key], ",") {
+			if label := labelName(value); label != "" {
+				labels = append(labels, label)
+			}
+		}
+	}
+	if len(labels) > 0 {
+		if err := client.AddLabels(ctx, upserted.PageID, labels); err != nil {
+			return PublishedPage{}, fmt.Errorf("add labels: %w", err)
+		}
+	}
+
+	for _, key := range input.PropertyKeys {
+		value, ok := doc.Metadata[key]
+		if !ok {
+			continue
+		}
+		if err := client.SetContentProperty(ctx, upserted.PageID, key, value); err != nil {
+			return PublishedPage{}, fmt.Errorf("set property %s: %w", key, err)
+		}
+	}
+
+	return PublishedPage{
+		DocumentID: doc.ID,
+		PageID:     upserted.PageID,
+		URL:        upserted.URL,
+		Created:    upserted.Created,
+	}, nil
+}
+
+// PublishDocuments creates a node for publishing Documents to Confluence.
+func PublishDocuments(input PublishDocumentsInput) *core.Node[PublishDocumentsInput, PublishDocumentsOutput] {
+	return withPolicy(core.NewNode("confluence.PublishDocuments", PublishDocumentsActivity, input))
+}
@@ -0,0 +1,50 @@
+package confluence_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/resolute-sh/resolute-confluence"
+	transform "github.com/resolute-sh/resolute-transform"
+)
+
+func TestPublishDocumentsActivityLabels(t *testing.T) {
+	srv := newServer(t)
+	srv.AddSpace(confluence.Space{Key: "ENG"})
+
+	ref, err := transform.StoreDocuments(context.Background(), []transform.Document{{
+		ID:       "report-1",
+		Title:    "Weekly report",
+		Content:  "All services **green**.",
+		Metadata: map[string]string{"teams": "Platform, ,Data", "owner": "platform"},
+	}})
+	if err != nil {
+		t.Fatalf("StoreDocuments() error = %v", err)
+	}
+
+	out, err := confluence.PublishDocumentsActivity(context.Background(), confluence.PublishDocumentsInput{
+		BaseURL:      srv.URL,
+		Email:        srv.Email,
+		APIToken:     srv.APIToken,
+		DocumentsRef: ref,
+		SpaceKey:     "ENG",
+		Labels:       []string{"Generated", "", "  "},
+		LabelKeys:    []string{"teams"},
+		PropertyKeys: []string{"owner"},
+	})
+	if err != nil {
+		t.Fatalf("PublishDocumentsActivity() error = %v", err)
+	}
+	if out.Created != 1 || len(out.Errors) > 0 {
+		t.Fatalf("Created, Errors = %d, %+v, want 1 page created", out.Created, out.Errors)
+	}
+
+	page, _ := srv.Page(out.Pages[0].PageID)
+	if want := []string{"generated", "platform", "data"}; !slices.Equal(page.LabelNames(), want) {
+		t.Errorf("labels = %v, want %v", page.LabelNames(), want)
+	}
+	if property := page.Metadata.Properties["owner"]; property.Value != "platform" {
+		t.Errorf("owner property = %v, want platform", property.Value)
+	}
+}
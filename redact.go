@@ -0,0 +1,129 @@
+package confluence
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+
+	transform "github.com/resolute-sh/resolute-transform"
+)
+
+// Redacted replaces the strings masked by the built-in redactors.
+const Redacted = "[REDACTED]"
+
+// A Redactor masks the sensitive strings of a text, such as email addresses
+// and tokens, for compliance-sensitive ingestion.
+type Redactor func(text string) string
+
+// Patterns of sensitive strings, for RedactPatterns.
+var (
+	PatternEmail = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	// PatternAtlassianToken matches Atlassian API tokens.
+	PatternAtlassianToken = regexp.MustCompile(`\bATATT[A-Za-z0-9_\-=]{20,}`)
+	// PatternBearerToken matches bearer tokens of Authorization headers.
+	PatternBearerToken = regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/\-]+=*`)
+	// PatternAWSAccessKey matches AWS access key IDs.
+	PatternAWSAccessKey = regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)
+	// PatternPrivateKey matches PEM-encoded private keys.
+	PatternPrivateKey = regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)
+)
+
+// DefaultRedactionPatterns are the patterns RedactPatterns masks when given
+// none.
+var DefaultRedactionPatterns = []*regexp.Regexp{
+	PatternEmail,
+	PatternAtlassianToken,
+	PatternBearerToken,
+	PatternAWSAccessKey,
+	PatternPrivateKey,
+}
+
+// RedactPatterns returns a Redactor replacing every match of patterns with
+// Redacted, or of DefaultRedactionPatterns when patterns is empty.
+func RedactPatterns(patterns ...*regexp.Regexp) Redactor {
+	if len(patterns) == 0 {
+		patterns = DefaultRedactionPatterns
+	}
+	return func(text string) string {
+		for _, pattern := range patterns {
+			text = pattern.ReplaceAllLiteralString(text, Redacted)
+		}
+		return text
+	}
+}
+
+// RedactTerms returns a Redactor replacing every occurrence of a deny-listed
+// term with Redacted, ignoring case. Empty terms are ignored.
+func RedactTerms(terms ...string) Redactor {
+	quoted := make([]string, 0, len(terms))
+	for _, term := range terms {
+		if term != "" {
+			quoted = append(quoted, regexp.QuoteMeta(term))
+		}
+	}
+	if len(quoted) == 0 {
+		return func(text string) string { return text }
+	}
+	return RedactPatterns(regexp.MustCompile(`(?i)` + strings.Join(quoted, "|")))
+}
+
+// ChainRedactors returns a Redactor applying redactors in order. Nil
+// redactors are skipped.
+func ChainRedactors(redactors ...Redactor) Redactor {
+	return func(text string) string {
+		for _, redact := range redactors {
+			if redact != nil {
+				text = redact(text)
+			}
+		}
+		return text
+	}
+}
+
+var (
+	redactorMu sync.RWMutex
+	redactor   Redactor
+)
+
+// SetRedactor sets the Redactor applied to the Documents built by every
+// activity before they are stored or returned. A nil Redactor turns
+// redaction off. Provider's WithRedactor option is equivalent.
+//
+//	confluence.SetRedactor(confluence.ChainRedactors(
+//		confluence.RedactPatterns(),
+//		confluence.RedactTerms("Project Nightingale"),
+//	))
+func SetRedactor(r Redactor) {
+	redactorMu.Lock()
+	defer redactorMu.Unlock()
+	redactor = r
+}
+
+// currentRedactor returns the Redactor set with SetRedactor.
+func currentRedactor() Redactor {
+	redactorMu.RLock()
+	defer redactorMu.RUnlock()
+	return redactor
+}
+
+// redactedMetadata lists the metadata fields holding free text, which are
+// redacted along with the Content and Title of Documents.
+var redactedMetadata = []string{"breadcrumb", "excerpt", "original_selection"}
+
+// redactDocument applies the Redactor set with SetRedactor to the text of
+// doc.
+func redactDocument(doc transform.Document) transform.Document {
+	redact := currentRedactor()
+	if redact == nil {
+		return doc
+	}
+
+	doc.Content = redact(doc.Content)
+	doc.Title = redact(doc.Title)
+	for _, key := range redactedMetadata {
+		if value, ok := doc.Metadata[key]; ok {
+			doc.Metadata[key] = redact(value)
+		}
+	}
+	return doc
+}
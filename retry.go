@@ -0,0 +1,194 @@
+package confluence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Jitter strategies of RequestRetryPolicy.
+const (
+	// JitterFull waits a random duration between zero and the backoff,
+	// which spreads the retries of concurrent clients the most.
+	JitterFull = "full"
+	// JitterEqual waits half the backoff plus a random duration up to the
+	// other half.
+	JitterEqual = "equal"
+	// JitterNone waits the backoff exactly.
+	JitterNone = "none"
+)
+
+// Defaults of RequestRetryPolicy.
+const (
+	defaultInitialBackoff = 500 * time.Millisecond
+	defaultMaxBackoff     = 30 * time.Second
+)
+
+// DefaultRetryableStatuses are the status codes retried when
+// RequestRetryPolicy.RetryableStatuses is empty: 429 Too Many Requests and
+// the gateway errors of transient outages.
+var DefaultRetryableStatuses = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RequestRetryPolicy configures how a client retries failed API requests
+// before returning the error to the activity, whose own retry policy then
+// applies. Keep the two aligned: client retries should fit well within the
+// activity timeout, and the fewer the activity attempts, the more the client
+// may retry. The zero RequestRetryPolicy does not retry.
+//
+// Requests are retried after transport errors and responses with a
+// retryable status. POST requests, which are not idempotent, are only
+// retried on 429, which the API answers without processing the request.
+type RequestRetryPolicy struct {
+	// MaxAttempts is the number of attempts of a request, the first one
+	// included. Zero or one does not retry.
+	MaxAttempts int
+	// MaxElapsed stops retrying once a retry would start this long after
+	// the first attempt. Zero only bounds retries by MaxAttempts and the
+	// context.
+	MaxElapsed time.Duration
+
+	// InitialBackoff is the backoff before the first retry, doubled for
+	// every later retry. Defaults to 500 milliseconds.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the backoff. Defaults to 30 seconds. A longer
+	// Retry-After header of the response is still honored.
+	MaxBackoff time.Duration
+	// Jitter randomizes the backoff to avoid retry storms: JitterFull,
+	// JitterEqual, or JitterNone. Defaults to JitterFull.
+	Jitter string
+
+	// RetryableStatuses lists the response status codes that are retried.
+	// Defaults to DefaultRetryableStatuses.
+	RetryableStatuses []int
+}
+
+// validate reports the invalid fields of p.
+func (p RequestRetryPolicy) validate() error {
+	var errs []error
+	if p.MaxAttempts < 0 {
+		errs = append(errs, errors.New("retry max attempts must not be negative"))
+	}
+	if p.MaxElapsed < 0 || p.InitialBackoff < 0 || p.MaxBackoff < 0 {
+		errs = append(errs, errors.New("retry durations must not be negative"))
+	}
+	switch p.Jitter {
+	case "", JitterFull, JitterEqual, JitterNone:
+	default:
+		errs = append(errs, fmt.Errorf("unknown retry jitter %q", p.Jitter))
+	}
+	for _, status := range p.RetryableStatuses {
+		if status < 400 || status > 599 {
+			errs = append(errs, fmt.Errorf("retryable status %d is not an error status", status))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// retryable reports whether a request with method that failed with err may
+// be retried.
+func (p RequestRetryPolicy) retryable(method string, err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		if method == http.MethodPost {
+			return apiErr.Status == http.StatusTooManyRequests
+		}
+		statuses := p.RetryableStatuses
+		if len(statuses) == 0 {
+			statuses = DefaultRetryableStatuses
+		}
+		return slices.Contains(statuses, apiErr.Status)
+	}
+	var urlErr *url.Error
+	return method != http.MethodPost && errors.As(err, &urlErr)
+}
+
+// backoff returns how long to wait before retry n, counted from one, of a
+// request that failed with err.
+func (p RequestRetryPolicy) backoff(n int, err error) time.Duration {
+	initial := p.InitialBackoff
+	if initial <= 0 {
+		initial = defaultInitialBackoff
+	}
+	limit := p.MaxBackoff
+	if limit <= 0 {
+		limit = defaultMaxBackoff
+	}
+
+	wait := limit
+	if shift := n - 1; shift < 32 && initial<<shift > 0 && initial<<shift < limit {
+		wait = initial << shift
+	}
+	switch p.Jitter {
+	case JitterNone:
+	case JitterEqual:
+		wait = wait/2 + rand.N(wait/2+1)
+	default:
+		wait = rand.N(wait + 1)
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.retryAfter > wait {
+		wait = apiErr.retryAfter
+	}
+	return wait
+}
+
+// retryPolicy returns the retry policy of the client, or the one of the
+// activity defaults when the client config sets none.
+func (c *Client) retryPolicy(ctx context.Context) RequestRetryPolicy {
+	if c.retry.MaxAttempts > 0 {
+		return c.retry
+	}
+	return activityDefaults(ctx).Retry
+}
+
+// withRetry calls attempt until it succeeds or the retry policy of the
+// client gives up, and returns the error of the last attempt.
+func (c *Client) withRetry(ctx context.Context, method string, attempt func() error) error {
+	policy := c.retryPolicy(ctx)
+	started := time.Now()
+	for n := 1; ; n++ {
+		err := attempt()
+		if err == nil || n >= policy.MaxAttempts || ctx.Err() != nil || !policy.retryable(method, err) {
+			return err
+		}
+
+		wait := policy.backoff(n, err)
+		if policy.MaxElapsed > 0 && time.Since(started)+wait > policy.MaxElapsed {
+			return err
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// parseRetryAfter parses a Retry-After header, given in seconds or as an
+// HTTP date. It returns zero for a missing or invalid header.
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		return max(time.Until(at), 0)
+	}
+	return 0
+}
@@ -0,0 +1,99 @@
+package confluence_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/resolute-sh/resolute-confluence"
+	"github.com/resolute-sh/resolute-confluence/confluencetest"
+)
+
+// newRetryClient returns a client of srv that makes up to attempts
+// attempts per request, retrying without backoff.
+func newRetryClient(srv *confluencetest.Server, attempts int) *confluence.Client {
+	return confluence.NewClient(confluence.ClientConfig{
+		BaseURL:  srv.URL,
+		Email:    srv.Email,
+		APIToken: srv.APIToken,
+		Retry: confluence.RequestRetryPolicy{
+			MaxAttempts:    attempts,
+			InitialBackoff: time.Millisecond,
+			Jitter:         confluence.JitterNone,
+		},
+	})
+}
+
+func TestClientRetriesThrottledRequests(t *testing.T) {
+	srv := newServer(t)
+	srv.AddSpace(confluence.Space{Key: "ENG", Name: "Engineering"})
+	srv.Throttle(2, 0)
+
+	space, err := newRetryClient(srv, 3).GetSpace(context.Background(), "ENG")
+	if err != nil {
+		t.Fatalf("GetSpace() error = %v", err)
+	}
+	if space.Key != "ENG" {
+		t.Errorf("GetSpace() key = %q, want ENG", space.Key)
+	}
+	if got := srv.Requests(); got != 3 {
+		t.Errorf("Requests() = %d, want 3", got)
+	}
+}
+
+func TestClientGivesUpAfterMaxAttempts(t *testing.T) {
+	srv := newServer(t)
+	srv.AddSpace(confluence.Space{Key: "ENG", Name: "Engineering"})
+	srv.Throttle(2, 0)
+
+	_, err := newRetryClient(srv, 2).GetSpace(context.Background(), "ENG")
+	var apiErr *confluence.APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusTooManyRequests {
+		t.Fatalf("GetSpace() error = %v, want a 429 APIError", err)
+	}
+	if got := srv.Requests(); got != 2 {
+		t.Errorf("Requests() = %d, want 2", got)
+	}
+}
+
+func TestClientHonorsRetryAfter(t *testing.T) {
+	srv := newServer(t)
+	srv.AddSpace(confluence.Space{Key: "ENG", Name: "Engineering"})
+	srv.Throttle(1, time.Second)
+
+	started := time.Now()
+	if _, err := newRetryClient(srv, 2).GetSpace(context.Background(), "ENG"); err != nil {
+		t.Fatalf("GetSpace() error = %v", err)
+	}
+	if elapsed := time.Since(started); elapsed < 900*time.Millisecond {
+		t.Errorf("retry after %v, want at least the 1s Retry-After", elapsed)
+	}
+}
+
+func TestFetchPagesActivityRetriesThrottledRequests(t *testing.T) {
+	srv := newServer(t)
+	srv.SetMaxPageSize(1)
+	srv.AddPage(confluencetest.NewPage("ENG", "Alpha", "<p>alpha</p>"))
+	srv.AddPage(confluencetest.NewPage("ENG", "Beta", "<p>beta</p>"))
+	srv.Throttle(1, 0)
+
+	confluence.SetDefaults(confluence.ActivityDefaults{
+		Retry: confluence.RequestRetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond},
+	})
+	t.Cleanup(func() { confluence.SetDefaults(confluence.ActivityDefaults{}) })
+
+	out, err := confluence.FetchPagesActivity(context.Background(), confluence.FetchPagesInput{
+		BaseURL:  srv.URL,
+		Email:    srv.Email,
+		APIToken: srv.APIToken,
+		SpaceKey: "ENG",
+	})
+	if err != nil {
+		t.Fatalf("FetchPagesActivity() error = %v", err)
+	}
+	if out.Count != 2 {
+		t.Errorf("Count = %d, want 2", out.Count)
+	}
+}
@@ -0,0 +1,159 @@
+package confluence
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/resolute-sh/resolute-confluence/cql"
+	transform "github.com/resolute-sh/resolute-transform"
+	"github.com/resolute-sh/resolute/core"
+)
+
+// defaultSearchResults is the number of results SearchActivity returns when
+// the input sets no MaxResults.
+const defaultSearchResults = 25
+
+// searchExpand lists the properties expanded on search results, enough to
+// locate each result without fetching its body.
+var searchExpand = []string{"space", "version", "ancestors"}
+
+// SearchInput is the input for SearchActivity.
+type SearchInput struct {
+	BaseURL  string `validate:"required,url"`
+	Email    string `validate:"required"`
+	APIToken string `validate:"required"`
+	Site     string
+
+	// Query is the text to search for, as typed in the site search box.
+	Query string `validate:"required"`
+
+	// SpaceKeys restricts the search to these spaces. Empty searches every
+	// space the user can see.
+	SpaceKeys []string
+	// Types restricts the search to these content types. Defaults to pages
+	// and blog posts.
+	Types []string
+	// IncludeArchivedSpaces searches archived spaces too.
+	IncludeArchivedSpaces bool
+
+	// MaxResults is the number of results returned. Defaults to 25.
+	MaxResults int `validate:"min=0"`
+	// Excerpt is the excerpt strategy: "highlight", "indexed", or "none".
+	// Empty uses the API default, highlighted excerpts.
+	Excerpt string `validate:"oneof=|highlight|indexed|none"`
+
+	// Source is the Source of the returned Documents, which tells apart the
+	// Documents of several sites. Defaults to the hostname of BaseURL.
+	Source string
+}
+
+// SearchOutput is the output of SearchActivity.
+type SearchOutput struct {
+	// Documents lists the results by relevance. Their Content is the
+	// excerpt of the result rather than its body.
+	Documents []transform.Document
+	Count     int
+	// TotalSize is the number of matches reported by the API, which may
+	// exceed Count.
+	TotalSize int
+}
+
+// SearchActivity runs a text query across spaces with the site search and
+// returns the best matches as lightweight Documents holding excerpts, for
+// on-demand retrieval rather than ingestion. Fetch the bodies of the
+// results worth reading with FetchPage. The Documents are returned in the
+// output rather than stored, so keep MaxResults small.
+func SearchActivity(ctx context.Context, input SearchInput) (_ SearchOutput, err error) {
+	defer classifyError(&err)
+
+	if err := resolveCredentials(ctx, input.Site, &input.BaseURL, &input.Email, &input.APIToken); err != nil {
+		return SearchOutput{}, err
+	}
+	if err := validateInput(input); err != nil {
+		return SearchOutput{}, err
+	}
+
+	client := NewClient(ClientConfig{
+		BaseURL:  input.BaseURL,
+		Email:    input.Email,
+		APIToken: input.APIToken,
+	})
+
+	source := documentSource(input.BaseURL, input.Source)
+	maxResults := input.MaxResults
+	if maxResults == 0 {
+		maxResults = defaultSearchResults
+	}
+	types := input.Types
+	if len(types) == 0 {
+		types = []string{cql.TypePage, cql.TypeBlogPost}
+	}
+
+	query := cql.SiteSearch(input.Query).And(cql.TypeIn(types...))
+	if len(input.SpaceKeys) > 0 {
+		query = query.And(cql.SpaceIn(input.SpaceKeys...))
+	}
+
+	opts := SearchOptions{
+		Excerpt:               input.Excerpt,
+		IncludeArchivedSpaces: input.IncludeArchivedSpaces,
+		Expand:                searchExpand,
+	}
+
+	var output SearchOutput
+	cursor := ""
+	for len(output.Documents) < maxResults {
+		result, err := client.SearchCQLPageWithOptions(ctx, query.String(), cursor, pageSize(ctx, maxResults-len(output.Documents)), opts)
+		if err != nil {
+			return SearchOutput{}, fmt.Errorf("search: %w", err)
+		}
+		output.TotalSize = result.TotalSize
+
+		for _, item := range result.Results {
+			if len(output.Documents) == maxResults {
+				break
+			}
+			output.Documents = append(output.Documents, searchResultDocument(item, input.BaseURL, source, len(output.Documents)+1))
+		}
+
+		cursor = result.NextCursor()
+		if cursor == "" || len(result.Results) == 0 {
+			break
+		}
+	}
+	output.Count = len(output.Documents)
+
+	return output, nil
+}
+
+// searchResultDocument converts a search result into a Document whose
+// Content is the plain text of its excerpt. The raw excerpt is kept in the
+// "excerpt" metadata field and the position of the result in "rank".
+func searchResultDocument(item SearchResultItem, baseURL, source string, rank int) transform.Document {
+	doc := pageToDocument(item.Content, baseURL, source, ConvertOptions{})
+	doc.Content = excerptText(item.Excerpt)
+	if doc.Title == "" {
+		doc.Title = item.Title
+	}
+	if item.Excerpt != "" {
+		doc.Metadata["excerpt"] = item.Excerpt
+	}
+	doc.Metadata["rank"] = strconv.Itoa(rank)
+	return redactDocument(doc)
+}
+
+// excerptHighlightMarkers are the markers around the matched words of
+// highlighted excerpts.
+var excerptHighlightMarkers = strings.NewReplacer("@@@hl@@@", "", "@@@endhl@@@", "")
+
+// excerptText returns the plain text of an excerpt.
+func excerptText(excerpt string) string {
+	return stripHTML(excerptHighlightMarkers.Replace(excerpt))
+}
+
+// Search creates a node for searching Confluence with the site search.
+func Search(input SearchInput) *core.Node[SearchInput, SearchOutput] {
+	return withPolicy(core.NewNode("confluence.Search", SearchActivity, input))
+}
@@ -0,0 +1,41 @@
+package confluence_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/resolute-sh/resolute-confluence/confluencetest"
+	transform "github.com/resolute-sh/resolute-transform"
+	"github.com/resolute-sh/resolute/core"
+)
+
+// newServer starts a fake Confluence site and stores the Documents of the
+// test in a temporary directory.
+func newServer(t *testing.T) *confluencetest.Server {
+	t.Helper()
+	local, err := core.NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStorage() error = %v", err)
+	}
+	core.SetStorage(core.NewStorage(local))
+
+	srv := confluencetest.NewServer()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// loadTitles returns the sorted titles of the Documents stored under ref.
+func loadTitles(t *testing.T, ref core.DataRef) []string {
+	t.Helper()
+	docs, err := transform.LoadDocuments(context.Background(), ref)
+	if err != nil {
+		t.Fatalf("LoadDocuments() error = %v", err)
+	}
+	titles := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		titles = append(titles, doc.Title)
+	}
+	sort.Strings(titles)
+	return titles
+}
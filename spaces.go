@@ -0,0 +1,361 @@
+package confluence
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/resolute-sh/resolute-confluence/cql"
+	"github.com/resolute-sh/resolute/core"
+)
+
+// SchemaSpaces is the DataRef schema for stored Space slices.
+const SchemaSpaces = "confluence.Space"
+
+// ListSpacesOptions filters space listings.
+type ListSpacesOptions struct {
+	// Type is "global" or "personal". Empty lists both.
+	Type string
+	// Status is "current" or "archived". Empty lists current spaces, and
+	// archived ones too with IncludeArchivedSpaces.
+	Status string
+	// Labels restricts the listing to spaces with any of these labels.
+	Labels []string
+	// Categories restricts the listing to spaces in any of these space
+	// categories. It is matched against the labels expanded with each space,
+	// which the API may truncate for spaces with many labels.
+	Categories []string
+	// ExcludeLabels leaves out spaces with any of these labels or
+	// categories.
+	ExcludeLabels []string
+	// IncludeArchivedSpaces lists archived spaces along with current ones
+	// when Status is empty.
+	IncludeArchivedSpaces bool
+}
+
+// matches reports whether a space with expanded labels passes the
+// category and exclusion filters, which the API does not support.
+func (opts ListSpacesOptions) matches(space Space) bool {
+	if len(opts.Categories) > 0 && !containsAny(space.Categories(), opts.Categories) {
+		return false
+	}
+	if len(opts.ExcludeLabels) > 0 {
+		if containsAny(space.LabelNames(), opts.ExcludeLabels) || containsAny(space.Categories(), opts.ExcludeLabels) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsAny reports whether any of values is in names, ignoring case as
+// Confluence labels are lowercase.
+func containsAny(names, values []string) bool {
+	for _, name := range names {
+		for _, value := range values {
+			if strings.EqualFold(name, value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SpaceList represents a single page of space results.
+type SpaceList struct {
+	Results []Space   `json:"results"`
+	Start   int       `json:"start"`
+	Limit   int       `json:"limit"`
+	Size    int       `json:"size"`
+	Links   ListLinks `json:"_links"`
+}
+
+// HasMore reports whether another page of results is available.
+func (l *SpaceList) HasMore() bool {
+	return l.Links.Next != ""
+}
+
+// ListSpaces fetches one page of spaces matching opts starting at offset
+// start, with their labels. Spaces left out by the Categories and
+// ExcludeLabels filters are dropped from Results, while Size still counts
+// them, so callers advance start by Size.
+func (c *Client) ListSpaces(ctx context.Context, opts ListSpacesOptions, start, limit int) (*SpaceList, error) {
+	if limit <= 0 {
+		limit = maxPageSize
+	}
+
+	query := url.Values{}
+	query.Set("start", strconv.Itoa(start))
+	query.Set("limit", strconv.Itoa(limit))
+	if opts.Type != "" {
+		query.Set("type", opts.Type)
+	}
+	switch {
+	case opts.Status != "":
+		query.Set("status", opts.Status)
+	case !opts.IncludeArchivedSpaces:
+		query.Set("status", "current")
+	}
+	for _, label := range opts.Labels {
+		query.Add("label", label)
+	}
+	query.Set("expand", "metadata.labels")
+
+	endpoint := c.apiEndpoint(query, "space")
+
+	var list SpaceList
+	if err := c.getJSON(ctx, endpoint, &list); err != nil {
+		return nil, err
+	}
+
+	matched := list.Results[:0]
+	for _, space := range list.Results {
+		if opts.matches(space) {
+			matched = append(matched, space)
+		}
+	}
+	list.Results = matched
+
+	return &list, nil
+}
+
+// GetSpace fetches a space by key.
+func (c *Client) GetSpace(ctx context.Context, spaceKey string) (*Space, error) {
+	endpoint := c.apiEndpoint(nil, "space", spaceKey)
+
+	var space Space
+	if err := c.getJSON(ctx, endpoint, &space); err != nil {
+		return nil, err
+	}
+
+	return &space, nil
+}
+
+// listAllSpaces collects every space matching opts.
+func listAllSpaces(ctx context.Context, client *Client, opts ListSpacesOptions, limit int) ([]Space, error) {
+	var spaces []Space
+	start := 0
+	for {
+		list, err := client.ListSpaces(ctx, opts, start, limit)
+		if err != nil {
+			return nil, fmt.Errorf("list spaces at %d: %w", start, err)
+		}
+
+		spaces = append(spaces, list.Results...)
+
+		start += list.Size
+		if !list.HasMore() || list.Size == 0 {
+			break
+		}
+	}
+	return spaces, nil
+}
+
+// FetchSpacesInput is the input for FetchSpacesActivity.
+type FetchSpacesInput struct {
+	BaseURL  string `validate:"required,url"`
+	Email    string `validate:"required"`
+	APIToken string `validate:"required"`
+	Site     string
+
+	// Type is "global" or "personal". Empty lists both.
+	Type string
+	// Status is "current" or "archived". Empty lists current spaces, and
+	// archived ones too with IncludeArchivedSpaces.
+	Status string
+	// Labels restricts the listing to spaces with any of these labels.
+	Labels []string
+	// Categories restricts the listing to spaces in any of these space
+	// categories.
+	Categories []string
+	// ExcludeLabels leaves out spaces with any of these labels or
+	// categories.
+	ExcludeLabels []string
+	// IncludeArchivedSpaces lists archived spaces along with current ones,
+	// for compliance exports. Normal syncs leave it unset.
+	IncludeArchivedSpaces bool
+
+	// Limit is the number of spaces requested per API call, up to the
+	// API maximum of 250. Defaults to 250.
+	Limit int `validate:"min=0"`
+}
+
+// FetchSpacesOutput is the output of FetchSpacesActivity.
+type FetchSpacesOutput struct {
+	Ref   core.DataRef
+	Count int
+
+	// SpaceKeys lists the keys of the matched spaces, for fanning out
+	// per-space work directly from a workflow.
+	SpaceKeys []string
+}
+
+// FetchSpacesActivity lists the spaces matching the input filters and stores them.
+func FetchSpacesActivity(ctx context.Context, input FetchSpacesInput) (_ FetchSpacesOutput, err error) {
+	defer classifyError(&err)
+
+	if err := resolveCredentials(ctx, input.Site, &input.BaseURL, &input.Email, &input.APIToken); err != nil {
+		return FetchSpacesOutput{}, err
+	}
+	if err := validateInput(input); err != nil {
+		return FetchSpacesOutput{}, err
+	}
+
+	client := NewClient(ClientConfig{
+		BaseURL:  input.BaseURL,
+		Email:    input.Email,
+		APIToken: input.APIToken,
+	})
+
+	limit := pageSize(ctx, input.Limit)
+
+	opts := ListSpacesOptions{
+		Type:                  input.Type,
+		Status:                input.Status,
+		Labels:                input.Labels,
+		Categories:            input.Categories,
+		ExcludeLabels:         input.ExcludeLabels,
+		IncludeArchivedSpaces: input.IncludeArchivedSpaces,
+	}
+
+	spaces, err := listAllSpaces(ctx, client, opts, limit)
+	if err != nil {
+		return FetchSpacesOutput{}, err
+	}
+
+	keys := make([]string, 0, len(spaces))
+	for _, space := range spaces {
+		keys = append(keys, space.Key)
+	}
+
+	ref, err := StoreSpaces(ctx, spaces)
+	if err != nil {
+		return FetchSpacesOutput{}, fmt.Errorf("store spaces: %w", err)
+	}
+
+	return FetchSpacesOutput{
+		Ref:       ref,
+		Count:     len(spaces),
+		SpaceKeys: keys,
+	}, nil
+}
+
+// StoreSpaces stores a slice of Spaces and returns a DataRef.
+func StoreSpaces(ctx context.Context, spaces []Space) (core.DataRef, error) {
+	storage, err := core.GetStorage()
+	if err != nil {
+		return core.DataRef{}, fmt.Errorf("get storage: %w", err)
+	}
+
+	ref, err := storage.StoreJSON(ctx, SchemaSpaces, spaces)
+	if err != nil {
+		return core.DataRef{}, err
+	}
+
+	ref.Count = len(spaces)
+	return ref, nil
+}
+
+// LoadSpaces loads Spaces from a DataRef.
+func LoadSpaces(ctx context.Context, ref core.DataRef) ([]Space, error) {
+	if ref.Schema != SchemaSpaces {
+		return nil, fmt.Errorf("schema mismatch: expected %s, got %s", SchemaSpaces, ref.Schema)
+	}
+
+	storage, err := core.GetStorage()
+	if err != nil {
+		return nil, fmt.Errorf("get storage: %w", err)
+	}
+
+	var spaces []Space
+	if err := storage.LoadJSON(ctx, ref, &spaces); err != nil {
+		return nil, fmt.Errorf("load spaces: %w", err)
+	}
+
+	return spaces, nil
+}
+
+// SpaceStatsInput is the input for SpaceStatsActivity.
+type SpaceStatsInput struct {
+	BaseURL  string `validate:"required,url"`
+	Email    string `validate:"required"`
+	APIToken string `validate:"required"`
+	Site     string
+	SpaceKey string `validate:"required"`
+}
+
+// SpaceStatsOutput is the output of SpaceStatsActivity.
+type SpaceStatsOutput struct {
+	Pages       int
+	BlogPosts   int
+	Attachments int
+
+	// LastActivity is the latest modification time of any content in the
+	// space, zero for an empty space.
+	LastActivity time.Time
+}
+
+// Empty reports whether the space has no pages or blog posts.
+func (s SpaceStatsOutput) Empty() bool {
+	return s.Pages == 0 && s.BlogPosts == 0
+}
+
+// SpaceStatsActivity counts the content of a space without listing it, so
+// workflows can size batches and skip empty spaces. Counts come from search
+// and may lag recent changes by the indexing delay.
+func SpaceStatsActivity(ctx context.Context, input SpaceStatsInput) (_ SpaceStatsOutput, err error) {
+	defer classifyError(&err)
+
+	if err := resolveCredentials(ctx, input.Site, &input.BaseURL, &input.Email, &input.APIToken); err != nil {
+		return SpaceStatsOutput{}, err
+	}
+	if err := validateInput(input); err != nil {
+		return SpaceStatsOutput{}, err
+	}
+
+	client := NewClient(ClientConfig{
+		BaseURL:  input.BaseURL,
+		Email:    input.Email,
+		APIToken: input.APIToken,
+	})
+
+	var stats SpaceStatsOutput
+	counts := []struct {
+		contentType string
+		count       *int
+	}{
+		{"page", &stats.Pages},
+		{"blogpost", &stats.BlogPosts},
+		{"attachment", &stats.Attachments},
+	}
+	for _, c := range counts {
+		n, err := client.CountCQL(ctx, cql.Space(input.SpaceKey).And(cql.Type(c.contentType)).String())
+		if err != nil {
+			return SpaceStatsOutput{}, fmt.Errorf("count %ss: %w", c.contentType, err)
+		}
+		*c.count = n
+	}
+
+	latest := cql.Space(input.SpaceKey).OrderBy(cql.FieldLastModified, cql.Descending).String()
+	list, err := client.SearchContent(ctx, latest, []string{"version"}, 0, 1)
+	if err != nil {
+		return SpaceStatsOutput{}, fmt.Errorf("search latest content: %w", err)
+	}
+	if len(list.Results) > 0 {
+		stats.LastActivity = list.Results[0].Version.ModifiedAt()
+	}
+
+	return stats, nil
+}
+
+// SpaceStats creates a node for counting the content of a Confluence space.
+func SpaceStats(input SpaceStatsInput) *core.Node[SpaceStatsInput, SpaceStatsOutput] {
+	return withPolicy(core.NewNode("confluence.SpaceStats", SpaceStatsActivity, input))
+}
+
+// FetchSpaces creates a node for listing Confluence spaces.
+func FetchSpaces(input FetchSpacesInput) *core.Node[FetchSpacesInput, FetchSpacesOutput] {
+	return withPolicy(core.NewNode("confluence.FetchSpaces", FetchSpacesActivity, input))
+}
@@ -0,0 +1,92 @@
+package confluence
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	transform "github.com/resolute-sh/resolute-transform"
+	"github.com/resolute-sh/resolute/core"
+)
+
+// documentSpool buffers Documents in a temporary file as a JSON array, so a
+// large fetch stored under a single ref does not hold every converted
+// Document, with its pages and bodies, in memory while it runs. Storing the
+// spool still reads the whole encoded array into memory, which bounds the
+// peak memory of the fetch by the size of the stored ref.
+type documentSpool struct {
+	file  *os.File
+	w     *bufio.Writer
+	count int
+}
+
+func newDocumentSpool() (*documentSpool, error) {
+	file, err := os.CreateTemp("", "confluence-documents-*.json")
+	if err != nil {
+		return nil, fmt.Errorf("create spool: %w", err)
+	}
+
+	s := &documentSpool{file: file, w: bufio.NewWriter(file)}
+	if err := s.w.WriteByte('['); err != nil {
+		s.Close()
+		return nil, fmt.Errorf("write spool: %w", err)
+	}
+	return s, nil
+}
+
+// add appends Documents to the spool.
+func (s *documentSpool) add(docs ...transform.Document) error {
+	for _, doc := range docs {
+		data, err := json.Marshal(doc)
+		if err != nil {
+			return fmt.Errorf("marshal document %s: %w", doc.ID, err)
+		}
+		if s.count > 0 {
+			if err := s.w.WriteByte(','); err != nil {
+				return fmt.Errorf("write spool: %w", err)
+			}
+		}
+		if _, err := s.w.Write(data); err != nil {
+			return fmt.Errorf("write spool: %w", err)
+		}
+		s.count++
+	}
+	return nil
+}
+
+// store stores the spooled Documents under a single ref, checksummed like
+// the refs of transform.StoreDocuments. The encoded Documents are read back
+// into memory whole, as storage takes them in one piece.
+func (s *documentSpool) store(ctx context.Context) (core.DataRef, error) {
+	if err := s.w.WriteByte(']'); err != nil {
+		return core.DataRef{}, fmt.Errorf("write spool: %w", err)
+	}
+	if err := s.w.Flush(); err != nil {
+		return core.DataRef{}, fmt.Errorf("write spool: %w", err)
+	}
+
+	data, err := os.ReadFile(s.file.Name())
+	if err != nil {
+		return core.DataRef{}, fmt.Errorf("read spool: %w", err)
+	}
+
+	storage, err := core.GetStorage()
+	if err != nil {
+		return core.DataRef{}, fmt.Errorf("get storage: %w", err)
+	}
+	ref, err := storage.StoreJSON(ctx, transform.SchemaDocuments, json.RawMessage(data))
+	if err != nil {
+		return core.DataRef{}, err
+	}
+
+	ref.Count = s.count
+	return ref.WithChecksum(data), nil
+}
+
+// Close removes the spool file.
+func (s *documentSpool) Close() error {
+	s.file.Close()
+	return os.Remove(s.file.Name())
+}
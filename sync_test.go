@@ -0,0 +1,59 @@
+package confluence_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/resolute-sh/resolute-confluence"
+	"github.com/resolute-sh/resolute-confluence/confluencetest"
+)
+
+func TestIncrementalSyncActivity(t *testing.T) {
+	srv := newServer(t)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	srv.Now = func() time.Time { return now }
+	alpha := srv.AddPage(confluencetest.NewPage("ENG", "Alpha", "<p>first</p>"))
+	srv.AddPage(confluencetest.NewPage("ENG", "Beta", "<p>first</p>"))
+
+	input := confluence.IncrementalSyncInput{
+		BaseURL:  srv.URL,
+		Email:    srv.Email,
+		APIToken: srv.APIToken,
+		SpaceKey: "ENG",
+	}
+	full, err := confluence.IncrementalSyncActivity(context.Background(), input)
+	if err != nil {
+		t.Fatalf("IncrementalSyncActivity() error = %v", err)
+	}
+	if full.Count != 2 || !full.Watermark.Equal(now) {
+		t.Fatalf("full sync Count, Watermark = %d, %v, want 2, %v", full.Count, full.Watermark, now)
+	}
+
+	now = now.Add(time.Hour)
+	if _, ok := srv.UpdatePage(alpha.ID, "<p>second</p>"); !ok {
+		t.Fatalf("UpdatePage(%s) found no page", alpha.ID)
+	}
+
+	input.Watermark = &full.Watermark
+	delta, err := confluence.IncrementalSyncActivity(context.Background(), input)
+	if err != nil {
+		t.Fatalf("IncrementalSyncActivity() error = %v", err)
+	}
+	if !delta.Watermark.Equal(now) {
+		t.Errorf("Watermark = %v, want %v", delta.Watermark, now)
+	}
+	if got := loadTitles(t, delta.Ref); !slices.Contains(got, "Alpha") {
+		t.Errorf("titles = %v, want Alpha among them", got)
+	}
+
+	input.Watermark = &delta.Watermark
+	idle, err := confluence.IncrementalSyncActivity(context.Background(), input)
+	if err != nil {
+		t.Fatalf("IncrementalSyncActivity() error = %v", err)
+	}
+	if !idle.Watermark.Equal(delta.Watermark) {
+		t.Errorf("idle Watermark = %v, want %v", idle.Watermark, delta.Watermark)
+	}
+}
@@ -0,0 +1,190 @@
+package confluence
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	transform "github.com/resolute-sh/resolute-transform"
+	"github.com/resolute-sh/resolute/core"
+)
+
+// Task statuses.
+const (
+	TaskStatusIncomplete = "incomplete"
+	TaskStatusComplete   = "complete"
+)
+
+// Task is an inline task found in a page body.
+type Task struct {
+	ID     string
+	Status string
+	// Text is the task body as plain text.
+	Text string
+	// AssigneeAccountID is the account ID of the first user mentioned in
+	// the task, which Confluence treats as the assignee.
+	AssigneeAccountID string
+	// DueDate is the first date in the task, formatted as YYYY-MM-DD.
+	DueDate string
+}
+
+var (
+	taskOpenRegex   = regexp.MustCompile(`<ac:task>`)
+	taskIDRegex     = regexp.MustCompile(`<ac:task-id>\s*([^<]*?)\s*</ac:task-id>`)
+	taskStatusRegex = regexp.MustCompile(`<ac:task-status>\s*([^<]*?)\s*</ac:task-status>`)
+	taskBodyRegex   = regexp.MustCompile(`(?s)<ac:task-body>(.*?)(?:</ac:task-body>|$)`)
+	taskUserRegex   = regexp.MustCompile(`<ri:user\b[^>]*\bri:account-id="([^"]+)"`)
+	taskDateRegex   = regexp.MustCompile(`<time\b[^>]*\bdatetime="([^"]+)"`)
+)
+
+// ExtractTasks returns the inline tasks of a storage-format body in
+// document order. Nested task lists are returned as separate tasks.
+func ExtractTasks(storage string) []Task {
+	starts := taskOpenRegex.FindAllStringIndex(storage, -1)
+
+	tasks := make([]Task, 0, len(starts))
+	for i, loc := range starts {
+		end := len(storage)
+		if i+1 < len(starts) {
+			end = starts[i+1][0]
+		}
+		segment := storage[loc[1]:end]
+
+		var task Task
+		if m := taskIDRegex.FindStringSubmatch(segment); m != nil {
+			task.ID = m[1]
+		}
+		if m := taskStatusRegex.FindStringSubmatch(segment); m != nil {
+			task.Status = m[1]
+		}
+		if m := taskBodyRegex.FindStringSubmatch(segment); m != nil {
+			body := m[1]
+			task.Text = stripHTML(replaceEmoticons(body))
+			if u := taskUserRegex.FindStringSubmatch(body); u != nil {
+				task.AssigneeAccountID = u[1]
+			}
+			if d := taskDateRegex.FindStringSubmatch(body); d != nil {
+				task.DueDate = d[1]
+			}
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
+// FetchTasksInput is the input for FetchTasksActivity.
+type FetchTasksInput struct {
+	BaseURL  string `validate:"required,url"`
+	Email    string `validate:"required"`
+	APIToken string `validate:"required"`
+	Site     string
+	SpaceKey string `validate:"required"`
+
+	// IncludeCompleted also returns completed tasks.
+	IncludeCompleted bool
+
+	// Limit is the number of pages requested per API call, up to the
+	// API maximum of 250. Defaults to 250.
+	Limit int `validate:"min=0"`
+
+	// Source is the Source of the stored Documents, which tells apart the
+	// Documents of several sites. Defaults to the hostname of BaseURL.
+	Source string
+}
+
+// FetchTasksOutput is the output of FetchTasksActivity.
+type FetchTasksOutput struct {
+	Ref   core.DataRef
+	Count int
+}
+
+// FetchTasksActivity gathers the open inline tasks of a space and stores
+// each as a Document whose content is the task text. Documents carry
+// "task_id", "task_status", "assignee_account_id", and "due_date" metadata
+// alongside the "page_id" of the page holding the task.
+func FetchTasksActivity(ctx context.Context, input FetchTasksInput) (_ FetchTasksOutput, err error) {
+	defer classifyError(&err)
+
+	if err := resolveCredentials(ctx, input.Site, &input.BaseURL, &input.Email, &input.APIToken); err != nil {
+		return FetchTasksOutput{}, err
+	}
+	if err := validateInput(input); err != nil {
+		return FetchTasksOutput{}, err
+	}
+
+	client := NewClient(ClientConfig{
+		BaseURL:  input.BaseURL,
+		Email:    input.Email,
+		APIToken: input.APIToken,
+	})
+
+	source := documentSource(input.BaseURL, input.Source)
+
+	limit := pageSize(ctx, input.Limit)
+
+	var docs []transform.Document
+	start := 0
+	for {
+		list, err := client.ListSpacePages(ctx, input.SpaceKey, start, limit)
+		if err != nil {
+			return FetchTasksOutput{}, fmt.Errorf("list space pages at %d: %w", start, err)
+		}
+
+		for _, page := range list.Results {
+			for _, task := range ExtractTasks(page.Body.Storage.Value) {
+				if task.Status == TaskStatusComplete && !input.IncludeCompleted {
+					continue
+				}
+				docs = append(docs, taskToDocument(task, page, input.BaseURL, source))
+			}
+		}
+		recordHeartbeat(ctx, start+len(list.Results))
+
+		start += len(list.Results)
+		if !list.HasMore() || len(list.Results) == 0 {
+			break
+		}
+	}
+
+	ref, err := transform.StoreDocuments(ctx, docs)
+	if err != nil {
+		return FetchTasksOutput{}, fmt.Errorf("store documents: %w", err)
+	}
+
+	return FetchTasksOutput{
+		Ref:   ref,
+		Count: len(docs),
+	}, nil
+}
+
+func taskToDocument(task Task, page Page, baseURL, source string) transform.Document {
+	metadata := map[string]string{
+		"content_type": "task",
+		"task_id":      task.ID,
+		"task_status":  task.Status,
+		"page_id":      page.ID,
+		"space_key":    page.Space.Key,
+	}
+	if assignee := anonymizeAccountID(task.AssigneeAccountID); assignee != "" {
+		metadata["assignee_account_id"] = assignee
+	}
+	if task.DueDate != "" {
+		metadata["due_date"] = task.DueDate
+	}
+
+	return redactDocument(transform.Document{
+		ID:        strings.Join([]string{page.ID, "task", task.ID}, "-"),
+		Content:   task.Text,
+		Title:     page.Title,
+		Source:    source,
+		URL:       webURL(baseURL, page.Links.WebUI),
+		Metadata:  metadata,
+		UpdatedAt: page.Version.ModifiedAt(),
+	})
+}
+
+// FetchTasks creates a node for gathering Confluence inline tasks.
+func FetchTasks(input FetchTasksInput) *core.Node[FetchTasksInput, FetchTasksOutput] {
+	return withPolicy(core.NewNode("confluence.FetchTasks", FetchTasksActivity, input))
+}
@@ -0,0 +1,185 @@
+package confluence
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Rate-limit headers of Atlassian Cloud responses.
+const (
+	headerRateLimitLimit     = "X-RateLimit-Limit"
+	headerRateLimitRemaining = "X-RateLimit-Remaining"
+	headerRateLimitReset     = "X-RateLimit-Reset"
+	headerRateLimitNearLimit = "X-RateLimit-NearLimit"
+)
+
+const (
+	// throttleThreshold is the fraction of the quota below which requests
+	// are paced.
+	throttleThreshold = 0.2
+	// defaultRateWindow is the time over which the remaining quota is spread
+	// when the responses do not tell when it resets.
+	defaultRateWindow = time.Minute
+	// nearLimitInterval spaces requests when the API only reports that the
+	// quota is nearly used up.
+	nearLimitInterval = 500 * time.Millisecond
+	// maxThrottleInterval caps the spacing of paced requests.
+	maxThrottleInterval = 10 * time.Second
+)
+
+// rateLimiter paces the requests to a site from the rate-limit headers of
+// its responses. It is shared by every client of the site, since they draw
+// from the same quota.
+type rateLimiter struct {
+	mu sync.Mutex
+	// next is the time requests are paused until.
+	next time.Time
+	// last is the start of the latest request.
+	last time.Time
+	// interval spaces consecutive requests until reset.
+	interval time.Duration
+	reset    time.Time
+}
+
+var (
+	rateLimitersMu sync.Mutex
+	rateLimiters   = make(map[string]*rateLimiter)
+)
+
+// siteRateLimiter returns the rate limiter of the site at baseURL.
+func siteRateLimiter(baseURL string) *rateLimiter {
+	rateLimitersMu.Lock()
+	defer rateLimitersMu.Unlock()
+	l, ok := rateLimiters[baseURL]
+	if !ok {
+		l = &rateLimiter{}
+		rateLimiters[baseURL] = l
+	}
+	return l
+}
+
+// wait blocks until a request may start, an interval after the start of
+// the previous one and not before a pause ends, reserving its slot so
+// concurrent requests are spaced too.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	if !l.reset.IsZero() && now.After(l.reset) {
+		l.interval, l.reset = 0, time.Time{}
+	}
+	at := now
+	if l.next.After(at) {
+		at = l.next
+	}
+	if spaced := l.last.Add(l.interval); spaced.After(at) {
+		at = spaced
+	}
+	l.last = at
+	l.mu.Unlock()
+
+	delay := time.Until(at)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// observe adjusts the pacing to the rate-limit headers of a response.
+// Requests are paced once the response reports its quota and less than
+// throttleThreshold of it remains, spreading what remains until the quota
+// resets, and paused until the reset, or for defaultRateWindow when it is
+// unknown, once none remains. Responses without rate-limit headers leave
+// the pacing unchanged. Throttled responses pause requests for their
+// Retry-After. observe only sets the interval; wait reserves it between
+// consecutive requests.
+func (l *rateLimiter) observe(resp *http.Response) {
+	now := time.Now()
+	limit, hasLimit := headerInt(resp.Header, headerRateLimitLimit)
+	remaining, hasRemaining := headerInt(resp.Header, headerRateLimitRemaining)
+	reset := parseRateLimitReset(resp.Header.Get(headerRateLimitReset), now)
+	nearLimit, _ := strconv.ParseBool(resp.Header.Get(headerRateLimitNearLimit))
+
+	if !reset.After(now) {
+		reset = time.Time{}
+	}
+	remaining = max(remaining, 0)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !reset.IsZero() {
+		l.reset = reset
+	}
+	switch {
+	case hasRemaining && remaining == 0:
+		if reset.IsZero() {
+			reset = now.Add(defaultRateWindow)
+			l.reset = reset
+		}
+		l.pause(reset)
+	case hasRemaining && hasLimit && float64(remaining) < throttleThreshold*float64(limit):
+		window := defaultRateWindow
+		if !reset.IsZero() {
+			window = reset.Sub(now)
+		}
+		l.interval = min(window/time.Duration(remaining+1), maxThrottleInterval)
+		if l.reset.IsZero() {
+			l.reset = now.Add(window)
+		}
+	case hasRemaining && hasLimit:
+		l.interval = 0
+	case nearLimit:
+		l.interval = max(l.interval, nearLimitInterval)
+		if l.reset.IsZero() {
+			l.reset = now.Add(defaultRateWindow)
+		}
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		if wait := parseRetryAfter(resp.Header.Get("Retry-After")); wait > 0 {
+			l.pause(now.Add(wait))
+		}
+	}
+}
+
+// pause holds every request until at.
+func (l *rateLimiter) pause(at time.Time) {
+	if at.After(l.next) {
+		l.next = at
+	}
+}
+
+// headerInt parses an integer header, reporting whether it is set.
+func headerInt(header http.Header, name string) (int, bool) {
+	n, err := strconv.Atoi(header.Get(name))
+	return n, err == nil
+}
+
+// parseRateLimitReset parses an X-RateLimit-Reset header, given as an
+// ISO 8601 timestamp, epoch seconds, or seconds from now. It returns the
+// zero time for a missing or invalid header.
+func parseRateLimitReset(header string, now time.Time) time.Time {
+	if header == "" {
+		return time.Time{}
+	}
+	if at, err := time.Parse(time.RFC3339, header); err == nil {
+		return at
+	}
+	if n, err := strconv.ParseInt(header, 10, 64); err == nil && n >= 0 {
+		if n > 1e9 {
+			return time.Unix(n, 0)
+		}
+		return now.Add(time.Duration(n) * time.Second)
+	}
+	return time.Time{}
+}
@@ -0,0 +1,90 @@
+package confluence_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/resolute-sh/resolute-confluence"
+)
+
+// requestGaps serves a space with the rate-limit headers, makes n requests
+// to it, and returns the time between the arrivals of consecutive requests.
+func requestGaps(t *testing.T, headers map[string]string, n int) []time.Duration {
+	t.Helper()
+	var (
+		mu       sync.Mutex
+		arrivals []time.Time
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		arrivals = append(arrivals, time.Now())
+		mu.Unlock()
+		for name, value := range headers {
+			w.Header().Set(name, value)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"key":"ENG","name":"Engineering"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	client := confluence.NewClient(confluence.ClientConfig{BaseURL: srv.URL, Email: "a@example.com", APIToken: "token"})
+	for range n {
+		if _, err := client.GetSpace(context.Background(), "ENG"); err != nil {
+			t.Fatalf("GetSpace() error = %v", err)
+		}
+	}
+
+	gaps := make([]time.Duration, 0, n-1)
+	for i := 1; i < len(arrivals); i++ {
+		gaps = append(gaps, arrivals[i].Sub(arrivals[i-1]))
+	}
+	return gaps
+}
+
+func TestClientPacesRequestsNearQuota(t *testing.T) {
+	// Four of 100 requests remain for a second: requests are spaced by a
+	// fifth of it.
+	gaps := requestGaps(t, map[string]string{
+		"X-RateLimit-Limit":     "100",
+		"X-RateLimit-Remaining": "4",
+		"X-RateLimit-Reset":     "1",
+	}, 3)
+	for _, gap := range gaps {
+		if gap < 150*time.Millisecond || gap > 300*time.Millisecond {
+			t.Errorf("gaps = %v, want about 200ms each", gaps)
+			break
+		}
+	}
+}
+
+func TestClientDoesNotPaceWithoutQuota(t *testing.T) {
+	gaps := requestGaps(t, map[string]string{"X-RateLimit-Remaining": "4"}, 3)
+	for _, gap := range gaps {
+		if gap > 100*time.Millisecond {
+			t.Errorf("gaps = %v, want no pacing without X-RateLimit-Limit", gaps)
+			break
+		}
+	}
+}
+
+func TestClientPausesWhenQuotaExhausted(t *testing.T) {
+	gaps := requestGaps(t, map[string]string{
+		"X-RateLimit-Limit":     "100",
+		"X-RateLimit-Remaining": "0",
+		"X-RateLimit-Reset":     "1",
+	}, 2)
+	if gaps[0] < 900*time.Millisecond || gaps[0] > 1500*time.Millisecond {
+		t.Errorf("gap = %v, want a pause of about a second until the quota resets", gaps[0])
+	}
+}
+
+func TestClientPacesRequestsNearLimit(t *testing.T) {
+	gaps := requestGaps(t, map[string]string{"X-RateLimit-NearLimit": "true"}, 2)
+	if gaps[0] < 400*time.Millisecond || gaps[0] > 700*time.Millisecond {
+		t.Errorf("gap = %v, want about 500ms when the quota is nearly used up", gaps[0])
+	}
+}
@@ -0,0 +1,145 @@
+package confluence
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	transform "github.com/resolute-sh/resolute-transform"
+	"github.com/resolute-sh/resolute/core"
+	"go.temporal.io/sdk/temporal"
+)
+
+// ErrTypeAmbiguousTitle is the application error type of title lookups that
+// match several pages.
+const ErrTypeAmbiguousTitle = "confluence.AmbiguousTitle"
+
+// ListPagesByTitle fetches the pages of a space with a title, with the
+// properties Documents are built from. The API matches titles exactly but
+// may ignore case, so several pages can be returned.
+func (c *Client) ListPagesByTitle(ctx context.Context, spaceKey, title string) ([]Page, error) {
+	query := url.Values{}
+	query.Set("spaceKey", spaceKey)
+	query.Set("title", title)
+	query.Set("type", "page")
+	query.Set("limit", "25")
+	query.Set("expand", strings.Join(documentExpand, ","))
+
+	endpoint := c.apiEndpoint(query, "content")
+
+	var list PageList
+	if err := c.getJSON(ctx, endpoint, &list); err != nil {
+		return nil, err
+	}
+
+	return list.Results, nil
+}
+
+// FetchPageByTitleInput is the input for FetchPageByTitleActivity.
+type FetchPageByTitleInput struct {
+	BaseURL  string `validate:"required,url"`
+	Email    string `validate:"required"`
+	APIToken string `validate:"required"`
+	Site     string
+	SpaceKey string `validate:"required"`
+	Title    string `validate:"required"`
+
+	// ParentID restricts matches to the direct children of a page, to tell
+	// apart pages whose titles differ only in case.
+	ParentID string
+
+	// CollectCommentRefs adds the refs of inline comment markers found in
+	// the page body to the "inline_comment_refs" metadata field.
+	CollectCommentRefs bool
+
+	// Source is the Source of the stored Documents, which tells apart the
+	// Documents of several sites. Defaults to the hostname of BaseURL.
+	Source string
+}
+
+// FetchPageByTitleOutput is the output of FetchPageByTitleActivity.
+type FetchPageByTitleOutput struct {
+	Document transform.Document
+	PageID   string
+	Found    bool
+}
+
+// FetchPageByTitleActivity fetches the page of a space with a title. A page
+// with exactly the given title is preferred over pages whose titles only
+// differ in case. A title that matches no page is reported with Found set
+// to false; one that still matches several pages fails with a non-retryable
+// ErrTypeAmbiguousTitle error listing their IDs.
+func FetchPageByTitleActivity(ctx context.Context, input FetchPageByTitleInput) (_ FetchPageByTitleOutput, err error) {
+	defer classifyError(&err)
+
+	if err := resolveCredentials(ctx, input.Site, &input.BaseURL, &input.Email, &input.APIToken); err != nil {
+		return FetchPageByTitleOutput{}, err
+	}
+	if err := validateInput(input); err != nil {
+		return FetchPageByTitleOutput{}, err
+	}
+
+	client := NewClient(ClientConfig{
+		BaseURL:  input.BaseURL,
+		Email:    input.Email,
+		APIToken: input.APIToken,
+	})
+
+	pages, err := client.ListPagesByTitle(ctx, input.SpaceKey, input.Title)
+	if err != nil {
+		return FetchPageByTitleOutput{}, fmt.Errorf("list pages by title: %w", err)
+	}
+
+	matches := matchTitle(pages, input.Title, input.ParentID)
+	switch len(matches) {
+	case 0:
+		return FetchPageByTitleOutput{Found: false}, nil
+	case 1:
+	default:
+		ids := make([]string, len(matches))
+		for i, page := range matches {
+			ids[i] = page.ID
+		}
+		return FetchPageByTitleOutput{}, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("title %q matches %d pages in space %s: %s", input.Title, len(matches), input.SpaceKey, strings.Join(ids, ", ")),
+			ErrTypeAmbiguousTitle, nil)
+	}
+
+	page := matches[0]
+	return FetchPageByTitleOutput{
+		Document: pageToDocument(page, input.BaseURL, documentSource(input.BaseURL, input.Source), ConvertOptions{
+			CollectCommentRefs: input.CollectCommentRefs,
+		}),
+		PageID: page.ID,
+		Found:  true,
+	}, nil
+}
+
+// matchTitle selects the pages titled title, under parentID when set. Exact
+// matches are returned when there are any, case-insensitive ones otherwise.
+func matchTitle(pages []Page, title, parentID string) []Page {
+	var exact, folded []Page
+	for _, page := range pages {
+		if parentID != "" {
+			if n := len(page.Ancestors); n == 0 || page.Ancestors[n-1].ID != parentID {
+				continue
+			}
+		}
+		switch {
+		case page.Title == title:
+			exact = append(exact, page)
+		case strings.EqualFold(page.Title, title):
+			folded = append(folded, page)
+		}
+	}
+	if len(exact) > 0 {
+		return exact
+	}
+	return folded
+}
+
+// FetchPageByTitle creates a node for fetching a Confluence page by title.
+func FetchPageByTitle(input FetchPageByTitleInput) *core.Node[FetchPageByTitleInput, FetchPageByTitleOutput] {
+	return withPolicy(core.NewNode("confluence.FetchPageByTitle", FetchPageByTitleActivity, input))
+}
@@ -0,0 +1,271 @@
+package confluence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/resolute-sh/resolute/core"
+)
+
+// ListTrashedContent fetches one page of trashed content of a type, such as
+// "page" or "blogpost", in a space starting at offset start.
+func (c *Client) ListTrashedContent(ctx context.Context, spaceKey, contentType string, start, limit int) (*PageList, error) {
+	return c.ListContent(ctx, ContentQuery{
+		SpaceKey: spaceKey,
+		Type:     contentType,
+		Status:   "trashed",
+		Expand:   []string{"space", "version", "version.by"},
+	}, start, limit)
+}
+
+// GetTrashedContent fetches trashed content by ID.
+func (c *Client) GetTrashedContent(ctx context.Context, id string) (*Page, error) {
+	endpoint := c.apiEndpoint(url.Values{"status": {"trashed"}, "expand": {"space,version"}}, "content", id)
+
+	var page Page
+	if err := c.getJSON(ctx, endpoint, &page); err != nil {
+		return nil, err
+	}
+
+	return &page, nil
+}
+
+// RestoreTrashedContent moves trashed content back into its space and
+// returns it.
+func (c *Client) RestoreTrashedContent(ctx context.Context, trashed Page) (*Page, error) {
+	body := contentRequest{
+		ID:      trashed.ID,
+		Type:    trashed.Type,
+		Title:   trashed.Title,
+		Status:  StatusCurrent,
+		Version: &contentVersion{Number: trashed.Version.Number + 1},
+	}
+
+	endpoint := c.apiEndpoint(nil, "content", trashed.ID)
+
+	var page Page
+	if err := c.doJSON(ctx, http.MethodPut, endpoint, body, &page); err != nil {
+		return nil, err
+	}
+
+	return &page, nil
+}
+
+// TrashedItem is content found in the trash of a space.
+type TrashedItem struct {
+	ID    string
+	Type  string
+	Title string
+	// LastModified is the time of the last version before the content was
+	// trashed.
+	LastModified time.Time
+	// LastModifiedBy is the account ID of the author of that version.
+	LastModifiedBy string
+}
+
+// ListTrashInput is the input for ListTrashActivity.
+type ListTrashInput struct {
+	BaseURL  string `validate:"required,url"`
+	Email    string `validate:"required"`
+	APIToken string `validate:"required"`
+	Site     string
+	SpaceKey string `validate:"required"`
+
+	// Types lists the content types to list. Defaults to pages and blog
+	// posts.
+	Types []string
+
+	// Limit is the number of items requested per API call, up to the
+	// API maximum of 250. Defaults to 250.
+	Limit int `validate:"min=0"`
+}
+
+// ListTrashOutput is the output of ListTrashActivity.
+type ListTrashOutput struct {
+	Items []TrashedItem
+	Count int
+}
+
+// ListTrashActivity lists the trashed content of a space, so deletions can
+// be reviewed before the trash is purged.
+func ListTrashActivity(ctx context.Context, input ListTrashInput) (_ ListTrashOutput, err error) {
+	defer classifyError(&err)
+
+	if err := resolveCredentials(ctx, input.Site, &input.BaseURL, &input.Email, &input.APIToken); err != nil {
+		return ListTrashOutput{}, err
+	}
+	if err := validateInput(input); err != nil {
+		return ListTrashOutput{}, err
+	}
+
+	client := NewClient(ClientConfig{
+		BaseURL:  input.BaseURL,
+		Email:    input.Email,
+		APIToken: input.APIToken,
+	})
+
+	limit := pageSize(ctx, input.Limit)
+	types := input.Types
+	if len(types) == 0 {
+		types = []string{"page", "blogpost"}
+	}
+
+	var output ListTrashOutput
+	for _, contentType := range types {
+		trashed, err := listAllPages(ctx, limit, func(start, limit int) (*PageList, error) {
+			return client.ListTrashedContent(ctx, input.SpaceKey, contentType, start, limit)
+		})
+		if err != nil {
+			return ListTrashOutput{}, fmt.Errorf("list trashed %ss: %w", contentType, err)
+		}
+		for _, item := range trashed {
+			output.Items = append(output.Items, TrashedItem{
+				ID:             item.ID,
+				Type:           contentType,
+				Title:          item.Title,
+				LastModified:   item.Version.ModifiedAt(),
+				LastModifiedBy: item.Version.By.AccountID,
+			})
+		}
+	}
+	output.Count = len(output.Items)
+
+	return output, nil
+}
+
+// ListTrash creates a node for listing the trash of a Confluence space.
+func ListTrash(input ListTrashInput) *core.Node[ListTrashInput, ListTrashOutput] {
+	return withPolicy(core.NewNode("confluence.ListTrash", ListTrashActivity, input))
+}
+
+// RestoreFromTrashInput is the input for RestoreFromTrashActivity.
+type RestoreFromTrashInput struct {
+	BaseURL    string `validate:"required,url"`
+	Email      string `validate:"required"`
+	APIToken   string `validate:"required"`
+	Site       string
+	ContentIDs []string `validate:"minlen=1"`
+}
+
+// RestoreFromTrashOutput is the output of RestoreFromTrashActivity.
+type RestoreFromTrashOutput struct {
+	Restored []string
+	Errors   []ItemError
+}
+
+// RestoreFromTrashActivity moves trashed content back into its space,
+// reporting failures per item instead of failing the activity. Content that
+// is no longer in the trash but still exists counts as restored, so retries
+// are safe.
+func RestoreFromTrashActivity(ctx context.Context, input RestoreFromTrashInput) (_ RestoreFromTrashOutput, err error) {
+	defer classifyError(&err)
+
+	if err := resolveCredentials(ctx, input.Site, &input.BaseURL, &input.Email, &input.APIToken); err != nil {
+		return RestoreFromTrashOutput{}, err
+	}
+	if err := validateInput(input); err != nil {
+		return RestoreFromTrashOutput{}, err
+	}
+
+	client := NewClient(ClientConfig{
+		BaseURL:  input.BaseURL,
+		Email:    input.Email,
+		APIToken: input.APIToken,
+	})
+
+	var output RestoreFromTrashOutput
+	for i, id := range input.ContentIDs {
+		if err := restoreFromTrash(ctx, client, id); err != nil {
+			output.Errors = append(output.Errors, newItemError(id, err))
+		} else {
+			output.Restored = append(output.Restored, id)
+		}
+		recordHeartbeat(ctx, i+1)
+	}
+
+	return output, nil
+}
+
+func restoreFromTrash(ctx context.Context, client *Client, id string) error {
+	trashed, err := client.GetTrashedContent(ctx, id)
+	if hasStatus(err, http.StatusNotFound) {
+		if _, err := client.GetContent(ctx, id, []string{"version"}); err != nil {
+			if hasStatus(err, http.StatusNotFound) {
+				return errors.New("content is neither trashed nor current")
+			}
+			return fmt.Errorf("get content: %w", err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("get trashed content: %w", err)
+	}
+
+	if _, err := client.RestoreTrashedContent(ctx, *trashed); err != nil {
+		return fmt.Errorf("restore content: %w", err)
+	}
+	return nil
+}
+
+// RestoreFromTrash creates a node for restoring trashed Confluence content.
+func RestoreFromTrash(input RestoreFromTrashInput) *core.Node[RestoreFromTrashInput, RestoreFromTrashOutput] {
+	return withPolicy(core.NewNode("confluence.RestoreFromTrash", RestoreFromTrashActivity, input))
+}
+
+// PurgeTrashInput is the input for PurgeTrashActivity.
+type PurgeTrashInput struct {
+	BaseURL    string `validate:"required,url"`
+	Email      string `validate:"required"`
+	APIToken   string `validate:"required"`
+	Site       string
+	ContentIDs []string `validate:"minlen=1"`
+}
+
+// PurgeTrashOutput is the output of PurgeTrashActivity.
+type PurgeTrashOutput struct {
+	Purged []string
+	Errors []ItemError
+}
+
+// PurgeTrashActivity permanently deletes trashed content, reporting failures
+// per item instead of failing the activity. Only content already in the
+// trash is purged; content that is gone counts as purged, so retries are
+// safe.
+func PurgeTrashActivity(ctx context.Context, input PurgeTrashInput) (_ PurgeTrashOutput, err error) {
+	defer classifyError(&err)
+
+	if err := resolveCredentials(ctx, input.Site, &input.BaseURL, &input.Email, &input.APIToken); err != nil {
+		return PurgeTrashOutput{}, err
+	}
+	if err := validateInput(input); err != nil {
+		return PurgeTrashOutput{}, err
+	}
+
+	client := NewClient(ClientConfig{
+		BaseURL:  input.BaseURL,
+		Email:    input.Email,
+		APIToken: input.APIToken,
+	})
+
+	var output PurgeTrashOutput
+	for i, id := range input.ContentIDs {
+		err := client.PurgePage(ctx, id)
+		if err != nil && !hasStatus(err, http.StatusNotFound) {
+			output.Errors = append(output.Errors, newItemError(id, fmt.Errorf("purge content: %w", err)))
+		} else {
+			output.Purged = append(output.Purged, id)
+		}
+		recordHeartbeat(ctx, i+1)
+	}
+
+	return output, nil
+}
+
+// PurgeTrash creates a node for purging trashed Confluence content.
+func PurgeTrash(input PurgeTrashInput) *core.Node[PurgeTrashInput, PurgeTrashOutput] {
+	return withPolicy(core.NewNode("confluence.PurgeTrash", PurgeTrashActivity, input))
+}
@@ -0,0 +1,148 @@
+package confluence
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	transform "github.com/resolute-sh/resolute-transform"
+	"github.com/resolute-sh/resolute/core"
+)
+
+// FetchPageTreeInput is the input for FetchPageTreeActivity.
+type FetchPageTreeInput struct {
+	BaseURL    string `validate:"required,url"`
+	Email      string `validate:"required"`
+	APIToken   string `validate:"required"`
+	Site       string
+	RootPageID string `validate:"required"`
+
+	// MaxDepth limits how many levels below the root page are fetched.
+	// Zero fetches all descendants.
+	MaxDepth int `validate:"min=0"`
+
+	// Limit is the number of pages requested per API call, up to the
+	// API maximum of 250. Defaults to 250.
+	Limit int `validate:"min=0"`
+
+	// CollectCommentRefs adds the refs of inline comment markers found in
+	// each page body to the "inline_comment_refs" metadata field.
+	CollectCommentRefs bool
+
+	// Source is the Source of the stored Documents, which tells apart the
+	// Documents of several sites. Defaults to the hostname of BaseURL.
+	Source string
+}
+
+// FetchPageTreeOutput is the output of FetchPageTreeActivity.
+type FetchPageTreeOutput struct {
+	Ref   core.DataRef
+	Count int
+	// Denied lists the pages, by ID, whose children were left out because
+	// the credentials cannot read them.
+	Denied []ItemError
+	// Errors lists the pages, by ID, whose children were left out because
+	// listing them failed with a server error or a malformed response.
+	Errors []ItemError
+}
+
+// FetchPageTreeActivity fetches a page and its descendants and stores them.
+// Each Document carries "parent_id", "depth", and "breadcrumb" metadata, and
+// descendants carry their "position" in the order Confluence lists siblings.
+func FetchPageTreeActivity(ctx context.Context, input FetchPageTreeInput) (_ FetchPageTreeOutput, err error) {
+	defer classifyError(&err)
+
+	if err := resolveCredentials(ctx, input.Site, &input.BaseURL, &input.Email, &input.APIToken); err != nil {
+		return FetchPageTreeOutput{}, err
+	}
+	if err := validateInput(input); err != nil {
+		return FetchPageTreeOutput{}, err
+	}
+
+	client := NewClient(ClientConfig{
+		BaseURL:  input.BaseURL,
+		Email:    input.Email,
+		APIToken: input.APIToken,
+	})
+
+	source := documentSource(input.BaseURL, input.Source)
+
+	limit := pageSize(ctx, input.Limit)
+
+	opts := ConvertOptions{CollectCommentRefs: input.CollectCommentRefs}
+
+	root, err := client.GetPage(ctx, input.RootPageID)
+	if err != nil {
+		return FetchPageTreeOutput{}, fmt.Errorf("get root page: %w", err)
+	}
+
+	type treeNode struct {
+		page  Page
+		depth int
+		// position is the index of the page among its siblings, or -1 for
+		// the root.
+		position int
+	}
+
+	queue := []treeNode{{page: *root, position: -1}}
+	var docs []transform.Document
+	var denied, failed []ItemError
+
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		doc := pageToDocument(node.page, input.BaseURL, source, opts)
+		doc.Metadata["depth"] = strconv.Itoa(node.depth)
+		if node.position >= 0 {
+			doc.Metadata["position"] = strconv.Itoa(node.position)
+		}
+		docs = append(docs, doc)
+
+		recordHeartbeat(ctx, len(docs))
+
+		if input.MaxDepth > 0 && node.depth >= input.MaxDepth {
+			continue
+		}
+
+		children, err := listAllPages(ctx, limit, func(start, limit int) (*PageList, error) {
+			return client.ListChildPages(ctx, node.page.ID, start, limit)
+		})
+		if isInaccessible(err) {
+			denied = append(denied, newItemError(node.page.ID, err))
+			continue
+		}
+		if isItemFailure(err) {
+			failed = append(failed, newItemError(node.page.ID, err))
+			continue
+		}
+		if err != nil {
+			return FetchPageTreeOutput{}, fmt.Errorf("list children of %s: %w", node.page.ID, err)
+		}
+
+		for i, child := range children {
+			queue = append(queue, treeNode{
+				page:     child,
+				depth:    node.depth + 1,
+				position: i,
+			})
+		}
+	}
+
+	ref, err := transform.StoreDocuments(ctx, docs)
+	if err != nil {
+		return FetchPageTreeOutput{}, fmt.Errorf("store documents: %w", err)
+	}
+
+	return FetchPageTreeOutput{
+		Ref:    ref,
+		Count:  len(docs),
+		Denied: denied,
+		Errors: failed,
+	}, nil
+}
+
+// FetchPageTree creates a node for fetching a Confluence page tree.
+func FetchPageTree(input FetchPageTreeInput) *core.Node[FetchPageTreeInput, FetchPageTreeOutput] {
+	return withPolicy(core.NewNode("confluence.FetchPageTree", FetchPageTreeActivity, input))
+}
@@ -0,0 +1,65 @@
+package confluence
+
+import (
+	"errors"
+
+	"github.com/resolute-sh/resolute/core"
+	"go.temporal.io/sdk/temporal"
+)
+
+// ErrTypeInvalidInput is the application error type of activities called
+// with input that fails validation.
+const ErrTypeInvalidInput = "confluence.InvalidInput"
+
+// validateInput checks an activity input against its validate tags and
+// returns a non-retryable ErrTypeInvalidInput error when it fails, so bad
+// input is rejected before any API call is made.
+func validateInput(input any) error {
+	if err := core.Validate(input); err != nil {
+		return invalidInputError(err)
+	}
+	return nil
+}
+
+// invalidInputError wraps err as a non-retryable ErrTypeInvalidInput error.
+func invalidInputError(err error) error {
+	return temporal.NewNonRetryableApplicationError("invalid input", ErrTypeInvalidInput, err)
+}
+
+// checkCQLSyntax catches the CQL mistakes that can be spotted without a
+// round trip: unterminated strings and unbalanced parentheses.
+func checkCQLSyntax(cql string) error {
+	depth := 0
+	var quote rune
+	escaped := false
+
+	for _, r := range cql {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+			if depth < 0 {
+				return errors.New("cql: unexpected )")
+			}
+		}
+	}
+
+	if quote != 0 {
+		return errors.New("cql: unterminated string")
+	}
+	if depth > 0 {
+		return errors.New("cql: missing )")
+	}
+	return nil
+}
@@ -0,0 +1,324 @@
+package confluence
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	transform "github.com/resolute-sh/resolute-transform"
+	"github.com/resolute-sh/resolute/core"
+)
+
+// Webhook events handled by the webhook helpers.
+const (
+	EventPageCreated    = "page_created"
+	EventPageUpdated    = "page_updated"
+	EventPageRestored   = "page_restored"
+	EventPageTrashed    = "page_trashed"
+	EventPageRemoved    = "page_removed"
+	EventCommentCreated = "comment_created"
+)
+
+// WebhookSignatureHeader is the header carrying the HMAC-SHA256 signature of
+// webhook payloads sent by a webhook configured with a secret.
+const WebhookSignatureHeader = "X-Hub-Signature"
+
+// maxWebhookBody is the largest webhook payload ParseWebhookRequest reads.
+const maxWebhookBody = 1 << 20
+
+// ErrInvalidWebhookSignature is returned when a webhook payload does not
+// carry a valid signature or token.
+var ErrInvalidWebhookSignature = errors.New("confluence: invalid webhook signature")
+
+// WebhookEvent is a parsed Confluence webhook payload.
+type WebhookEvent struct {
+	// Event is the event name, such as EventPageUpdated.
+	Event string `json:"webhookEvent"`
+	// Timestamp is the time of the event in milliseconds since the epoch.
+	Timestamp     int64  `json:"timestamp"`
+	UserAccountID string `json:"userAccountId"`
+
+	// Page is set by page events.
+	Page *WebhookContent `json:"page,omitempty"`
+	// Comment is set by comment events.
+	Comment *WebhookContent `json:"comment,omitempty"`
+}
+
+// WebhookContent is the content a webhook event is about.
+type WebhookContent struct {
+	ID                    ContentID `json:"id"`
+	SpaceKey              string    `json:"spaceKey"`
+	Title                 string    `json:"title"`
+	ContentType           string    `json:"contentType"`
+	Version               int       `json:"version"`
+	CreatorAccountID      string    `json:"creatorAccountId"`
+	LastModifierAccountID string    `json:"lastModifierAccountId"`
+	// CreationDate and ModificationDate are in milliseconds since the epoch.
+	CreationDate     int64  `json:"creationDate"`
+	ModificationDate int64  `json:"modificationDate"`
+	Self             string `json:"self"`
+
+	// Parent is the content a comment belongs to.
+	Parent *WebhookContent `json:"parent,omitempty"`
+}
+
+// ContentID is a content ID, which webhook payloads encode as a number.
+type ContentID string
+
+// UnmarshalJSON accepts both numeric and string IDs.
+func (id *ContentID) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*id = ContentID(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("content id: %w", err)
+	}
+	*id = ContentID(n.String())
+	return nil
+}
+
+// Time returns the time of the event.
+func (e WebhookEvent) Time() time.Time {
+	return time.UnixMilli(e.Timestamp)
+}
+
+// PageID returns the ID of the page the event is about: the page of page
+// events and the commented page of comment events.
+func (e WebhookEvent) PageID() string {
+	switch {
+	case e.Page != nil:
+		return string(e.Page.ID)
+	case e.Comment != nil && e.Comment.Parent != nil:
+		return string(e.Comment.Parent.ID)
+	}
+	return ""
+}
+
+// SpaceKey returns the key of the space the event happened in.
+func (e WebhookEvent) SpaceKey() string {
+	switch {
+	case e.Page != nil:
+		return e.Page.SpaceKey
+	case e.Comment != nil && e.Comment.Parent != nil:
+		return e.Comment.Parent.SpaceKey
+	case e.Comment != nil:
+		return e.Comment.SpaceKey
+	}
+	return ""
+}
+
+// Deleted reports whether the event removed its page from the space.
+func (e WebhookEvent) Deleted() bool {
+	return e.Event == EventPageTrashed || e.Event == EventPageRemoved
+}
+
+// FetchPageInput returns the input that fetches the current state of the
+// event's page, and false for events that removed it or carry no page.
+// Connection fields are left empty for the default credentials.
+func (e WebhookEvent) FetchPageInput() (FetchPageInput, bool) {
+	pageID := e.PageID()
+	if pageID == "" || e.Deleted() {
+		return FetchPageInput{}, false
+	}
+	return FetchPageInput{PageID: pageID}, true
+}
+
+// FetchCommentsInput returns the input that fetches the comments of the
+// commented page of comment events, and false for other events.
+// Connection fields are left empty for the default credentials.
+func (e WebhookEvent) FetchCommentsInput() (FetchCommentsInput, bool) {
+	if e.Event != EventCommentCreated || e.PageID() == "" {
+		return FetchCommentsInput{}, false
+	}
+	return FetchCommentsInput{PageIDs: []string{e.PageID()}}, true
+}
+
+// ParseWebhookEvent parses a webhook payload. Payloads that do not name
+// their event get the given event, typically taken from the webhook URL.
+func ParseWebhookEvent(body []byte, event string) (WebhookEvent, error) {
+	var e WebhookEvent
+	if err := json.Unmarshal(body, &e); err != nil {
+		return WebhookEvent{}, fmt.Errorf("parse webhook event: %w", err)
+	}
+	if e.Event == "" {
+		e.Event = event
+	}
+	if e.Event == "" {
+		return WebhookEvent{}, errors.New("parse webhook event: event name is missing")
+	}
+	return e, nil
+}
+
+// ParseWebhookRequest reads and parses a webhook request. With a non-empty
+// secret, the payload signature in WebhookSignatureHeader is verified
+// first. The event name is read from the payload or the "event" query
+// parameter.
+func ParseWebhookRequest(r *http.Request, secret string) (WebhookEvent, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBody+1))
+	if err != nil {
+		return WebhookEvent{}, fmt.Errorf("read webhook request: %w", err)
+	}
+	if len(body) > maxWebhookBody {
+		return WebhookEvent{}, errors.New("read webhook request: payload too large")
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if secret != "" {
+		if err := VerifyWebhookSignature(body, r.Header.Get(WebhookSignatureHeader), secret); err != nil {
+			return WebhookEvent{}, err
+		}
+	}
+
+	return ParseWebhookEvent(body, r.URL.Query().Get("event"))
+}
+
+// VerifyWebhookSignature checks a "sha256=<hex>" HMAC-SHA256 signature of
+// body made with secret.
+func VerifyWebhookSignature(body []byte, signature, secret string) error {
+	digest, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return ErrInvalidWebhookSignature
+	}
+	got, err := hex.DecodeString(digest)
+	if err != nil {
+		return ErrInvalidWebhookSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return ErrInvalidWebhookSignature
+	}
+	return nil
+}
+
+// VerifyWebhookToken checks a shared secret token sent with a webhook, for
+// webhooks registered with the token in their URL instead of a secret.
+func VerifyWebhookToken(token, secret string) error {
+	if secret == "" || subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+		return ErrInvalidWebhookSignature
+	}
+	return nil
+}
+
+// FetchChangedPageInput is the input for FetchChangedPageActivity.
+type FetchChangedPageInput struct {
+	BaseURL  string `validate:"required,url"`
+	Email    string `validate:"required"`
+	APIToken string `validate:"required"`
+	Site     string
+
+	// Event is the webhook event to act on.
+	Event WebhookEvent
+
+	// CollectCommentRefs adds the refs of inline comment markers found in
+	// the page body to the "inline_comment_refs" metadata field.
+	CollectCommentRefs bool
+
+	// Source is the Source of the Document, which tells apart the
+	// Documents of several sites. Defaults to the hostname of BaseURL.
+	Source string
+}
+
+// FetchChangedPageOutput is the output of FetchChangedPageActivity.
+type FetchChangedPageOutput struct {
+	// Document is the changed page or comment, or a tombstone.
+	Document transform.Document
+	// Found is false when the event carries no content to act on.
+	Found bool
+	// Deleted reports that Document is a tombstone.
+	Deleted bool
+}
+
+// FetchChangedPageActivity acts on a webhook event: created, updated, and
+// restored pages are fetched into a Document, created comments into a
+// comment Document, and trashed or removed pages produce a tombstone. A page
+// that no longer exists when the event is handled also produces a
+// tombstone, so late events do not resurrect deleted pages.
+func FetchChangedPageActivity(ctx context.Context, input FetchChangedPageInput) (_ FetchChangedPageOutput, err error) {
+	defer classifyError(&err)
+
+	if err := resolveCredentials(ctx, input.Site, &input.BaseURL, &input.Email, &input.APIToken); err != nil {
+		return FetchChangedPageOutput{}, err
+	}
+	if err := validateInput(input); err != nil {
+		return FetchChangedPageOutput{}, err
+	}
+
+	client := NewClient(ClientConfig{
+		BaseURL:  input.BaseURL,
+		Email:    input.Email,
+		APIToken: input.APIToken,
+	})
+
+	source := documentSource(input.BaseURL, input.Source)
+
+	event := input.Event
+	pageID := event.PageID()
+	if pageID == "" {
+		return FetchChangedPageOutput{}, nil
+	}
+
+	tombstone := func(reason string) FetchChangedPageOutput {
+		return FetchChangedPageOutput{
+			Document: tombstoneDocument(pageID, event.SpaceKey(), source, reason, event.Time()),
+			Found:    true,
+			Deleted:  true,
+		}
+	}
+
+	switch event.Event {
+	case EventPageTrashed:
+		return tombstone(DeletedReasonTrashed), nil
+	case EventPageRemoved:
+		return tombstone(DeletedReasonRemoved), nil
+	case EventCommentCreated:
+		if event.Comment == nil {
+			return FetchChangedPageOutput{}, nil
+		}
+		comment, err := client.GetComment(ctx, string(event.Comment.ID))
+		if hasStatus(err, http.StatusNotFound) {
+			return FetchChangedPageOutput{}, nil
+		}
+		if err != nil {
+			return FetchChangedPageOutput{}, fmt.Errorf("get comment: %w", err)
+		}
+		return FetchChangedPageOutput{
+			Document: commentToDocument(*comment, pageID, input.BaseURL, source),
+			Found:    true,
+		}, nil
+	}
+
+	page, err := client.GetPage(ctx, pageID)
+	if hasStatus(err, http.StatusNotFound) {
+		return tombstone(DeletedReasonMissing), nil
+	}
+	if err != nil {
+		return FetchChangedPageOutput{}, fmt.Errorf("get page: %w", err)
+	}
+
+	return FetchChangedPageOutput{
+		Document: pageToDocument(*page, input.BaseURL, source, ConvertOptions{
+			CollectCommentRefs: input.CollectCommentRefs,
+		}),
+		Found: true,
+	}, nil
+}
+
+// FetchChangedPage creates a node for acting on a Confluence webhook event.
+func FetchChangedPage(input FetchChangedPageInput) *core.Node[FetchChangedPageInput, FetchChangedPageOutput] {
+	return withPolicy(core.NewNode("confluence.FetchChangedPage", FetchChangedPageActivity, input))
+}
@@ -0,0 +1,198 @@
+package confluence
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/resolute-sh/resolute-confluence/cql"
+	transform "github.com/resolute-sh/resolute-transform"
+	"github.com/resolute-sh/resolute/core"
+)
+
+// Content types of the newer Confluence editor features.
+const (
+	ContentTypeWhiteboard = "whiteboard"
+	ContentTypeDatabase   = "database"
+)
+
+// FetchWhiteboardsInput is the input for FetchWhiteboardsActivity.
+type FetchWhiteboardsInput struct {
+	BaseURL  string `validate:"required,url"`
+	Email    string `validate:"required"`
+	APIToken string `validate:"required"`
+	Site     string
+	SpaceKey string `validate:"required"`
+
+	// Limit is the number of items requested per API call, up to the
+	// API maximum of 250. Defaults to 250.
+	Limit int `validate:"min=0"`
+
+	// IncludeArchivedSpaces finds the items of the space even when it is
+	// archived, which CQL searches leave out by default.
+	IncludeArchivedSpaces bool
+
+	// Source is the Source of the stored Documents, which tells apart the
+	// Documents of several sites. Defaults to the hostname of BaseURL.
+	Source string
+}
+
+// FetchWhiteboardsOutput is the output of FetchWhiteboardsActivity.
+type FetchWhiteboardsOutput struct {
+	Ref   core.DataRef
+	Count int
+}
+
+// FetchWhiteboardsActivity stores the whiteboards of a space as Documents.
+// The Confluence API does not expose whiteboard canvases, so Documents hold
+// the title and location of each whiteboard, which is enough to surface it
+// in search results.
+func FetchWhiteboardsActivity(ctx context.Context, input FetchWhiteboardsInput) (_ FetchWhiteboardsOutput, err error) {
+	defer classifyError(&err)
+
+	if err := resolveCredentials(ctx, input.Site, &input.BaseURL, &input.Email, &input.APIToken); err != nil {
+		return FetchWhiteboardsOutput{}, err
+	}
+	if err := validateInput(input); err != nil {
+		return FetchWhiteboardsOutput{}, err
+	}
+
+	ref, count, err := fetchSpaceContentOfType(ctx, ClientConfig{
+		BaseURL:  input.BaseURL,
+		Email:    input.Email,
+		APIToken: input.APIToken,
+	}, input.SpaceKey, ContentTypeWhiteboard, documentSource(input.BaseURL, input.Source), input.Limit, input.IncludeArchivedSpaces)
+	if err != nil {
+		return FetchWhiteboardsOutput{}, err
+	}
+
+	return FetchWhiteboardsOutput{
+		Ref:   ref,
+		Count: count,
+	}, nil
+}
+
+// FetchWhiteboards creates a node for fetching Confluence whiteboards.
+func FetchWhiteboards(input FetchWhiteboardsInput) *core.Node[FetchWhiteboardsInput, FetchWhiteboardsOutput] {
+	return withPolicy(core.NewNode("confluence.FetchWhiteboards", FetchWhiteboardsActivity, input))
+}
+
+// FetchDatabasesInput is the input for FetchDatabasesActivity.
+type FetchDatabasesInput struct {
+	BaseURL  string `validate:"required,url"`
+	Email    string `validate:"required"`
+	APIToken string `validate:"required"`
+	Site     string
+	SpaceKey string `validate:"required"`
+
+	// Limit is the number of items requested per API call, up to the
+	// API maximum of 250. Defaults to 250.
+	Limit int `validate:"min=0"`
+
+	// IncludeArchivedSpaces finds the items of the space even when it is
+	// archived, which CQL searches leave out by default.
+	IncludeArchivedSpaces bool
+
+	// Source is the Source of the stored Documents, which tells apart the
+	// Documents of several sites. Defaults to the hostname of BaseURL.
+	Source string
+}
+
+// FetchDatabasesOutput is the output of FetchDatabasesActivity.
+type FetchDatabasesOutput struct {
+	Ref   core.DataRef
+	Count int
+}
+
+// FetchDatabasesActivity stores the databases of a space as Documents. The
+// Confluence API does not expose database entries, so Documents hold the
+// title and location of each database.
+func FetchDatabasesActivity(ctx context.Context, input FetchDatabasesInput) (_ FetchDatabasesOutput, err error) {
+	defer classifyError(&err)
+
+	if err := resolveCredentials(ctx, input.Site, &input.BaseURL, &input.Email, &input.APIToken); err != nil {
+		return FetchDatabasesOutput{}, err
+	}
+	if err := validateInput(input); err != nil {
+		return FetchDatabasesOutput{}, err
+	}
+
+	ref, count, err := fetchSpaceContentOfType(ctx, ClientConfig{
+		BaseURL:  input.BaseURL,
+		Email:    input.Email,
+		APIToken: input.APIToken,
+	}, input.SpaceKey, ContentTypeDatabase, documentSource(input.BaseURL, input.Source), input.Limit, input.IncludeArchivedSpaces)
+	if err != nil {
+		return FetchDatabasesOutput{}, err
+	}
+
+	return FetchDatabasesOutput{
+		Ref:   ref,
+		Count: count,
+	}, nil
+}
+
+// FetchDatabases creates a node for fetching Confluence databases.
+func FetchDatabases(input FetchDatabasesInput) *core.Node[FetchDatabasesInput, FetchDatabasesOutput] {
+	return withPolicy(core.NewNode("confluence.FetchDatabases", FetchDatabasesActivity, input))
+}
+
+// fetchSpaceContentOfType finds the content of a type in a space with CQL
+// and stores it as Documents.
+func fetchSpaceContentOfType(ctx context.Context, cfg ClientConfig, spaceKey, contentType, source string, limit int, includeArchived bool) (core.DataRef, int, error) {
+	client := NewClient(cfg)
+
+	limit = pageSize(ctx, limit)
+
+	query := cql.Space(spaceKey).
+		And(cql.Type(contentType)).
+		OrderBy(cql.FieldLastModified, cql.Descending).
+		String()
+	items, err := listAllPages(ctx, limit, func(start, limit int) (*PageList, error) {
+		return client.SearchContentWithOptions(ctx, query, start, limit, SearchOptions{
+			Expand:                []string{"space", "version", "ancestors"},
+			IncludeArchivedSpaces: includeArchived,
+		})
+	})
+	if err != nil {
+		return core.DataRef{}, 0, fmt.Errorf("search %ss: %w", contentType, err)
+	}
+
+	docs := make([]transform.Document, 0, len(items))
+	for _, item := range items {
+		docs = append(docs, typedContentToDocument(item, contentType, cfg.BaseURL, source))
+	}
+
+	ref, err := transform.StoreDocuments(ctx, docs)
+	if err != nil {
+		return core.DataRef{}, 0, fmt.Errorf("store documents: %w", err)
+	}
+
+	return ref, len(docs), nil
+}
+
+func typedContentToDocument(item Page, contentType, baseURL, source string) transform.Document {
+	metadata := map[string]string{
+		"content_id":   item.ID,
+		"content_type": contentType,
+		"space_key":    item.Space.Key,
+		"space_name":   item.Space.Name,
+		"status":       item.Status,
+		"version":      fmt.Sprintf("%d", item.Version.Number),
+	}
+	if n := len(item.Ancestors); n > 0 {
+		metadata["parent_id"] = item.Ancestors[n-1].ID
+	}
+	if item.Space.Type != "" {
+		metadata["space_type"] = item.Space.Type
+	}
+
+	return redactDocument(transform.Document{
+		ID:        item.ID,
+		Content:   item.Title,
+		Title:     item.Title,
+		Source:    source,
+		URL:       webURL(baseURL, item.Links.WebUI),
+		Metadata:  metadata,
+		UpdatedAt: item.Version.ModifiedAt(),
+	})
+}
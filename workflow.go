@@ -0,0 +1,313 @@
+package confluence
+
+import (
+	"time"
+
+	transform "github.com/resolute-sh/resolute-transform"
+	"github.com/resolute-sh/resolute/core"
+	"go.temporal.io/sdk/workflow"
+)
+
+// SyncSpaceWorkflowName is the name SyncSpaceWorkflow is registered under.
+const SyncSpaceWorkflowName = "confluence.SyncSpace"
+
+// SyncSpaceInput is the input for SyncSpaceWorkflow.
+type SyncSpaceInput struct {
+	// BaseURL, Email, and APIToken may be left empty to use the credentials
+	// registered for Site, or the default credentials, of the worker running
+	// the activities.
+	BaseURL  string
+	Email    string
+	APIToken string
+	Site     string
+	SpaceKey string `validate:"required"`
+
+	// Watermark is the watermark returned by the previous sync. A nil
+	// watermark performs a full sync of the space.
+	Watermark *time.Time
+
+	// BatchSize is the number of pages fetched and stored per activity.
+	// Defaults to 500.
+	BatchSize int `validate:"min=0"`
+
+	// Limit is the number of pages requested per API call, up to the
+	// API maximum of 250. Defaults to 250.
+	Limit int `validate:"min=0"`
+
+	// Concurrency is the number of page bodies fetched at once. See
+	// FetchPagesInput.Concurrency.
+	Concurrency int `validate:"min=0"`
+	// LazyBodies only fetches the bodies of changed pages. See
+	// FetchPagesInput.LazyBodies.
+	LazyBodies bool
+	// IncludeArchivedSpaces syncs the space even when it is archived. See
+	// FetchPagesInput.IncludeArchivedSpaces.
+	IncludeArchivedSpaces bool
+
+	// Chunk splits each stored batch into chunks when set.
+	Chunk *transform.ChunkOptions
+
+	// CollectCommentRefs adds the refs of inline comment markers found in
+	// each page body to the "inline_comment_refs" metadata field.
+	CollectCommentRefs bool
+
+	// MaxBatchesPerRun is the number of batches processed before the
+	// workflow continues as new to keep its history bounded. Defaults to 50.
+	MaxBatchesPerRun int `validate:"min=0"`
+
+	// Source is the Source of the stored Documents, which tells apart the
+	// Documents of several sites. Defaults to the hostname of BaseURL.
+	Source string
+
+	// Progress carries the sync state across continue-as-new. Leave it
+	// empty when starting a sync.
+	Progress SyncSpaceProgress
+}
+
+// SyncSpaceProgress is the state of a sync in progress.
+type SyncSpaceProgress struct {
+	Start     int
+	Refs      []core.DataRef
+	Count     int
+	Watermark time.Time
+}
+
+// SyncSpaceOutput is the output of SyncSpaceWorkflow.
+type SyncSpaceOutput struct {
+	// Refs holds one ref per stored batch, chunked when Chunk was set.
+	Refs  []core.DataRef
+	Count int
+
+	// Watermark is the latest modification time among the synced pages, or
+	// the input watermark if nothing changed. Pass it to the next sync.
+	Watermark time.Time
+}
+
+// SyncSpaceWorkflow syncs a Confluence space in batches. Each batch is
+// fetched and stored by FetchPagesActivity and optionally chunked by
+// ChunkDocumentsActivity, and the workflow continues as new every
+// MaxBatchesPerRun batches, carrying its progress and watermark along.
+func SyncSpaceWorkflow(ctx workflow.Context, input SyncSpaceInput) (SyncSpaceOutput, error) {
+	if err := validateInput(input); err != nil {
+		return SyncSpaceOutput{}, err
+	}
+
+	ctx = workflow.WithActivityOptions(ctx, batchPolicy.ActivityOptions())
+
+	batchSize := input.BatchSize
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	maxBatches := input.MaxBatchesPerRun
+	if maxBatches <= 0 {
+		maxBatches = 50
+	}
+
+	progress := input.Progress
+	if input.Watermark != nil && input.Watermark.After(progress.Watermark) {
+		progress.Watermark = *input.Watermark
+	}
+
+	// Runs recorded before FetchPages was versioned replay the v1 name.
+	fetchPages := "confluence.FetchPages"
+	if workflow.GetVersion(ctx, "fetch-pages-v2", workflow.DefaultVersion, 1) == 1 {
+		fetchPages = VersionedName(fetchPages, 2)
+	}
+
+	for batch := 0; ; batch++ {
+		if batch >= maxBatches {
+			input.Progress = progress
+			return SyncSpaceOutput{}, workflow.NewContinueAsNewError(ctx, SyncSpaceWorkflowName, input)
+		}
+
+		var fetched FetchPagesOutput
+		err := workflow.ExecuteActivity(ctx, fetchPages, FetchPagesInput{
+			BaseURL:               input.BaseURL,
+			Email:                 input.Email,
+			APIToken:              input.APIToken,
+			Site:                  input.Site,
+			SpaceKey:              input.SpaceKey,
+			Since:                 input.Watermark,
+			Limit:                 input.Limit,
+			MaxResults:            batchSize,
+			Start:                 progress.Start,
+			Concurrency:           input.Concurrency,
+			LazyBodies:            input.LazyBodies,
+			CollectCommentRefs:    input.CollectCommentRefs,
+			IncludeArchivedSpaces: input.IncludeArchivedSpaces,
+			Source:                input.Source,
+		}).Get(ctx, &fetched)
+		if err != nil {
+			return SyncSpaceOutput{}, err
+		}
+
+		ref, count := fetched.Ref, fetched.Count
+		if input.Chunk != nil && fetched.Count > 0 {
+			var chunked ChunkDocumentsOutput
+			err := workflow.ExecuteActivity(ctx, "confluence.ChunkDocuments", ChunkDocumentsInput{
+				Ref:     fetched.Ref,
+				Options: *input.Chunk,
+			}).Get(ctx, &chunked)
+			if err != nil {
+				return SyncSpaceOutput{}, err
+			}
+			ref, count = chunked.Ref, chunked.Count
+		}
+
+		if fetched.Count > 0 {
+			progress.Refs = append(progress.Refs, ref)
+			progress.Count += count
+		}
+		if fetched.Watermark.After(progress.Watermark) {
+			progress.Watermark = fetched.Watermark
+		}
+		progress.Start = fetched.NextStart
+
+		if !fetched.HasMore {
+			break
+		}
+	}
+
+	return SyncSpaceOutput{
+		Refs:      progress.Refs,
+		Count:     progress.Count,
+		Watermark: progress.Watermark,
+	}, nil
+}
+
+// WatchSpaceWorkflowName is the name WatchSpaceWorkflow is registered under.
+const WatchSpaceWorkflowName = "confluence.WatchSpace"
+
+// SpaceChangedSignal is the signal WatchSpaceWorkflow sends with a
+// SpaceChangeEvent when pages in the watched space change.
+const SpaceChangedSignal = "confluence.SpaceChanged"
+
+// WatchStateQuery is the query that returns the last SpaceChangeEvent seen
+// by a WatchSpaceWorkflow.
+const WatchStateQuery = "confluence.WatchState"
+
+// WatchSpaceInput is the input for WatchSpaceWorkflow.
+type WatchSpaceInput struct {
+	// BaseURL, Email, and APIToken may be left empty to use the credentials
+	// registered for Site, or the default credentials, of the worker running
+	// the activities.
+	BaseURL  string
+	Email    string
+	APIToken string
+	Site     string
+	SpaceKey string `validate:"required"`
+
+	// Watermark is the time to watch for changes from. A nil watermark
+	// reports every page of the space on the first poll.
+	Watermark *time.Time
+
+	// Interval is the time between polls. Defaults to 5 minutes.
+	Interval time.Duration
+
+	// Limit is the number of pages requested per API call, up to the
+	// API maximum of 250. Defaults to 250.
+	Limit int `validate:"min=0"`
+
+	// CollectCommentRefs adds the refs of inline comment markers found in
+	// each page body to the "inline_comment_refs" metadata field.
+	CollectCommentRefs bool
+
+	// NotifyWorkflowID is the workflow signalled with SpaceChangedSignal on
+	// each change. Changes are only exposed through WatchStateQuery when empty.
+	NotifyWorkflowID string
+
+	// MaxPollsPerRun is the number of polls made before the workflow
+	// continues as new to keep its history bounded. Defaults to 100.
+	MaxPollsPerRun int `validate:"min=0"`
+
+	// Source is the Source of the stored Documents, which tells apart the
+	// Documents of several sites. Defaults to the hostname of BaseURL.
+	Source string
+}
+
+// SpaceChangeEvent describes the pages that changed between two polls.
+type SpaceChangeEvent struct {
+	SpaceKey string
+	Ref      core.DataRef
+	Count    int
+
+	// Watermark is the latest modification time among the changed pages.
+	Watermark time.Time
+}
+
+// WatchSpaceWorkflow polls a Confluence space for changed pages using
+// IncrementalSyncActivity, signals NotifyWorkflowID with a SpaceChangeEvent
+// for every poll that finds changes, and continues as new every
+// MaxPollsPerRun polls. It runs until cancelled.
+func WatchSpaceWorkflow(ctx workflow.Context, input WatchSpaceInput) error {
+	if err := validateInput(input); err != nil {
+		return err
+	}
+
+	ctx = workflow.WithActivityOptions(ctx, batchPolicy.ActivityOptions())
+
+	interval := input.Interval
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	maxPolls := input.MaxPollsPerRun
+	if maxPolls <= 0 {
+		maxPolls = 100
+	}
+
+	var last SpaceChangeEvent
+	if input.Watermark != nil {
+		last.Watermark = *input.Watermark
+	}
+	err := workflow.SetQueryHandler(ctx, WatchStateQuery, func() (SpaceChangeEvent, error) {
+		return last, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for poll := 0; poll < maxPolls; poll++ {
+		var synced IncrementalSyncOutput
+		err := workflow.ExecuteActivity(ctx, "confluence.IncrementalSync", IncrementalSyncInput{
+			BaseURL:            input.BaseURL,
+			Email:              input.Email,
+			APIToken:           input.APIToken,
+			Site:               input.Site,
+			SpaceKey:           input.SpaceKey,
+			Watermark:          input.Watermark,
+			Limit:              input.Limit,
+			CollectCommentRefs: input.CollectCommentRefs,
+			Source:             input.Source,
+		}).Get(ctx, &synced)
+		if err != nil {
+			return err
+		}
+
+		// Pages modified exactly at the watermark are returned on every
+		// poll, so only an advancing watermark counts as a change.
+		changed := synced.Count > 0 &&
+			(input.Watermark == nil || synced.Watermark.After(*input.Watermark))
+		if changed {
+			last = SpaceChangeEvent{
+				SpaceKey:  input.SpaceKey,
+				Ref:       synced.Ref,
+				Count:     synced.Count,
+				Watermark: synced.Watermark,
+			}
+			if input.NotifyWorkflowID != "" {
+				err := workflow.SignalExternalWorkflow(ctx, input.NotifyWorkflowID, "", SpaceChangedSignal, last).Get(ctx, nil)
+				if err != nil {
+					return err
+				}
+			}
+			watermark := synced.Watermark
+			input.Watermark = &watermark
+		}
+
+		if err := workflow.Sleep(ctx, interval); err != nil {
+			return err
+		}
+	}
+
+	return workflow.NewContinueAsNewError(ctx, WatchSpaceWorkflowName, input)
+}
@@ -0,0 +1,319 @@
+package confluence_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/resolute-sh/resolute-confluence"
+	"github.com/resolute-sh/resolute-confluence/confluencetest"
+	"go.temporal.io/sdk/temporal"
+)
+
+func TestCreatePageActivity(t *testing.T) {
+	srv := newServer(t)
+	parent := srv.AddPage(confluencetest.NewPage("ENG", "Reports", "<p>Weekly reports.</p>"))
+
+	out, err := confluence.CreatePageActivity(context.Background(), confluence.CreatePageInput{
+		BaseURL:  srv.URL,
+		Email:    srv.Email,
+		APIToken: srv.APIToken,
+		SpaceKey: "ENG",
+		Title:    "Week 42",
+		ParentID: parent.ID,
+		Body:     "All services **green**.",
+		Format:   confluence.BodyFormatMarkdown,
+	})
+	if err != nil {
+		t.Fatalf("CreatePageActivity() error = %v", err)
+	}
+
+	page, ok := srv.Page(out.PageID)
+	if !ok {
+		t.Fatalf("page %s was not created", out.PageID)
+	}
+	if want := srv.URL + "/wiki" + page.Links.WebUI; out.URL != want || out.Version != 1 {
+		t.Errorf("URL, Version = %q, %d, want %q, 1", out.URL, out.Version, want)
+	}
+	if page.Title != "Week 42" || len(page.Ancestors) != 1 || page.Ancestors[0].ID != parent.ID {
+		t.Errorf("page = %q below %+v, want Week 42 below %s", page.Title, page.Ancestors, parent.ID)
+	}
+	if body := page.Body.Storage.Value; !strings.Contains(body, "<strong>green</strong>") {
+		t.Errorf("body = %q, want the Markdown converted to storage format", body)
+	}
+}
+
+func TestCreatePageActivityExistingTitle(t *testing.T) {
+	srv := newServer(t)
+	srv.AddPage(confluencetest.NewPage("ENG", "Week 42", "<p>Already published.</p>"))
+
+	_, err := confluence.CreatePageActivity(context.Background(), confluence.CreatePageInput{
+		BaseURL:  srv.URL,
+		Email:    srv.Email,
+		APIToken: srv.APIToken,
+		SpaceKey: "ENG",
+		Title:    "Week 42",
+		Body:     "<p>Again.</p>",
+	})
+	if err == nil {
+		t.Fatal("CreatePageActivity() error = nil, want the title conflict")
+	}
+}
+
+func TestUpdatePageActivityConflicts(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy string
+		expected int
+		races    int
+
+		wantVersion  int
+		wantConflict bool
+	}{
+		{name: "current version", strategy: confluence.ConflictFail, expected: 2, wantVersion: 3},
+		{name: "stale version fails", strategy: confluence.ConflictFail, expected: 1, wantConflict: true},
+		{name: "stale version overwritten", strategy: confluence.ConflictOverwrite, expected: 1, wantVersion: 3},
+		{name: "stale version not retried", strategy: confluence.ConflictRetry, expected: 1, wantConflict: true},
+		{name: "concurrent edit fails", strategy: confluence.ConflictFail, races: 1, wantConflict: true},
+		{name: "concurrent edit retried", strategy: confluence.ConflictRetry, races: 1, wantVersion: 4},
+		{name: "concurrent edits exhaust retries", strategy: confluence.ConflictRetry, races: 3, wantConflict: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newServer(t)
+			page := srv.AddPage(confluencetest.NewPage("ENG", "Status", "<p>v1</p>"))
+			srv.UpdatePage(page.ID, "<p>v2</p>")
+			srv.EditConcurrently(page.ID, tt.races)
+
+			out, err := confluence.UpdatePageActivity(context.Background(), confluence.UpdatePageInput{
+				BaseURL:          srv.URL,
+				Email:            srv.Email,
+				APIToken:         srv.APIToken,
+				PageID:           page.ID,
+				Body:             "<p>update</p>",
+				ExpectedVersion:  tt.expected,
+				ConflictStrategy: tt.strategy,
+				VersionMessage:   "scripted update",
+			})
+
+			updated, _ := srv.Page(page.ID)
+			if tt.wantConflict {
+				var appErr *temporal.ApplicationError
+				if !errors.As(err, &appErr) || appErr.Type() != confluence.ErrTypeVersionConflict || !appErr.NonRetryable() {
+					t.Fatalf("UpdatePageActivity() error = %v, want a non-retryable version conflict", err)
+				}
+				if updated.Body.Storage.Value == "<p>update</p>" {
+					t.Error("the conflicting update was applied")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdatePageActivity() error = %v", err)
+			}
+			if out.Version != tt.wantVersion || updated.Version.Number != tt.wantVersion {
+				t.Errorf("Version = %d, page version %d, want %d", out.Version, updated.Version.Number, tt.wantVersion)
+			}
+			if updated.Body.Storage.Value != "<p>update</p>" || updated.Version.Message != "scripted update" {
+				t.Errorf("page body, message = %q, %q, want the update", updated.Body.Storage.Value, updated.Version.Message)
+			}
+		})
+	}
+}
+
+func TestUpdatePageActivityKeepsUnsetFields(t *testing.T) {
+	srv := newServer(t)
+	page := srv.AddPage(confluencetest.NewPage("ENG", "Status", "<p>All green.</p>"))
+
+	_, err := confluence.UpdatePageActivity(context.Background(), confluence.UpdatePageInput{
+		BaseURL:  srv.URL,
+		Email:    srv.Email,
+		APIToken: srv.APIToken,
+		PageID:   page.ID,
+		Title:    "Service status",
+	})
+	if err != nil {
+		t.Fatalf("UpdatePageActivity() error = %v", err)
+	}
+
+	updated, _ := srv.Page(page.ID)
+	if updated.Title != "Service status" || updated.Body.Storage.Value != "<p>All green.</p>" {
+		t.Errorf("page = %q with body %q, want the new title and the body kept", updated.Title, updated.Body.Storage.Value)
+	}
+}
+
+func TestUpsertPageActivity(t *testing.T) {
+	srv := newServer(t)
+	srv.AddSpace(confluence.Space{Key: "ENG"})
+	input := confluence.UpsertPageInput{
+		BaseURL:  srv.URL,
+		Email:    srv.Email,
+		APIToken: srv.APIToken,
+		SpaceKey: "ENG",
+		Title:    "Release notes",
+		Body:     "<p>v1.0</p>",
+	}
+
+	created, err := confluence.UpsertPageActivity(context.Background(), input)
+	if err != nil {
+		t.Fatalf("first UpsertPageActivity() error = %v", err)
+	}
+	if !created.Created || created.Version != 1 {
+		t.Errorf("first Created, Version = %t, %d, want true, 1", created.Created, created.Version)
+	}
+
+	input.Body = "<p>v1.1</p>"
+	updated, err := confluence.UpsertPageActivity(context.Background(), input)
+	if err != nil {
+		t.Fatalf("second UpsertPageActivity() error = %v", err)
+	}
+	if updated.Created || updated.PageID != created.PageID || updated.Version != 2 {
+		t.Errorf("second = %+v, want page %s updated to version 2", updated, created.PageID)
+	}
+	if page, _ := srv.Page(created.PageID); page.Body.Storage.Value != "<p>v1.1</p>" {
+		t.Errorf("body = %q, want the second body", page.Body.Storage.Value)
+	}
+}
+
+func TestUpsertPageActivityParentMismatch(t *testing.T) {
+	srv := newServer(t)
+	parent := srv.AddPage(confluencetest.NewPage("ENG", "Reports", "<p>Reports.</p>"))
+	existing := srv.AddPage(confluencetest.NewPage("ENG", "Release notes", "<p>v1.0</p>"))
+
+	_, err := confluence.UpsertPageActivity(context.Background(), confluence.UpsertPageInput{
+		BaseURL:  srv.URL,
+		Email:    srv.Email,
+		APIToken: srv.APIToken,
+		SpaceKey: "ENG",
+		Title:    "Release notes",
+		ParentID: parent.ID,
+		Body:     "<p>v1.1</p>",
+	})
+	var appErr *temporal.ApplicationError
+	if !errors.As(err, &appErr) || appErr.Type() != confluence.ErrTypeParentMismatch {
+		t.Fatalf("UpsertPageActivity() error = %v, want a parent mismatch", err)
+	}
+	if page, _ := srv.Page(existing.ID); page.Version.Number != 1 {
+		t.Errorf("existing page version = %d, want it left at 1", page.Version.Number)
+	}
+}
+
+func TestAppendToPageActivityIdempotency(t *testing.T) {
+	srv := newServer(t)
+	page := srv.AddPage(confluencetest.NewPage("ENG", "Changelog", "<p>Entries:</p>"))
+	input := confluence.AppendToPageInput{
+		BaseURL:        srv.URL,
+		Email:          srv.Email,
+		APIToken:       srv.APIToken,
+		PageID:         page.ID,
+		Section:        "<p>2026-10-16: deployed v2.</p>",
+		IdempotencyKey: "deploy-2026-10-16",
+	}
+
+	first, err := confluence.AppendToPageActivity(context.Background(), input)
+	if err != nil {
+		t.Fatalf("first AppendToPageActivity() error = %v", err)
+	}
+	if !first.Appended || first.Version != 2 {
+		t.Errorf("first Appended, Version = %t, %d, want true, 2", first.Appended, first.Version)
+	}
+
+	second, err := confluence.AppendToPageActivity(context.Background(), input)
+	if err != nil {
+		t.Fatalf("second AppendToPageActivity() error = %v", err)
+	}
+	if second.Appended || second.Version != 2 {
+		t.Errorf("second Appended, Version = %t, %d, want false, 2", second.Appended, second.Version)
+	}
+
+	appended, _ := srv.Page(page.ID)
+	body := appended.Body.Storage.Value
+	if !strings.HasPrefix(body, "<p>Entries:</p>") || strings.Count(body, "deployed v2") != 1 {
+		t.Errorf("body = %q, want the section appended once at the bottom", body)
+	}
+}
+
+func TestAppendToPageActivityConcurrentEdit(t *testing.T) {
+	srv := newServer(t)
+	page := srv.AddPage(confluencetest.NewPage("ENG", "Changelog", "<p>Entries:</p>"))
+	srv.EditConcurrently(page.ID, 1)
+
+	out, err := confluence.AppendToPageActivity(context.Background(), confluence.AppendToPageInput{
+		BaseURL:  srv.URL,
+		Email:    srv.Email,
+		APIToken: srv.APIToken,
+		PageID:   page.ID,
+		Section:  "<p>Newest entry.</p>",
+		Position: confluence.PositionTop,
+	})
+	if err != nil {
+		t.Fatalf("AppendToPageActivity() error = %v", err)
+	}
+	if !out.Appended || out.Version != 3 {
+		t.Errorf("Appended, Version = %t, %d, want true, 3", out.Appended, out.Version)
+	}
+	if appended, _ := srv.Page(page.ID); appended.Body.Storage.Value != "<p>Newest entry.</p><p>Entries:</p>" {
+		t.Errorf("body = %q, want the section at the top", appended.Body.Storage.Value)
+	}
+}
+
+func TestDeletePagesActivity(t *testing.T) {
+	srv := newServer(t)
+	current := srv.AddPage(confluencetest.NewPage("ENG", "Current", "<p>current</p>"))
+	trashed := srv.AddPage(confluencetest.NewPage("ENG", "Trashed", "<p>trashed</p>"))
+	restricted := srv.AddPage(confluencetest.NewPage("ENG", "Restricted", "<p>restricted</p>"))
+	srv.Restrict(restricted.ID)
+
+	input := confluence.DeletePagesInput{
+		BaseURL:  srv.URL,
+		Email:    srv.Email,
+		APIToken: srv.APIToken,
+		PageIDs:  []string{trashed.ID},
+	}
+	if _, err := confluence.DeletePagesActivity(context.Background(), input); err != nil {
+		t.Fatalf("first DeletePagesActivity() error = %v", err)
+	}
+
+	input.PageIDs = []string{current.ID, trashed.ID, restricted.ID, "404"}
+	out, err := confluence.DeletePagesActivity(context.Background(), input)
+	if err != nil {
+		t.Fatalf("DeletePagesActivity() error = %v", err)
+	}
+	if want := []string{current.ID, trashed.ID, "404"}; !slices.Equal(out.Deleted, want) {
+		t.Errorf("Deleted = %v, want %v", out.Deleted, want)
+	}
+	if len(out.Errors) != 1 || out.Errors[0].ID != restricted.ID {
+		t.Errorf("Errors = %+v, want one for page %s", out.Errors, restricted.ID)
+	}
+	for _, id := range []string{current.ID, trashed.ID} {
+		if page, _ := srv.Page(id); page.Status != "trashed" {
+			t.Errorf("page %s status = %q, want trashed", id, page.Status)
+		}
+	}
+}
+
+func TestDeletePagesActivityPurge(t *testing.T) {
+	srv := newServer(t)
+	page := srv.AddPage(confluencetest.NewPage("ENG", "Generated", "<p>generated</p>"))
+
+	input := confluence.DeletePagesInput{
+		BaseURL:  srv.URL,
+		Email:    srv.Email,
+		APIToken: srv.APIToken,
+		PageIDs:  []string{page.ID},
+		Purge:    true,
+	}
+	for attempt := 1; attempt <= 2; attempt++ {
+		out, err := confluence.DeletePagesActivity(context.Background(), input)
+		if err != nil {
+			t.Fatalf("attempt %d: DeletePagesActivity() error = %v", attempt, err)
+		}
+		if !slices.Equal(out.Deleted, input.PageIDs) || len(out.Errors) > 0 {
+			t.Errorf("attempt %d: Deleted, Errors = %v, %+v, want the page deleted", attempt, out.Deleted, out.Errors)
+		}
+	}
+	if _, ok := srv.Page(page.ID); ok {
+		t.Error("page was not purged")
+	}
+}